@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/lint"
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// lintOpts holds options of the lint command.
+type lintOpts struct {
+	Fix bool `long:"fix" description:"fix task headers, checkbox syntax and task numbering in place"`
+}
+
+// errLintFailed is returned when linted plans have errors.
+var errLintFailed = errors.New("plan lint failed")
+
+// runLintCommand runs "ralphex lint [--fix] [plan...]" and returns the process exit code,
+// non-zero if any plan has errors, so the command can gate CI. plans are parsed with the plan format
+// of the config and the plan frontmatter, the same way the runner parses them.
+func runLintCommand(args []string, stdout, stderr io.Writer) int {
+	var o lintOpts
+	parser := flags.NewParser(&o, flags.Default)
+	parser.Usage = "lint [OPTIONS] [plan...]"
+	plans, err := parser.ParseArgs(args)
+	if err != nil {
+		var flagsErr *flags.Error
+		if errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(stderr, "error: load config: %v\n", err)
+		return 1
+	}
+	if len(plans) == 0 {
+		if plans, err = filepath.Glob(filepath.Join(cfg.PlansDir, "*.md")); err != nil || len(plans) == 0 {
+			fmt.Fprintf(stderr, "error: no plans found in %s\n", cfg.PlansDir)
+			return 1
+		}
+	}
+
+	planGrammar := func(planFile string) (plan.Grammar, error) {
+		planCfg, err := cfg.WithPlan(planFile)
+		if err != nil {
+			return plan.Grammar{}, fmt.Errorf("apply plan settings of %s: %w", planFile, err)
+		}
+		return planCfg.PlanGrammar, nil
+	}
+	if err := lintPlans(plans, planGrammar, o.Fix, stdout); err != nil {
+		if !errors.Is(err, errLintFailed) {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// lintPlans checks the plan files, fixing them first if fix is set, and prints issues as "path:line: ...".
+// each plan is parsed with the grammar returned by planGrammar. returns errLintFailed if any plan has errors.
+func lintPlans(plans []string, planGrammar func(planFile string) (plan.Grammar, error), fix bool, w io.Writer) error {
+	errCount, warnCount := 0, 0
+	for _, planFile := range plans {
+		g, err := planGrammar(planFile)
+		if err != nil {
+			return err
+		}
+		if fix {
+			changed, err := lint.FixFile(planFile, g)
+			if err != nil {
+				return err
+			}
+			if changed {
+				fmt.Fprintf(w, "%s: fixed\n", planFile)
+			}
+		}
+
+		issues, err := lint.CheckFile(planFile, g)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			sep := ":"
+			if issue.Line == 0 {
+				sep = ": "
+			}
+			fmt.Fprintf(w, "%s%s%s\n", planFile, sep, issue)
+			if issue.Severity == lint.SeverityError {
+				errCount++
+			} else {
+				warnCount++
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%d plan(s) checked, %d error(s), %d warning(s)\n", len(plans), errCount, warnCount)
+	if errCount > 0 {
+		return errLintFailed
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+func TestLintPlans(t *testing.T) {
+	grammar := func(g plan.Grammar) func(string) (plan.Grammar, error) {
+		return func(string) (plan.Grammar, error) { return g, nil }
+	}
+	const goodPlan = "# Plan\n## Validation Commands\n### Task 1: A\n- [ ] a\n"
+	const badPlan = "# Plan\n### Task 1: A\n- [] a\n"
+
+	t.Run("valid plan", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(goodPlan), 0o600))
+		var buf bytes.Buffer
+		require.NoError(t, lintPlans([]string{planFile}, grammar(plan.Grammar{}), false, &buf))
+		assert.Equal(t, "1 plan(s) checked, 0 error(s), 0 warning(s)\n", buf.String())
+	})
+
+	t.Run("errors and warnings", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(badPlan), 0o600))
+		var buf bytes.Buffer
+		err := lintPlans([]string{planFile}, grammar(plan.Grammar{}), false, &buf)
+		require.ErrorIs(t, err, errLintFailed)
+		assert.Equal(t, planFile+`: warning: plan has no "## Validation Commands" section, tasks are not validated `+
+			"between iterations (no-validation)\n"+
+			planFile+`:3: error: checkbox "- [] a" is not recognized, use "- [ ] a" (checkbox)`+"\n"+
+			"1 plan(s) checked, 1 error(s), 1 warning(s)\n", buf.String())
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(badPlan), 0o600))
+		var buf bytes.Buffer
+		require.NoError(t, lintPlans([]string{planFile}, grammar(plan.Grammar{}), true, &buf), "only a warning left")
+		assert.Contains(t, buf.String(), planFile+": fixed\n")
+		data, err := os.ReadFile(planFile)
+		require.NoError(t, err)
+		assert.Equal(t, "# Plan\n### Task 1: A\n- [ ] a\n", string(data))
+	})
+
+	t.Run("custom grammar", func(t *testing.T) {
+		g, err := plan.NewGrammar(`^##\s+Step\s+(?P<num>\d+):\s*(?P<title>.*)$`, "")
+		require.NoError(t, err)
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n## Validation Commands\n## Step 1: A\n- [ ] a\n"), 0o600))
+		var buf bytes.Buffer
+		require.NoError(t, lintPlans([]string{planFile}, grammar(g), false, &buf))
+		assert.Equal(t, "1 plan(s) checked, 0 error(s), 0 warning(s)\n", buf.String())
+
+		require.ErrorIs(t, lintPlans([]string{planFile}, grammar(plan.Grammar{}), false, &buf), errLintFailed)
+	})
+
+	t.Run("grammar error", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := lintPlans([]string{"plan.md"}, func(string) (plan.Grammar, error) { return plan.Grammar{}, errors.New("bad config") },
+			false, &buf)
+		require.EqualError(t, err, "bad config")
+	})
+
+	t.Run("missing plan", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := lintPlans([]string{filepath.Join(t.TempDir(), "missing.md")}, grammar(plan.Grammar{}), false, &buf)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, errLintFailed)
+	})
+}
+
+func TestRunLintCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.md")
+	bad := filepath.Join(dir, "bad.md")
+	require.NoError(t, os.WriteFile(good, []byte("# Plan\n## Validation Commands\n### Task 1: A\n- [ ] a\n"), 0o600))
+	require.NoError(t, os.WriteFile(bad, []byte("# Plan\n## Validation Commands\n## Task 1: A\n- [ ] a\n"), 0o600))
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{name: "valid plan", args: []string{good}, wantCode: 0},
+		{name: "plan with errors", args: []string{good, bad}, wantCode: 1},
+		{name: "missing plan", args: []string{filepath.Join(dir, "missing.md")}, wantCode: 1},
+		{name: "unknown flag", args: []string{"--bad-flag"}, wantCode: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tc.wantCode, runLintCommand(tc.args, &stdout, &stderr))
+		})
+	}
+
+	t.Run("plan format from frontmatter", func(t *testing.T) {
+		steps := filepath.Join(dir, "steps.md")
+		require.NoError(t, os.WriteFile(steps, []byte("---\ntask_header_pattern: ^##\\s+Step\\s+(?P<num>\\d+):\\s*(?P<title>.*)$\n---\n"+
+			"# Plan\n## Validation Commands\n## Step 1: A\n- [ ] a\n"), 0o600))
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 0, runLintCommand([]string{steps}, &stdout, &stderr), stdout.String()+stderr.String())
+		assert.Contains(t, stdout.String(), "1 plan(s) checked, 0 error(s)")
+		assert.Equal(t, 1, runLintCommand([]string{steps, bad}, &stdout, &stderr), "other plans keep the config format")
+	})
+
+	t.Run("fix makes plan pass", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 0, runLintCommand([]string{"--fix", bad}, &stdout, &stderr))
+		assert.Contains(t, stdout.String(), bad+": fixed")
+		assert.Empty(t, stderr.String())
+	})
+}
@@ -3,6 +3,7 @@ package main
 
 import (
 	"bufio"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -12,26 +13,33 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/jessevdk/go-flags"
+	"golang.org/x/term"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/git"
 	"github.com/umputun/ralphex/pkg/input"
+	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/runfile"
 	"github.com/umputun/ralphex/pkg/web"
 )
 
 // opts holds all command-line options.
 type opts struct {
-	MaxIterations   int      `short:"m" long:"max-iterations" default:"50" description:"maximum task iterations"`
+	MaxIterations   int      `short:"m" long:"max-iterations" description:"maximum task iterations (default: 50)"`
 	Review          bool     `short:"r" long:"review" description:"skip task execution, run full review pipeline"`
 	CodexOnly       bool     `short:"c" long:"codex-only" description:"skip tasks and first review, run only codex loop"`
 	PlanDescription string   `long:"plan" description:"create plan interactively (enter plan description)"`
+	Template        string   `long:"template" description:"plan template for --plan, e.g. api-endpoint"`
 	Debug           bool     `short:"d" long:"debug" description:"enable debug logging"`
 	NoColor         bool     `long:"no-color" description:"disable color output"`
 	Version         bool     `short:"v" long:"version" description:"print version and exit"`
@@ -39,12 +47,61 @@ type opts struct {
 	Port            int      `short:"p" long:"port" default:"8080" description:"web dashboard port"`
 	Watch           []string `short:"w" long:"watch" description:"directories to watch for progress files (repeatable)"`
 	Reset           bool     `long:"reset" description:"interactively reset global config to embedded defaults"`
+	Resume          bool     `long:"resume" description:"continue an interrupted run from the saved phase and iteration"`
+	Phases          string   `long:"phases" description:"comma-separated phase pipeline, e.g. \"tasks, codex, custom:security\""`
+	Deadline        deadline `long:"deadline" description:"stop at a resumable point after duration (8h) or at time (07:00, RFC3339)"`
+	DryRun          bool     `long:"dry-run" description:"show branch, tasks, phases and expanded prompts without running anything"`
+	Base            string   `long:"base" description:"base branch for feature branches and reviewed diffs (default: origin/HEAD)"`
+	ConfirmPhases   bool     `long:"confirm-phases" description:"wait for approval after each phase (default: phases in confirm_phases config)"`
 
 	PlanFile string `positional-arg-name:"plan-file" description:"path to plan file (optional, uses fzf if omitted)"`
 }
 
 var revision = "unknown"
 
+// deadline is the --deadline option value, set from a duration relative to now or a wall clock time.
+type deadline struct {
+	time.Time
+}
+
+// UnmarshalFlag parses a duration ("8h30m"), a clock time ("07:00", next occurrence) or an RFC3339 timestamp.
+func (d *deadline) UnmarshalFlag(value string) error {
+	t, err := parseDeadline(value, time.Now())
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+// parseDeadline converts a --deadline value to an absolute time relative to now.
+func parseDeadline(value string, now time.Time) (time.Time, error) {
+	if dur, err := time.ParseDuration(value); err == nil {
+		if dur <= 0 {
+			return time.Time{}, fmt.Errorf("invalid deadline %q: duration must be positive", value)
+		}
+		return now.Add(dur), nil
+	}
+	if clock, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid deadline %q: expected duration (8h), clock time (07:00) or RFC3339 time", value)
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("invalid deadline %q: time is in the past", value)
+	}
+	return t, nil
+}
+
+// defaultMaxIterations is the max task iterations without --max-iterations flag and max_iterations setting.
+const defaultMaxIterations = 50
+
 // datePrefixRe matches date-like prefixes in plan filenames (e.g., "2024-01-15-").
 var datePrefixRe = regexp.MustCompile(`^[\d-]+`)
 
@@ -58,6 +115,7 @@ type startupInfo struct {
 	Mode          processor.Mode
 	MaxIterations int
 	ProgressPath  string
+	Deadline      time.Time // zero if no deadline
 }
 
 // planSelector holds parameters for plan file selection.
@@ -72,6 +130,8 @@ type planSelector struct {
 type executePlanRequest struct {
 	PlanFile string
 	Mode     processor.Mode
+	Pipeline []processor.Step // nil uses the default pipeline of the mode
+	Confirm  []processor.Step // phases followed by an approval gate
 	GitOps   *git.Repo
 	Config   *config.Config
 	Colors   *progress.Colors
@@ -86,14 +146,24 @@ type webDashboardParams struct {
 	WatchDirs       []string // CLI watch dirs
 	ConfigWatchDirs []string // config watch dirs
 	Colors          *progress.Colors
+	PlanGrammar     plan.Grammar // grammar of plan task headers and checkboxes
+	Settings        string       // effective run settings shown in the dashboard header
 }
 
 func main() {
+	// lint and new commands have their own options and output, they run before the main parser
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(runLintCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "new" {
+		os.Exit(runNewCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	fmt.Printf("ralphex %s\n", revision)
 
 	var o opts
 	parser := flags.NewParser(&o, flags.Default)
-	parser.Usage = "[OPTIONS] [plan-file]"
+	parser.Usage = "[OPTIONS] [plan-file]\n  ralphex lint [--fix] [plan...]\n  ralphex new <template> <name>"
 
 	args, err := parser.Parse()
 	if err != nil {
@@ -156,9 +226,11 @@ func run(ctx context.Context, o opts) error {
 		return runWatchOnly(ctx, o, cfg, colors)
 	}
 
-	// check dependencies using configured command (or default "claude")
-	if depErr := checkClaudeDep(cfg); depErr != nil {
-		return depErr
+	// check dependencies using configured command (or default "claude"), dry run only reports them
+	if !o.DryRun {
+		if depErr := checkClaudeDep(cfg); depErr != nil {
+			return depErr
+		}
 	}
 
 	// require running from repo root
@@ -172,6 +244,14 @@ func run(ctx context.Context, o opts) error {
 		return fmt.Errorf("open git repo: %w", err)
 	}
 
+	// resolve the base branch once, branch logic and prompts use it from config
+	cfg.DefaultBranch = resolveBaseBranch(o.Base, cfg.DefaultBranch, gitOps)
+
+	// dry run stops here, before anything in the repository is changed
+	if o.DryRun {
+		return runDryRun(ctx, o, gitOps, cfg, colors)
+	}
+
 	// ensure repository has commits (prompts to create initial commit if empty)
 	if ensureErr := ensureRepoHasCommits(ctx, gitOps, os.Stdin, os.Stdout); ensureErr != nil {
 		return ensureErr
@@ -197,7 +277,7 @@ func run(ctx context.Context, o opts) error {
 		Colors:   colors,
 	})
 	if err != nil {
-		// check for auto-plan-mode: no plans found on main/master branch
+		// check for auto-plan-mode: no plans found on the base branch
 		handled, autoPlanErr := tryAutoPlanMode(ctx, err, o, gitOps, cfg, colors)
 		if handled {
 			return autoPlanErr
@@ -205,13 +285,27 @@ func run(ctx context.Context, o opts) error {
 		return err
 	}
 
-	if setupErr := setupGitForExecution(gitOps, planFile, mode, colors); setupErr != nil {
+	if cfg, err = loadPlanConfig(cfg, planFile, o.Base, gitOps); err != nil {
+		return err
+	}
+	pipeline, err := resolvePipeline(o, cfg, mode)
+	if err != nil {
+		return err
+	}
+	confirm, err := resolveConfirmPhases(o, cfg, pipeline, mode)
+	if err != nil {
+		return err
+	}
+
+	if setupErr := setupGitForExecution(gitOps, planFile, cfg.Branch, cfg.DefaultBranch, mode, colors); setupErr != nil {
 		return setupErr
 	}
 
 	return executePlan(ctx, o, executePlanRequest{
 		PlanFile: planFile,
 		Mode:     mode,
+		Pipeline: pipeline,
+		Confirm:  confirm,
 		GitOps:   gitOps,
 		Config:   cfg,
 		Colors:   colors,
@@ -227,9 +321,81 @@ func getCurrentBranch(gitOps *git.Repo) string {
 	return branch
 }
 
-// isMainBranch returns true if the branch name is "main" or "master".
-func isMainBranch(branch string) bool {
-	return branch == "main" || branch == "master"
+// resolveBaseBranch returns the base branch of the run: --base flag, default_branch config
+// or the default branch detected from origin/HEAD, in this order.
+func resolveBaseBranch(flagBase, configBase string, gitOps *git.Repo) string {
+	if flagBase != "" {
+		return flagBase
+	}
+	if configBase != "" {
+		return configBase
+	}
+	return gitOps.DefaultBranch()
+}
+
+// loadPlanConfig applies settings from the frontmatter of the plan file to the loaded config and resolves
+// the base branch of the run with them. precedence is CLI flags > plan > local config > global config,
+// flags are applied on top by callers. returns cfg unchanged without a plan file.
+func loadPlanConfig(cfg *config.Config, planFile, flagBase string, gitOps *git.Repo) (*config.Config, error) {
+	if planFile == "" {
+		return cfg, nil
+	}
+	planCfg, err := cfg.WithPlan(planFile)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	planCfg.DefaultBranch = resolveBaseBranch(flagBase, planCfg.DefaultBranch, gitOps)
+	return planCfg, nil
+}
+
+// maxIterations returns the max task iterations of the run: --max-iterations flag, max_iterations
+// of plan frontmatter or config, or the default, in this order.
+func maxIterations(flag int, cfg *config.Config) int {
+	if flag > 0 {
+		return flag
+	}
+	if cfg.MaxIterations > 0 {
+		return cfg.MaxIterations
+	}
+	return defaultMaxIterations
+}
+
+// runSettings describes the effective settings of a run, after CLI flags, plan frontmatter and config
+// are merged, for the progress file header and the dashboard.
+func runSettings(cfg *config.Config, o opts, mode processor.Mode) string {
+	validationTimeout := "none"
+	if cfg.ValidationTimeoutMs > 0 {
+		validationTimeout = (time.Duration(cfg.ValidationTimeoutMs) * time.Millisecond).String()
+	}
+	agents := "all"
+	if len(cfg.Agents) > 0 {
+		agents = strings.Join(cfg.Agents, " ")
+	}
+	settings := []string{
+		fmt.Sprintf("max_iterations=%d", maxIterations(o.MaxIterations, cfg)),
+		fmt.Sprintf("codex_enabled=%t", cfg.CodexEnabled || mode == processor.ModeCodexOnly),
+		"validation_timeout=" + validationTimeout,
+		"base_branch=" + cfg.DefaultBranch,
+		"agents=" + agents,
+	}
+	if cfg.ClaudeArgs != "" {
+		settings = append(settings, "claude_args="+cfg.ClaudeArgs)
+	}
+	return strings.Join(settings, ", ")
+}
+
+// isBaseBranch returns true if the branch is the base branch feature branches are created from.
+// main and master are both accepted if the base is one of them, the default branch falls back to them
+// when origin/HEAD can't be resolved.
+func isBaseBranch(branch, base string) bool {
+	if branch == "" {
+		return false
+	}
+	if branch == base {
+		return true
+	}
+	fallback := []string{"main", "master"}
+	return slices.Contains(fallback, branch) && slices.Contains(fallback, base)
 }
 
 // promptPlanDescription prompts the user for a plan description when no plans are found.
@@ -248,7 +414,7 @@ func promptPlanDescription(ctx context.Context, r io.Reader, colors *progress.Co
 	return strings.TrimSpace(line)
 }
 
-// tryAutoPlanMode attempts to switch to plan mode when no plans are found on main/master.
+// tryAutoPlanMode attempts to switch to plan mode when no plans are found on the base branch.
 // returns (true, nil) if user canceled, (true, err) if plan mode was attempted, or (false, nil) if auto-plan-mode doesn't apply.
 func tryAutoPlanMode(ctx context.Context, err error, o opts, gitOps *git.Repo, cfg *config.Config, colors *progress.Colors) (bool, error) {
 	if !errors.Is(err, errNoPlansFound) || o.Review || o.CodexOnly {
@@ -256,7 +422,7 @@ func tryAutoPlanMode(ctx context.Context, err error, o opts, gitOps *git.Repo, c
 	}
 
 	branch, branchErr := gitOps.CurrentBranch()
-	if branchErr != nil || !isMainBranch(branch) {
+	if branchErr != nil || !isBaseBranch(branch, cfg.DefaultBranch) {
 		return false, nil //nolint:nilerr // branchErr is intentionally ignored - if we can't get branch, skip auto-plan-mode
 	}
 
@@ -298,12 +464,21 @@ func handlePostExecution(gitOps *git.Repo, planFile string, mode processor.Mode,
 func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	branch := getCurrentBranch(req.GitOps)
 
+	notifier, err := newNotifier(req.Config)
+	if err != nil {
+		return err
+	}
+	runInfo := notify.Message{PlanFile: req.PlanFile, Branch: branch, Mode: string(req.Mode)}
+	settings := runSettings(req.Config, o, req.Mode)
+
 	// create progress logger
 	baseLog, err := progress.NewLogger(progress.Config{
 		PlanFile: req.PlanFile,
 		Mode:     string(req.Mode),
 		Branch:   branch,
+		Settings: settings,
 		NoColor:  o.NoColor,
+		Append:   o.Resume,
 	}, req.Colors)
 	if err != nil {
 		return fmt.Errorf("create progress logger: %w", err)
@@ -327,6 +502,8 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		WatchDirs:       o.Watch,
 		ConfigWatchDirs: req.Config.WatchDirs,
 		Colors:          req.Colors,
+		PlanGrammar:     req.Config.PlanGrammar,
+		Settings:        settings,
 	})
 	if err != nil {
 		return err
@@ -337,13 +514,26 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		PlanFile:      req.PlanFile,
 		Branch:        branch,
 		Mode:          req.Mode,
-		MaxIterations: o.MaxIterations,
+		MaxIterations: maxIterations(o.MaxIterations, req.Config),
 		ProgressPath:  baseLog.Path(),
+		Deadline:      o.Deadline.Time,
 	}, req.Colors)
 
 	// create and run the runner
-	r := createRunner(req.Config, o, req.PlanFile, req.Mode, runnerLog)
+	r := createRunner(req.Config, o, req.PlanFile, req.Mode, req.Pipeline, req.Confirm, runnerLog)
+	r.SetGitRepo(req.GitOps)
+	// questions asked during tasks and reviews are answered in terminal unless auto_answer_questions is set
+	r.SetInputCollector(&notifyingCollector{Collector: input.NewTerminalCollector(), notifier: notifier, info: runInfo})
 	if runErr := r.Run(ctx); runErr != nil {
+		switch {
+		case errors.Is(runErr, processor.ErrBudgetExceeded):
+			req.Colors.Warn().Printf("\nrun stopped after %s%s, raise the limit and use --resume to continue\n",
+				baseLog.Elapsed(), usageSuffix(r.Usage()))
+		case errors.Is(runErr, processor.ErrDeadlineReached):
+			req.Colors.Warn().Printf("\nrun stopped by deadline after %s%s, use --resume to continue\n",
+				baseLog.Elapsed(), usageSuffix(r.Usage()))
+		}
+		sendNotification(ctx, notifier, failureNotification(runInfo, runErr))
 		return fmt.Errorf("runner: %w", runErr)
 	}
 
@@ -351,7 +541,9 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	handlePostExecution(req.GitOps, req.PlanFile, req.Mode, req.Colors)
 
 	elapsed := baseLog.Elapsed()
-	req.Colors.Info().Printf("\ncompleted in %s\n", elapsed)
+	req.Colors.Info().Printf("\ncompleted in %s%s\n", elapsed, usageSuffix(r.Usage()))
+	runInfo.Event, runInfo.Text = notify.EventCompleted, fmt.Sprintf("run completed in %s%s", elapsed, usageSuffix(r.Usage()))
+	sendNotification(ctx, notifier, runInfo)
 
 	// keep web dashboard running after execution completes
 	if o.Serve {
@@ -366,26 +558,274 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	return nil
 }
 
+// usageSuffix formats token usage and cost for the completion message, empty if nothing was reported.
+func usageSuffix(u executor.Usage) string {
+	if u.IsZero() {
+		return ""
+	}
+	return ", " + u.String()
+}
+
+// newNotifier creates a notifier with the notification sinks configured in cfg.
+func newNotifier(cfg *config.Config) (*notify.Notifier, error) {
+	n := &notify.Notifier{}
+	add := func(name string, sink notify.Sink, eventNames []string) error {
+		events, err := notify.ParseEvents(eventNames)
+		if err != nil {
+			return fmt.Errorf("notify_%s_events: %w", name, err)
+		}
+		n.Add(name, sink, events)
+		return nil
+	}
+
+	if cfg.NotifyWebhook != "" {
+		if err := add("webhook", &notify.Webhook{URL: cfg.NotifyWebhook}, cfg.NotifyWebhookEvents); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.NotifyCommand != "" {
+		if err := add("command", &notify.Command{Command: cfg.NotifyCommand}, cfg.NotifyCommandEvents); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.NotifyBell {
+		if err := add("bell", &notify.Bell{W: os.Stdout}, cfg.NotifyBellEvents); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// failureNotification returns the notification about a failed run, based on the run details in info.
+func failureNotification(info notify.Message, runErr error) notify.Message {
+	info.Event, info.Text, info.Error = notify.EventFailed, "run failed: "+runErr.Error(), runErr.Error()
+	if errors.Is(runErr, processor.ErrMaxIterations) {
+		info.Event, info.Text = notify.EventMaxIterations, "run stopped, max iterations reached without completion"
+	}
+	return info
+}
+
+// sendNotification sends the notification, delivery failures are printed as warnings.
+// interrupted runs are not reported, the user stopped them.
+func sendNotification(ctx context.Context, n *notify.Notifier, msg notify.Message) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := n.Notify(ctx, msg); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// notifyingCollector sends a question notification before waiting for the answer.
+type notifyingCollector struct {
+	input.Collector
+	notifier *notify.Notifier
+	info     notify.Message // run details of question notifications
+}
+
+// AskQuestion notifies about the pending question and asks it with the wrapped collector.
+func (c *notifyingCollector) AskQuestion(ctx context.Context, question string, options []string) (string, error) {
+	msg := c.info
+	msg.Event, msg.Text = notify.EventQuestion, "question pending: "+question
+	sendNotification(ctx, c.notifier, msg)
+
+	answer, err := c.Collector.AskQuestion(ctx, question, options)
+	if err != nil {
+		return "", fmt.Errorf("ask question: %w", err)
+	}
+	return answer, nil
+}
+
+// dryRunInfo holds run parameters printed by a dry run.
+type dryRunInfo struct {
+	PlanFile     string
+	Mode         processor.Mode
+	Branch       string
+	Base         string // base branch of reviewed diffs
+	Claude       string // claude command availability
+	Codex        string // codex command availability
+	ProgressPath string
+}
+
+// runDryRun prints what a run would do: branch, remaining tasks, phases with iteration caps and
+// expanded prompts. claude and codex are not invoked, git and the plan file are not modified.
+func runDryRun(ctx context.Context, o opts, gitOps *git.Repo, cfg *config.Config, colors *progress.Colors) error {
+	mode := determineMode(o)
+	planFile, err := preparePlanFile(ctx, planSelector{
+		PlanFile: o.PlanFile,
+		Optional: o.Review || o.CodexOnly,
+		PlansDir: cfg.PlansDir,
+		Colors:   colors,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg, err = loadPlanConfig(cfg, planFile, o.Base, gitOps); err != nil {
+		return err
+	}
+	pipeline, err := resolvePipeline(o, cfg, mode)
+	if err != nil {
+		return err
+	}
+	confirm, err := resolveConfirmPhases(o, cfg, pipeline, mode)
+	if err != nil {
+		return err
+	}
+
+	log := &dryRunLogger{path: progress.ProgressPath(progress.Config{PlanFile: planFile, Mode: string(mode)})}
+	r := createRunner(cfg, o, planFile, mode, pipeline, confirm, log)
+	r.SetGitRepo(gitOps) // resolves the base ref of review prompts, dry run doesn't modify the repository
+	report, err := r.DryRun()
+	if err != nil {
+		return fmt.Errorf("dry run: %w", err)
+	}
+
+	codex := "disabled in config"
+	if cfg.CodexEnabled || mode == processor.ModeCodexOnly {
+		codex = commandStatus(cfg.CodexCommand, "codex")
+	}
+	printDryRun(os.Stdout, dryRunInfo{
+		PlanFile:     planFile,
+		Mode:         mode,
+		Branch:       dryRunBranch(gitOps, planFile, cfg.Branch, cfg.DefaultBranch, mode),
+		Base:         cfg.DefaultBranch,
+		Claude:       commandStatus(cfg.ClaudeCommand, "claude"),
+		Codex:        codex,
+		ProgressPath: log.Path(),
+	}, report)
+	return nil
+}
+
+// dryRunBranch describes the branch a run would work on, following createBranchIfNeeded.
+func dryRunBranch(gitOps *git.Repo, planFile, branch, base string, mode processor.Mode) string {
+	current := getCurrentBranch(gitOps)
+	if planFile == "" || mode != processor.ModeFull || !isBaseBranch(current, base) {
+		return current
+	}
+	name := cmp.Or(branch, extractBranchName(planFile))
+	if gitOps.BranchExists(name) {
+		return fmt.Sprintf("%s (would switch to existing branch from %s)", name, current)
+	}
+	return fmt.Sprintf("%s (would be created from %s)", name, current)
+}
+
+// commandStatus reports whether an executor command is available in PATH.
+func commandStatus(command, fallback string) string {
+	if command == "" {
+		command = fallback
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return command + " (not found in PATH)"
+	}
+	return command + " (" + path + ")"
+}
+
+// printDryRun writes the dry run report.
+func printDryRun(w io.Writer, info dryRunInfo, report processor.DryRunReport) {
+	planStr := info.PlanFile
+	if planStr == "" {
+		planStr = "(no plan - review only)"
+	}
+	fmt.Fprintln(w, "dry run: nothing is executed, git and the plan file are not modified")
+	fmt.Fprintf(w, "plan: %s\n", planStr)
+	fmt.Fprintf(w, "mode: %s\n", info.Mode)
+	fmt.Fprintf(w, "branch: %s\n", info.Branch)
+	fmt.Fprintf(w, "base: %s\n", info.Base)
+	fmt.Fprintf(w, "claude: %s\n", info.Claude)
+	fmt.Fprintf(w, "codex: %s\n", info.Codex)
+	fmt.Fprintf(w, "progress log: %s\n", info.ProgressPath)
+
+	if info.PlanFile != "" {
+		fmt.Fprintf(w, "\nremaining tasks: %d\n", len(report.Tasks))
+		for _, t := range report.Tasks {
+			fmt.Fprintf(w, "  Task %d: %s\n", t.Number, t.Title)
+		}
+		if len(report.ValidationCommands) > 0 {
+			fmt.Fprintln(w, "\nvalidation commands:")
+			for _, c := range report.ValidationCommands {
+				fmt.Fprintf(w, "  %s\n", c)
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "\nphases:")
+	for i, p := range report.Phases {
+		gate := ""
+		if p.Confirm {
+			gate = ", approval gate after"
+		}
+		switch {
+		case p.Skip != "":
+			fmt.Fprintf(w, "  %d. %s (skipped: %s%s)\n", i+1, p.Name, p.Skip, gate)
+		case p.MaxIterations == 1:
+			fmt.Fprintf(w, "  %d. %s (single pass%s)\n", i+1, p.Name, gate)
+		default:
+			fmt.Fprintf(w, "  %d. %s (max %d iterations%s)\n", i+1, p.Name, p.MaxIterations, gate)
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		fmt.Fprintln(w, "\nwarnings:")
+		for _, warn := range report.Warnings {
+			fmt.Fprintf(w, "  %s\n", warn)
+		}
+	}
+
+	for _, p := range report.Phases {
+		for _, prompt := range p.Prompts {
+			fmt.Fprintf(w, "\n--- %s phase, %s prompt ---\n%s\n", p.Name, prompt.Name, strings.TrimRight(prompt.Text, "\n"))
+		}
+	}
+}
+
+// dryRunLogger is a processor logger discarding all output, used to build the dry run report.
+type dryRunLogger struct {
+	path string
+}
+
+func (l *dryRunLogger) SetPhase(processor.Phase)       {}
+func (l *dryRunLogger) Print(string, ...any)           {}
+func (l *dryRunLogger) PrintRaw(string, ...any)        {}
+func (l *dryRunLogger) PrintSection(processor.Section) {}
+func (l *dryRunLogger) PrintAligned(string)            {}
+func (l *dryRunLogger) LogQuestion(string, []string)   {}
+func (l *dryRunLogger) LogAnswer(string)               {}
+func (l *dryRunLogger) Path() string                   { return l.path }
+
 // setupGitForExecution prepares git state for execution (branch, gitignore).
-func setupGitForExecution(gitOps *git.Repo, planFile string, mode processor.Mode, colors *progress.Colors) error {
+// branch is the feature branch name, empty derives it from the plan file name.
+func setupGitForExecution(gitOps *git.Repo, planFile, branch, base string, mode processor.Mode, colors *progress.Colors) error {
 	if planFile == "" {
 		return nil
 	}
 	if mode == processor.ModeFull {
-		if err := createBranchIfNeeded(gitOps, planFile, colors); err != nil {
+		if err := createBranchIfNeeded(gitOps, planFile, branch, base, colors); err != nil {
 			return err
 		}
 	}
 	return ensureGitignore(gitOps, colors)
 }
 
-// checkClaudeDep checks that the claude command is available in PATH.
+// checkClaudeDep checks that the commands of task and review executors are available in PATH.
+// it's the claude command unless custom executors are assigned to both roles.
 func checkClaudeDep(cfg *config.Config) error {
 	claudeCmd := cfg.ClaudeCommand
 	if claudeCmd == "" {
 		claudeCmd = "claude"
 	}
-	return checkDependencies(claudeCmd)
+	deps := make([]string, 0, 2)
+	for _, name := range []string{cfg.TaskExecutor, cfg.ReviewExecutor} {
+		dep := claudeCmd
+		if ec, ok := cfg.Executors[name]; ok {
+			dep = ec.Command
+		}
+		if !slices.Contains(deps, dep) {
+			deps = append(deps, dep)
+		}
+	}
+	return checkDependencies(deps...)
 }
 
 // isWatchOnlyMode returns true if running in watch-only mode.
@@ -424,26 +864,85 @@ func validateFlags(o opts) error {
 	if o.PlanDescription != "" && o.PlanFile != "" {
 		return errors.New("--plan flag conflicts with plan file argument; use one or the other")
 	}
+	if o.DryRun && o.PlanDescription != "" {
+		return errors.New("--dry-run is not supported with --plan")
+	}
+	if o.Template != "" && (o.PlanFile != "" || o.Review || o.CodexOnly) {
+		return errors.New("--template is used only for plan creation with --plan")
+	}
 	return nil
 }
 
+// resolvePipeline returns the phase pipeline for the run.
+// --phases overrides the pipeline in any mode, the config pipeline applies to full mode only.
+// returns nil if neither is set, so the runner uses the default pipeline of the mode.
+func resolvePipeline(o opts, cfg *config.Config, mode processor.Mode) ([]processor.Step, error) {
+	def := o.Phases
+	if def == "" && mode == processor.ModeFull {
+		def = cfg.Pipeline
+	}
+	if def == "" {
+		return nil, nil
+	}
+	pipeline, err := processor.ParsePipeline(def)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline: %w", err)
+	}
+	return pipeline, nil
+}
+
+// resolveConfirmPhases returns the phases followed by an approval gate.
+// --confirm-phases gates all phases of the pipeline, otherwise confirm_phases config lists them.
+func resolveConfirmPhases(o opts, cfg *config.Config, pipeline []processor.Step, mode processor.Mode) ([]processor.Step, error) {
+	if o.ConfirmPhases {
+		if len(pipeline) == 0 {
+			return processor.DefaultPipeline(mode), nil
+		}
+		return pipeline, nil
+	}
+	if len(cfg.ConfirmPhases) == 0 {
+		return nil, nil
+	}
+	confirm, err := processor.ParsePipeline(strings.Join(cfg.ConfirmPhases, ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid confirm_phases: %w", err)
+	}
+	return confirm, nil
+}
+
 // createRunner creates a processor.Runner with the given configuration.
-func createRunner(cfg *config.Config, o opts, planFile string, mode processor.Mode, log processor.Logger) *processor.Runner {
+// confirm lists the phases followed by an approval gate.
+func createRunner(cfg *config.Config, o opts, planFile string, mode processor.Mode, pipeline, confirm []processor.Step,
+	log processor.Logger) *processor.Runner {
 	// --codex-only mode forces codex enabled regardless of config
 	codexEnabled := cfg.CodexEnabled
 	if mode == processor.ModeCodexOnly {
 		codexEnabled = true
 	}
+	// approval gates are asked in terminal only with interactive stdin and no dashboard of the run,
+	// a prompt left waiting for stdin after the dashboard decision would take the input of the next prompt
+	webApproval := o.Serve || !term.IsTerminal(int(os.Stdin.Fd()))
 	return processor.New(processor.Config{
 		PlanFile:         planFile,
 		ProgressPath:     log.Path(),
+		BaseRef:          cfg.DefaultBranch,
 		Mode:             mode,
-		MaxIterations:    o.MaxIterations,
+		Pipeline:         pipeline,
+		MaxIterations:    maxIterations(o.MaxIterations, cfg),
 		Debug:            o.Debug,
 		NoColor:          o.NoColor,
 		IterationDelayMs: cfg.IterationDelayMs,
 		TaskRetryCount:   cfg.TaskRetryCount,
+		ParallelTasks:    cfg.ParallelTasks,
 		CodexEnabled:     codexEnabled,
+		StatePath:        processor.StatePath(log.Path()),
+		ReportPath:       runfile.ReportPath(log.Path()),
+		NotesPath:        processor.NotesPath(log.Path()),
+		ApprovalPath:     runfile.ApprovalPath(log.Path()),
+		ConfirmPhases:    confirm,
+		WebApproval:      webApproval,
+		Resume:           o.Resume,
+		Deadline:         o.Deadline.Time,
 		AppConfig:        cfg,
 	}, log)
 }
@@ -537,17 +1036,19 @@ func extractBranchName(planFile string) string {
 	return branchName
 }
 
-func createBranchIfNeeded(gitOps *git.Repo, planFile string, colors *progress.Colors) error {
+// createBranchIfNeeded creates or switches to the plan branch when running on the base branch.
+// branch is the name of the plan branch, empty derives it from the plan file name.
+func createBranchIfNeeded(gitOps *git.Repo, planFile, branch, base string, colors *progress.Colors) error {
 	currentBranch, err := gitOps.CurrentBranch()
 	if err != nil {
 		return fmt.Errorf("get current branch: %w", err)
 	}
 
-	if currentBranch != "main" && currentBranch != "master" {
+	if !isBaseBranch(currentBranch, base) {
 		return nil // already on feature branch
 	}
 
-	branchName := extractBranchName(planFile)
+	branchName := cmp.Or(branch, extractBranchName(planFile))
 
 	// check for uncommitted changes to files other than the plan
 	hasOtherChanges, err := gitOps.HasChangesOtherThan(planFile)
@@ -631,10 +1132,32 @@ func movePlanToCompleted(gitOps *git.Repo, planFile string, colors *progress.Col
 	return nil
 }
 
+// gitignoreEntries lists ralphex artifacts kept out of git.
+// sample is a filename used to check whether the pattern is already ignored.
+var gitignoreEntries = []struct {
+	sample  string
+	comment string
+	pattern string
+}{
+	{sample: "progress-test.txt", comment: "ralphex progress logs", pattern: "progress*.txt"},
+	{sample: "progress-test.state.json", comment: "ralphex run state checkpoints", pattern: "progress*.state.json"},
+	{sample: "progress-test.review-report.json", comment: "ralphex review findings reports", pattern: "progress*.review-report.json"},
+	{sample: "progress-test.notes.md", comment: "ralphex answers to questions asked during runs", pattern: "progress*.notes.md"},
+	{sample: "progress-test.approval.json", comment: "ralphex pending phase approvals", pattern: "progress*.approval.json"},
+}
+
 func ensureGitignore(gitOps *git.Repo, colors *progress.Colors) error {
-	// check if already ignored
-	ignored, err := gitOps.IsIgnored("progress-test.txt")
-	if err == nil && ignored {
+	// collect patterns not ignored yet
+	var missing []string
+	var block strings.Builder
+	for _, e := range gitignoreEntries {
+		if ignored, err := gitOps.IsIgnored(e.sample); err == nil && ignored {
+			continue
+		}
+		missing = append(missing, e.pattern)
+		fmt.Fprintf(&block, "\n# %s\n%s\n", e.comment, e.pattern)
+	}
+	if len(missing) == 0 {
 		return nil // already ignored
 	}
 
@@ -645,7 +1168,7 @@ func ensureGitignore(gitOps *git.Repo, colors *progress.Colors) error {
 		return fmt.Errorf("open .gitignore: %w", err)
 	}
 
-	if _, err := f.WriteString("\n# ralphex progress logs\nprogress*.txt\n"); err != nil {
+	if _, err := f.WriteString(block.String()); err != nil {
 		f.Close()
 		return fmt.Errorf("write .gitignore: %w", err)
 	}
@@ -654,7 +1177,7 @@ func ensureGitignore(gitOps *git.Repo, colors *progress.Colors) error {
 		return fmt.Errorf("close .gitignore: %w", err)
 	}
 
-	colors.Info().Println("added progress*.txt to .gitignore")
+	colors.Info().Printf("added %s to .gitignore\n", strings.Join(missing, ", "))
 	return nil
 }
 
@@ -708,6 +1231,9 @@ func printStartupInfo(info startupInfo, colors *progress.Colors) {
 	}
 	colors.Info().Printf("starting ralphex loop: %s (max %d iterations)%s\n", planStr, info.MaxIterations, modeStr)
 	colors.Info().Printf("branch: %s\n", info.Branch)
+	if !info.Deadline.IsZero() {
+		colors.Info().Printf("deadline: %s\n", info.Deadline.Format(time.DateTime))
+	}
 	colors.Info().Printf("progress log: %s\n\n", info.ProgressPath)
 }
 
@@ -722,7 +1248,7 @@ func runWatchOnly(ctx context.Context, o opts, cfg *config.Config, colors *progr
 	}
 
 	// setup server and watcher
-	srvErrCh, watchErrCh, err := setupWatchMode(ctx, o.Port, dirs)
+	srvErrCh, watchErrCh, err := setupWatchMode(ctx, o.Port, dirs, cfg.PlanGrammar)
 	if err != nil {
 		return err
 	}
@@ -736,7 +1262,7 @@ func runWatchOnly(ctx context.Context, o opts, cfg *config.Config, colors *progr
 
 // setupWatchMode creates and starts the web server and file watcher for watch-only mode.
 // returns error channels for monitoring both components.
-func setupWatchMode(ctx context.Context, port int, dirs []string) (chan error, chan error, error) {
+func setupWatchMode(ctx context.Context, port int, dirs []string, g plan.Grammar) (chan error, chan error, error) {
 	sm := web.NewSessionManager()
 	watcher, err := web.NewWatcher(dirs, sm)
 	if err != nil {
@@ -744,10 +1270,11 @@ func setupWatchMode(ctx context.Context, port int, dirs []string) (chan error, c
 	}
 
 	serverCfg := web.ServerConfig{
-		Port:     port,
-		PlanName: "(watch mode)",
-		Branch:   "",
-		PlanFile: "",
+		Port:        port,
+		PlanName:    "(watch mode)",
+		Branch:      "",
+		PlanFile:    "",
+		PlanGrammar: g,
 	}
 
 	srv, err := web.NewServerWithSessions(serverCfg, sm)
@@ -850,8 +1377,23 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		return gitignoreErr
 	}
 
+	var planTemplate string
+	if o.Template != "" {
+		tmpl, tmplErr := req.Config.PlanTemplate(o.Template)
+		if tmplErr != nil {
+			return tmplErr
+		}
+		planTemplate = tmpl
+	}
+
 	branch := getCurrentBranch(req.GitOps)
 
+	notifier, err := newNotifier(req.Config)
+	if err != nil {
+		return err
+	}
+	runInfo := notify.Message{Branch: branch, Mode: string(processor.ModePlan)}
+
 	// create progress logger for plan mode
 	baseLog, err := progress.NewLogger(progress.Config{
 		PlanDescription: o.PlanDescription,
@@ -869,10 +1411,10 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 	}()
 
 	// print startup info for plan mode
-	printPlanModeInfo(o.PlanDescription, branch, o.MaxIterations, baseLog.Path(), req.Colors)
+	printPlanModeInfo(o.PlanDescription, branch, maxIterations(o.MaxIterations, req.Config), baseLog.Path(), req.Colors)
 
-	// create input collector
-	collector := input.NewTerminalCollector()
+	// create input collector, pending questions are sent as notifications
+	collector := &notifyingCollector{Collector: input.NewTerminalCollector(), notifier: notifier, info: runInfo}
 
 	// record start time for finding the created plan
 	startTime := time.Now()
@@ -880,9 +1422,10 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 	// create and configure runner
 	r := processor.New(processor.Config{
 		PlanDescription:  o.PlanDescription,
+		PlanTemplate:     planTemplate,
 		ProgressPath:     baseLog.Path(),
 		Mode:             processor.ModePlan,
-		MaxIterations:    o.MaxIterations,
+		MaxIterations:    maxIterations(o.MaxIterations, req.Config),
 		Debug:            o.Debug,
 		NoColor:          o.NoColor,
 		IterationDelayMs: req.Config.IterationDelayMs,
@@ -892,12 +1435,16 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 
 	// run the plan creation loop
 	if runErr := r.Run(ctx); runErr != nil {
+		sendNotification(ctx, notifier, failureNotification(runInfo, runErr))
 		return fmt.Errorf("plan creation: %w", runErr)
 	}
 
 	// find the newly created plan file
-	planFile := findRecentPlan(req.Config.PlansDir, startTime)
+	planFile := processor.FindRecentPlan(req.Config.PlansDir, startTime)
 	elapsed := baseLog.Elapsed()
+	runInfo.Event, runInfo.PlanFile = notify.EventCompleted, planFile
+	runInfo.Text = fmt.Sprintf("plan creation completed in %s%s", elapsed, usageSuffix(r.Usage()))
+	sendNotification(ctx, notifier, runInfo)
 
 	// print completion message with plan file path if found
 	if planFile != "" {
@@ -905,9 +1452,9 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 		if relErr != nil {
 			relPath = planFile
 		}
-		req.Colors.Info().Printf("\nplan creation completed in %s, created %s\n", elapsed, relPath)
+		req.Colors.Info().Printf("\nplan creation completed in %s%s, created %s\n", elapsed, usageSuffix(r.Usage()), relPath)
 	} else {
-		req.Colors.Info().Printf("\nplan creation completed in %s\n", elapsed)
+		req.Colors.Info().Printf("\nplan creation completed in %s%s\n", elapsed, usageSuffix(r.Usage()))
 	}
 
 	// if no plan file found, can't continue to implementation
@@ -945,46 +1492,21 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest) error {
 func continuePlanExecution(ctx context.Context, o opts, req executePlanRequest) error {
 	req.Colors.Info().Printf("\ncontinuing with plan implementation...\n")
 
+	// the created plan may have frontmatter with its own settings
+	cfg, err := loadPlanConfig(req.Config, req.PlanFile, o.Base, req.GitOps)
+	if err != nil {
+		return err
+	}
+	req.Config = cfg
+
 	// create branch if needed
-	if branchErr := createBranchIfNeeded(req.GitOps, req.PlanFile, req.Colors); branchErr != nil {
+	if branchErr := createBranchIfNeeded(req.GitOps, req.PlanFile, cfg.Branch, cfg.DefaultBranch, req.Colors); branchErr != nil {
 		return branchErr
 	}
 
 	return executePlan(ctx, o, req)
 }
 
-// findRecentPlan finds the most recently modified .md file in plansDir
-// that was modified after startTime. Returns empty string if none found.
-func findRecentPlan(plansDir string, startTime time.Time) string {
-	// find all .md files in plansDir (excluding completed/ subdirectory)
-	pattern := filepath.Join(plansDir, "*.md")
-	plans, err := filepath.Glob(pattern)
-	if err != nil || len(plans) == 0 {
-		return ""
-	}
-
-	var recentPlan string
-	var recentTime time.Time
-
-	for _, plan := range plans {
-		info, statErr := os.Stat(plan)
-		if statErr != nil {
-			continue
-		}
-		// file must be modified after startTime
-		if info.ModTime().Before(startTime) {
-			continue
-		}
-		// find the most recent one
-		if recentPlan == "" || info.ModTime().After(recentTime) {
-			recentPlan = plan
-			recentTime = info.ModTime()
-		}
-	}
-
-	return recentPlan
-}
-
 // printPlanModeInfo prints startup information for plan creation mode.
 func printPlanModeInfo(description, branch string, maxIterations int, progressPath string, colors *progress.Colors) {
 	colors.Info().Printf("starting interactive plan creation\n")
@@ -1008,10 +1530,12 @@ func startWebDashboard(ctx context.Context, p webDashboardParams) (processor.Log
 	}
 
 	cfg := web.ServerConfig{
-		Port:     p.Port,
-		PlanName: planName,
-		Branch:   p.Branch,
-		PlanFile: p.PlanFile,
+		Port:        p.Port,
+		PlanName:    planName,
+		Branch:      p.Branch,
+		PlanFile:    p.PlanFile,
+		Settings:    p.Settings,
+		PlanGrammar: p.PlanGrammar,
 	}
 
 	// determine if we should use multi-session mode
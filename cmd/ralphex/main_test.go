@@ -3,6 +3,11 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,11 +17,14 @@ import (
 
 	gogit "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jessevdk/go-flags"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/notify"
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/progress"
 )
@@ -76,24 +84,28 @@ func TestPromptPlanDescription(t *testing.T) {
 	})
 }
 
-func TestIsMainBranch(t *testing.T) {
+func TestIsBaseBranch(t *testing.T) {
 	tests := []struct {
 		name     string
 		branch   string
+		base     string
 		expected bool
 	}{
-		{name: "main_is_main_branch", branch: "main", expected: true},
-		{name: "master_is_main_branch", branch: "master", expected: true},
-		{name: "feature_branch_is_not_main", branch: "feature-x", expected: false},
-		{name: "develop_is_not_main", branch: "develop", expected: false},
-		{name: "empty_is_not_main", branch: "", expected: false},
-		{name: "main_prefixed_is_not_main", branch: "main-feature", expected: false},
-		{name: "master_prefixed_is_not_main", branch: "master-fix", expected: false},
+		{name: "main_is_base", branch: "main", base: "main", expected: true},
+		{name: "master_is_base", branch: "master", base: "master", expected: true},
+		{name: "develop_is_base", branch: "develop", base: "develop", expected: true},
+		{name: "master_is_not_base_develop", branch: "master", base: "develop", expected: false},
+		{name: "master_is_base_main_fallback", branch: "master", base: "main", expected: true},
+		{name: "main_is_base_master_fallback", branch: "main", base: "master", expected: true},
+		{name: "feature_branch_is_not_base", branch: "feature-x", base: "master", expected: false},
+		{name: "empty_is_not_base", branch: "", base: "", expected: false},
+		{name: "main_prefixed_is_not_base", branch: "main-feature", base: "main", expected: false},
+		{name: "master_prefixed_is_not_base", branch: "master-fix", base: "master", expected: false},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			result := isMainBranch(tc.branch)
+			result := isBaseBranch(tc.branch, tc.base)
 			assert.Equal(t, tc.expected, result)
 		})
 	}
@@ -122,6 +134,214 @@ func TestDetermineMode(t *testing.T) {
 	}
 }
 
+func TestResolvePipeline(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     opts
+		cfg      config.Config
+		mode     processor.Mode
+		expected string
+		wantErr  string
+	}{
+		{name: "default_is_nil", mode: processor.ModeFull},
+		{name: "config_pipeline_full_mode", cfg: config.Config{Pipeline: "tasks, codex"}, mode: processor.ModeFull,
+			expected: "tasks, codex"},
+		{name: "config_pipeline_ignored_in_review_mode", cfg: config.Config{Pipeline: "tasks, codex"}, mode: processor.ModeReview},
+		{name: "phases_flag_overrides_config", opts: opts{Phases: "review_loop,custom:security"},
+			cfg: config.Config{Pipeline: "tasks, codex"}, mode: processor.ModeFull, expected: "review_loop, custom:security"},
+		{name: "phases_flag_in_codex_mode", opts: opts{Phases: "codex"}, mode: processor.ModeCodexOnly, expected: "codex"},
+		{name: "invalid_phase", opts: opts{Phases: "tasks, deploy"}, mode: processor.ModeFull, wantErr: "invalid pipeline"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pipeline, err := resolvePipeline(tc.opts, &tc.cfg, tc.mode)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Nil(t, pipeline)
+				return
+			}
+			assert.Equal(t, tc.expected, processor.FormatPipeline(pipeline))
+		})
+	}
+}
+
+func TestResolveConfirmPhases(t *testing.T) {
+	pipeline := []processor.Step{{Kind: processor.StepTasks}, {Kind: processor.StepCodex}}
+	tests := []struct {
+		name     string
+		opts     opts
+		cfg      config.Config
+		pipeline []processor.Step
+		mode     processor.Mode
+		expected string
+		wantErr  string
+	}{
+		{name: "no_gates_by_default", mode: processor.ModeFull},
+		{name: "flag_gates_default_pipeline", opts: opts{ConfirmPhases: true}, mode: processor.ModeCodexOnly,
+			expected: "codex, review_loop"},
+		{name: "flag_gates_custom_pipeline", opts: opts{ConfirmPhases: true}, pipeline: pipeline, mode: processor.ModeFull,
+			expected: "tasks, codex"},
+		{name: "config_list", cfg: config.Config{ConfirmPhases: []string{"tasks", "custom:security"}}, mode: processor.ModeFull,
+			expected: "tasks, custom:security"},
+		{name: "invalid_config_phase", cfg: config.Config{ConfirmPhases: []string{"deploy"}}, mode: processor.ModeFull,
+			wantErr: "invalid confirm_phases"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			confirm, err := resolveConfirmPhases(tc.opts, &tc.cfg, tc.pipeline, tc.mode)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tc.expected == "" {
+				assert.Nil(t, confirm)
+				return
+			}
+			assert.Equal(t, tc.expected, processor.FormatPipeline(confirm))
+		})
+	}
+}
+
+func TestParseDeadline(t *testing.T) {
+	now := time.Date(2026, 3, 10, 22, 30, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{name: "duration", value: "8h30m", want: now.Add(8*time.Hour + 30*time.Minute)},
+		{name: "clock time later today", value: "23:15", want: time.Date(2026, 3, 10, 23, 15, 0, 0, time.Local)},
+		{name: "clock time tomorrow", value: "07:00", want: time.Date(2026, 3, 11, 7, 0, 0, 0, time.Local)},
+		{name: "rfc3339", value: "2026-03-11T06:00:00Z", want: time.Date(2026, 3, 11, 6, 0, 0, 0, time.UTC)},
+		{name: "rfc3339 in the past", value: "2026-03-09T06:00:00Z", wantErr: "time is in the past"},
+		{name: "negative duration", value: "-1h", wantErr: "duration must be positive"},
+		{name: "garbage", value: "tomorrow", wantErr: "expected duration"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseDeadline(tc.value, now)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tc.want.Equal(got), "want %s, got %s", tc.want, got)
+		})
+	}
+
+	t.Run("flag parsing", func(t *testing.T) {
+		var o opts
+		_, err := flags.NewParser(&o, flags.Default).ParseArgs([]string{"--deadline", "2h"})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), o.Deadline.Time, time.Minute)
+	})
+}
+
+func TestUsageSuffix(t *testing.T) {
+	assert.Empty(t, usageSuffix(executor.Usage{}))
+	assert.Equal(t, ", 30 tokens (in 10, out 20), $0.5000",
+		usageSuffix(executor.Usage{InputTokens: 10, OutputTokens: 20, CostUSD: 0.5}))
+}
+
+func TestNewNotifier(t *testing.T) {
+	t.Run("no sinks configured", func(t *testing.T) {
+		n, err := newNotifier(&config.Config{})
+		require.NoError(t, err)
+		assert.False(t, n.Enabled(notify.EventFailed))
+	})
+
+	t.Run("webhook with event filter", func(t *testing.T) {
+		var got []notify.Message
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var msg notify.Message
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+			got = append(got, msg)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		n, err := newNotifier(&config.Config{NotifyWebhook: srv.URL, NotifyWebhookEvents: []string{"failed", "max_iterations"}})
+		require.NoError(t, err)
+		assert.False(t, n.Enabled(notify.EventCompleted))
+
+		info := notify.Message{PlanFile: "docs/plans/feature.md", Branch: "feature", Mode: "full"}
+		sendNotification(context.Background(), n, failureNotification(info, errors.New("claude execution: boom")))
+		info.Event = notify.EventCompleted
+		sendNotification(context.Background(), n, info)
+
+		require.Len(t, got, 1, "completed event is filtered out")
+		assert.Equal(t, notify.EventFailed, got[0].Event)
+		assert.Equal(t, "feature", got[0].Branch)
+		assert.Equal(t, "claude execution: boom", got[0].Error)
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		_, err := newNotifier(&config.Config{NotifyBell: true, NotifyBellEvents: []string{"done"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "notify_bell_events")
+	})
+}
+
+func TestFailureNotification(t *testing.T) {
+	info := notify.Message{Branch: "feature"}
+
+	msg := failureNotification(info, errors.New("review failed"))
+	assert.Equal(t, notify.EventFailed, msg.Event)
+	assert.Equal(t, "run failed: review failed", msg.Text)
+	assert.Equal(t, "feature", msg.Branch)
+
+	msg = failureNotification(info, fmt.Errorf("tasks phase: %w: 50 iterations without completion", processor.ErrMaxIterations))
+	assert.Equal(t, notify.EventMaxIterations, msg.Event)
+	assert.Equal(t, "tasks phase: max iterations reached: 50 iterations without completion", msg.Error)
+}
+
+// stubCollector answers questions with a fixed answer.
+type stubCollector struct {
+	answer string
+}
+
+func (c *stubCollector) AskQuestion(_ context.Context, _ string, _ []string) (string, error) {
+	return c.answer, nil
+}
+
+func TestNotifyingCollector(t *testing.T) {
+	sink := &recordingSink{}
+	n := &notify.Notifier{}
+	n.Add("test", sink, notify.AllEvents)
+
+	c := &notifyingCollector{Collector: &stubCollector{answer: "Redis"}, notifier: n, info: notify.Message{Mode: "plan"}}
+	answer, err := c.AskQuestion(context.Background(), "Which cache backend?", []string{"Redis", "In-memory"})
+	require.NoError(t, err)
+	assert.Equal(t, "Redis", answer)
+
+	require.Len(t, sink.msgs, 1)
+	assert.Equal(t, notify.EventQuestion, sink.msgs[0].Event)
+	assert.Equal(t, "question pending: Which cache backend?", sink.msgs[0].Text)
+	assert.Equal(t, "plan", sink.msgs[0].Mode)
+}
+
+// recordingSink records sent notifications.
+type recordingSink struct {
+	msgs []notify.Message
+}
+
+func (s *recordingSink) Send(_ context.Context, msg notify.Message) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
 func TestIsWatchOnlyMode(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -351,6 +571,17 @@ func TestCheckClaudeDep(t *testing.T) {
 		assert.Contains(t, err.Error(), "nonexistent-command-12345")
 	})
 
+	t.Run("uses_custom_executor_commands", func(t *testing.T) {
+		cfg := &config.Config{ClaudeCommand: "nonexistent-claude-12345", TaskExecutor: "agent", ReviewExecutor: "agent",
+			Executors: map[string]config.ExecutorConfig{"agent": {Name: "agent", Command: "sh"}}}
+		require.NoError(t, checkClaudeDep(cfg))
+
+		cfg.ReviewExecutor = ""
+		err := checkClaudeDep(cfg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nonexistent-claude-12345", "review still runs with claude")
+	})
+
 	t.Run("falls_back_to_claude_when_empty", func(t *testing.T) {
 		cfg := &config.Config{ClaudeCommand: ""}
 		err := checkClaudeDep(cfg)
@@ -398,6 +629,8 @@ func TestPreparePlanFile(t *testing.T) {
 }
 
 func TestCreateRunner(t *testing.T) {
+	t.Chdir(t.TempDir()) // loggers without a plan file write progress.txt to the working directory
+
 	t.Run("maps_config_correctly", func(t *testing.T) {
 		cfg := &config.Config{
 			IterationDelayMs: 5000,
@@ -412,7 +645,7 @@ func TestCreateRunner(t *testing.T) {
 		require.NoError(t, err)
 		defer log.Close()
 
-		runner := createRunner(cfg, o, "/path/to/plan.md", processor.ModeFull, log)
+		runner := createRunner(cfg, o, "/path/to/plan.md", processor.ModeFull, nil, nil, log)
 		assert.NotNil(t, runner)
 	})
 
@@ -426,7 +659,7 @@ func TestCreateRunner(t *testing.T) {
 		defer log.Close()
 
 		// in codex-only mode, CodexEnabled should be forced to true
-		runner := createRunner(cfg, o, "", processor.ModeCodexOnly, log)
+		runner := createRunner(cfg, o, "", processor.ModeCodexOnly, nil, nil, log)
 		assert.NotNil(t, runner)
 		// we can't directly check runner internals, but this tests the code path runs without panic
 	})
@@ -564,7 +797,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		require.NoError(t, err)
 
 		// should return nil without creating new branch
-		err = createBranchIfNeeded(repo, "docs/plans/some-plan.md", colors)
+		err = createBranchIfNeeded(repo, "docs/plans/some-plan.md", "", "master", colors)
 		require.NoError(t, err)
 
 		// verify still on feature-test
@@ -584,7 +817,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		assert.Equal(t, "master", branch)
 
 		// should create branch from plan filename
-		err = createBranchIfNeeded(repo, "docs/plans/add-feature.md", colors)
+		err = createBranchIfNeeded(repo, "docs/plans/add-feature.md", "", "master", colors)
 		require.NoError(t, err)
 
 		// verify switched to new branch
@@ -593,6 +826,19 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		assert.Equal(t, "add-feature", branch)
 	})
 
+	t.Run("uses_branch_name_from_plan", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		repo, err := git.Open(dir)
+		require.NoError(t, err)
+
+		err = createBranchIfNeeded(repo, "docs/plans/add-feature.md", "feature/cache", "master", colors)
+		require.NoError(t, err)
+
+		branch, err := repo.CurrentBranch()
+		require.NoError(t, err)
+		assert.Equal(t, "feature/cache", branch)
+	})
+
 	t.Run("switches_to_existing_branch", func(t *testing.T) {
 		dir := setupTestRepo(t)
 		repo, err := git.Open(dir)
@@ -607,7 +853,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		require.NoError(t, err)
 
 		// should switch to existing branch without error
-		err = createBranchIfNeeded(repo, "docs/plans/existing-feature.md", colors)
+		err = createBranchIfNeeded(repo, "docs/plans/existing-feature.md", "", "master", colors)
 		require.NoError(t, err)
 
 		branch, err := repo.CurrentBranch()
@@ -621,7 +867,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		require.NoError(t, err)
 
 		// plan file with date prefix
-		err = createBranchIfNeeded(repo, "docs/plans/2024-01-15-feature.md", colors)
+		err = createBranchIfNeeded(repo, "docs/plans/2024-01-15-feature.md", "", "master", colors)
 		require.NoError(t, err)
 
 		branch, err := repo.CurrentBranch()
@@ -634,7 +880,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		repo, err := git.Open(dir)
 		require.NoError(t, err)
 
-		err = createBranchIfNeeded(repo, "add-tests.md", colors)
+		err = createBranchIfNeeded(repo, "add-tests.md", "", "master", colors)
 		require.NoError(t, err)
 
 		branch, err := repo.CurrentBranch()
@@ -648,7 +894,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		require.NoError(t, err)
 
 		// edge case: plan with complex date prefix
-		err = createBranchIfNeeded(repo, "docs/plans/2024-01-15-12-30-my-feature.md", colors)
+		err = createBranchIfNeeded(repo, "docs/plans/2024-01-15-12-30-my-feature.md", "", "master", colors)
 		require.NoError(t, err)
 
 		branch, err := repo.CurrentBranch()
@@ -668,7 +914,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		require.NoError(t, os.WriteFile(planFile, []byte("# Auto Commit Test Plan\n"), 0o600))
 
 		// should create branch and auto-commit the plan
-		err = createBranchIfNeeded(repo, planFile, colors)
+		err = createBranchIfNeeded(repo, planFile, "", "master", colors)
 		require.NoError(t, err)
 
 		// verify we're on the new branch
@@ -697,7 +943,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		require.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other content"), 0o600))
 
 		// should return an error with helpful message
-		err = createBranchIfNeeded(repo, planFile, colors)
+		err = createBranchIfNeeded(repo, planFile, "", "master", colors)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "cannot create branch")
 		assert.Contains(t, err.Error(), "uncommitted changes")
@@ -721,7 +967,7 @@ func TestCreateBranchIfNeeded(t *testing.T) {
 		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Modified\n"), 0o600))
 
 		// should return an error
-		err = createBranchIfNeeded(repo, planFile, colors)
+		err = createBranchIfNeeded(repo, planFile, "", "master", colors)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "uncommitted changes")
 	})
@@ -915,18 +1161,23 @@ func TestEnsureGitignore(t *testing.T) {
 		err = ensureGitignore(repo, colors)
 		require.NoError(t, err)
 
-		// verify .gitignore was created with the pattern
+		// verify .gitignore was created with the patterns
 		content, err := os.ReadFile(filepath.Join(dir, ".gitignore")) //nolint:gosec // test file in temp dir
 		require.NoError(t, err)
 		assert.Contains(t, string(content), "progress*.txt")
+		assert.Contains(t, string(content), "progress*.state.json")
+		assert.Contains(t, string(content), "progress*.review-report.json")
+		assert.Contains(t, string(content), "progress*.notes.md")
+		assert.Contains(t, string(content), "progress*.approval.json")
 	})
 
 	t.Run("skips_when_already_ignored", func(t *testing.T) {
 		dir := setupTestRepo(t)
 
-		// create gitignore with pattern already present
+		// create gitignore with patterns already present
 		gitignore := filepath.Join(dir, ".gitignore")
-		err := os.WriteFile(gitignore, []byte("progress*.txt\n"), 0o600)
+		ignored := "progress*.txt\nprogress*.state.json\nprogress*.review-report.json\nprogress*.notes.md\nprogress*.approval.json\n"
+		err := os.WriteFile(gitignore, []byte(ignored), 0o600)
 		require.NoError(t, err)
 
 		repo, err := git.Open(dir)
@@ -947,7 +1198,32 @@ func TestEnsureGitignore(t *testing.T) {
 		// verify content unchanged (no duplicate pattern)
 		content, err := os.ReadFile(gitignore) //nolint:gosec // test file in temp dir
 		require.NoError(t, err)
-		assert.Equal(t, "progress*.txt\n", string(content))
+		assert.Equal(t, ignored, string(content))
+	})
+
+	t.Run("adds_only_missing_patterns", func(t *testing.T) {
+		dir := setupTestRepo(t)
+
+		gitignore := filepath.Join(dir, ".gitignore")
+		err := os.WriteFile(gitignore, []byte("progress*.txt\n"), 0o600)
+		require.NoError(t, err)
+
+		repo, err := git.Open(dir)
+		require.NoError(t, err)
+
+		origDir, err := os.Getwd()
+		require.NoError(t, err)
+		err = os.Chdir(dir)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+		err = ensureGitignore(repo, colors)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(gitignore) //nolint:gosec // test file in temp dir
+		require.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(string(content), "progress*.txt"), "existing pattern not duplicated")
+		assert.Contains(t, string(content), "progress*.state.json")
 	})
 
 	t.Run("creates_gitignore_if_missing", func(t *testing.T) {
@@ -984,6 +1260,8 @@ func TestEnsureGitignore(t *testing.T) {
 }
 
 func TestSetupRunnerLogger(t *testing.T) {
+	t.Chdir(t.TempDir()) // loggers without a plan file write progress.txt to the working directory
+
 	t.Run("returns_base_logger_when_serve_disabled", func(t *testing.T) {
 		colors := testColors()
 		baseLog, err := progress.NewLogger(progress.Config{
@@ -1067,7 +1345,7 @@ func TestSetupGitForExecution(t *testing.T) {
 		repo, err := git.Open(dir)
 		require.NoError(t, err)
 
-		err = setupGitForExecution(repo, "", processor.ModeFull, colors)
+		err = setupGitForExecution(repo, "", "", "master", processor.ModeFull, colors)
 		require.NoError(t, err)
 	})
 
@@ -1083,7 +1361,7 @@ func TestSetupGitForExecution(t *testing.T) {
 		require.NoError(t, err)
 		t.Cleanup(func() { _ = os.Chdir(origDir) })
 
-		err = setupGitForExecution(repo, "docs/plans/new-feature.md", processor.ModeFull, colors)
+		err = setupGitForExecution(repo, "docs/plans/new-feature.md", "", "master", processor.ModeFull, colors)
 		require.NoError(t, err)
 
 		// verify branch was created
@@ -1104,7 +1382,7 @@ func TestSetupGitForExecution(t *testing.T) {
 		require.NoError(t, err)
 		t.Cleanup(func() { _ = os.Chdir(origDir) })
 
-		err = setupGitForExecution(repo, "docs/plans/some-plan.md", processor.ModeReview, colors)
+		err = setupGitForExecution(repo, "docs/plans/some-plan.md", "", "master", processor.ModeReview, colors)
 		require.NoError(t, err)
 
 		// verify still on master (no branch created)
@@ -1205,6 +1483,11 @@ func TestValidateFlags(t *testing.T) {
 		{name: "plan_flag_only_is_valid", opts: opts{PlanDescription: "add feature"}, wantErr: false},
 		{name: "plan_file_only_is_valid", opts: opts{PlanFile: "docs/plans/test.md"}, wantErr: false},
 		{name: "both_plan_and_planfile_conflicts", opts: opts{PlanDescription: "add feature", PlanFile: "docs/plans/test.md"}, wantErr: true, errMsg: "conflicts"},
+		{name: "dry_run_with_plan_file_is_valid", opts: opts{DryRun: true, PlanFile: "docs/plans/test.md"}, wantErr: false},
+		{name: "dry_run_with_plan_flag_unsupported", opts: opts{DryRun: true, PlanDescription: "add feature"}, wantErr: true, errMsg: "--dry-run is not supported"},
+		{name: "template_with_plan_flag_is_valid", opts: opts{PlanDescription: "add feature", Template: "api-endpoint"}, wantErr: false},
+		{name: "template_with_plan_file_unsupported", opts: opts{PlanFile: "docs/plans/test.md", Template: "api-endpoint"}, wantErr: true, errMsg: "--template is used only"},
+		{name: "template_with_review_unsupported", opts: opts{Review: true, Template: "api-endpoint"}, wantErr: true, errMsg: "--template is used only"},
 	}
 
 	for _, tc := range tests {
@@ -1248,93 +1531,171 @@ func TestPrintStartupInfo(t *testing.T) {
 	})
 }
 
-func TestFindRecentPlan(t *testing.T) {
-	t.Run("finds_recently_modified_file", func(t *testing.T) {
-		dir := t.TempDir()
-		startTime := time.Now()
+func TestRunDryRun(t *testing.T) {
+	dir := setupTestRepo(t)
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
 
-		// create a plan file
-		planFile := filepath.Join(dir, "new-plan.md")
-		err := os.WriteFile(planFile, []byte("# New Plan"), 0o600)
-		require.NoError(t, err)
+	require.NoError(t, os.MkdirAll("docs/plans", 0o750))
+	planFile := filepath.Join("docs", "plans", "2026-01-15-feature.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Feature\n\n### Task 1: Add model\n- [ ] create struct\n"), 0o600))
 
-		// explicitly set mod time to be after startTime
-		futureTime := startTime.Add(time.Second)
-		err = os.Chtimes(planFile, futureTime, futureTime)
-		require.NoError(t, err)
+	require.NoError(t, run(context.Background(), opts{DryRun: true, PlanFile: planFile, MaxIterations: 50}))
 
-		result := findRecentPlan(dir, startTime)
-		assert.Equal(t, planFile, result)
-	})
+	// nothing is changed: no branch, no progress file, no .gitignore entries
+	gitOps, err := git.Open(".")
+	require.NoError(t, err)
+	assert.Equal(t, "master", getCurrentBranch(gitOps))
+	assert.False(t, gitOps.BranchExists("feature"))
+	assert.NoFileExists(t, "progress-2026-01-15-feature.txt")
+	assert.NoFileExists(t, ".gitignore")
+}
 
-	t.Run("returns_empty_for_old_files", func(t *testing.T) {
-		dir := t.TempDir()
-		startTime := time.Now()
+func TestDryRunBranch(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitOps, err := git.Open(dir)
+	require.NoError(t, err)
+	planFile := filepath.Join(dir, "docs", "plans", "2026-01-15-feature.md")
 
-		// create a plan file
-		planFile := filepath.Join(dir, "old-plan.md")
-		err := os.WriteFile(planFile, []byte("# Old Plan"), 0o600)
-		require.NoError(t, err)
+	assert.Equal(t, "feature (would be created from master)", dryRunBranch(gitOps, planFile, "", "master", processor.ModeFull))
+	assert.Equal(t, "master", dryRunBranch(gitOps, planFile, "", "master", processor.ModeReview))
+	assert.Equal(t, "master", dryRunBranch(gitOps, "", "", "master", processor.ModeFull))
 
-		// set mod time to be before startTime
-		pastTime := startTime.Add(-time.Hour)
-		err = os.Chtimes(planFile, pastTime, pastTime)
-		require.NoError(t, err)
+	require.NoError(t, gitOps.CreateBranch("feature"))
+	assert.Equal(t, "feature", dryRunBranch(gitOps, planFile, "", "master", processor.ModeFull), "already on feature branch")
 
-		result := findRecentPlan(dir, startTime)
-		assert.Empty(t, result)
-	})
+	require.NoError(t, gitOps.CheckoutBranch("master"))
+	assert.Equal(t, "feature (would switch to existing branch from master)", dryRunBranch(gitOps, planFile, "", "master", processor.ModeFull))
+	assert.Equal(t, "cache (would be created from master)", dryRunBranch(gitOps, planFile, "cache", "master", processor.ModeFull),
+		"branch name from plan frontmatter")
+}
 
-	t.Run("returns_most_recent_of_multiple_files", func(t *testing.T) {
-		dir := t.TempDir()
-		startTime := time.Now()
+func TestLoadPlanConfig(t *testing.T) {
+	dir := setupTestRepo(t)
+	t.Setenv("HOME", t.TempDir())
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+	gitOps, err := git.Open(".")
+	require.NoError(t, err)
 
-		// create first file with earlier mod time
-		plan1 := filepath.Join(dir, "plan1.md")
-		err := os.WriteFile(plan1, []byte("# Plan 1"), 0o600)
-		require.NoError(t, err)
-		time1 := startTime.Add(time.Second)
-		err = os.Chtimes(plan1, time1, time1)
-		require.NoError(t, err)
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	same, err := loadPlanConfig(cfg, "", "", gitOps)
+	require.NoError(t, err)
+	assert.Same(t, cfg, same, "no plan file")
 
-		// create second file with later mod time
-		plan2 := filepath.Join(dir, "plan2.md")
-		err = os.WriteFile(plan2, []byte("# Plan 2"), 0o600)
-		require.NoError(t, err)
-		time2 := startTime.Add(2 * time.Second)
-		err = os.Chtimes(plan2, time2, time2)
-		require.NoError(t, err)
+	planFile := filepath.Join(dir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile,
+		[]byte("---\nmax_iterations: 7\nbase_branch: develop\nbranch: cache\ncodex_enabled: false\n---\n# Plan\n"), 0o600))
+	planCfg, err := loadPlanConfig(cfg, planFile, "", gitOps)
+	require.NoError(t, err)
+	assert.Equal(t, "develop", planCfg.DefaultBranch)
+	assert.Equal(t, "cache", planCfg.Branch)
+	assert.False(t, planCfg.CodexEnabled)
+	assert.Equal(t, 7, maxIterations(0, planCfg))
 
-		result := findRecentPlan(dir, startTime)
-		assert.Equal(t, plan2, result)
-	})
+	planCfg, err = loadPlanConfig(cfg, planFile, "stack-parent", gitOps)
+	require.NoError(t, err)
+	assert.Equal(t, "stack-parent", planCfg.DefaultBranch, "--base flag wins over plan")
+	assert.Equal(t, 5, maxIterations(5, planCfg), "--max-iterations flag wins over plan")
 
-	t.Run("returns_empty_for_nonexistent_directory", func(t *testing.T) {
-		result := findRecentPlan("/nonexistent/directory", time.Now())
-		assert.Empty(t, result)
-	})
+	// plan settings go on top of the loaded config, config files are not loaded again
+	cfg.ClaudeArgs = "--loaded"
+	planCfg, err = loadPlanConfig(cfg, planFile, "", gitOps)
+	require.NoError(t, err)
+	assert.Equal(t, "--loaded", planCfg.ClaudeArgs)
+	assert.True(t, cfg.CodexEnabled, "loaded config is not modified")
+	assert.Empty(t, cfg.Branch)
 
-	t.Run("returns_empty_for_empty_directory", func(t *testing.T) {
-		dir := t.TempDir()
-		result := findRecentPlan(dir, time.Now())
-		assert.Empty(t, result)
-	})
+	require.NoError(t, os.WriteFile(planFile, []byte("---\nmax_iterations: x\n---\n# Plan\n"), 0o600))
+	_, err = loadPlanConfig(cfg, planFile, "", gitOps)
+	require.ErrorContains(t, err, "invalid max_iterations")
+}
 
-	t.Run("ignores_non_md_files", func(t *testing.T) {
-		dir := t.TempDir()
-		startTime := time.Now()
+func TestRunSettings(t *testing.T) {
+	cfg := &config.Config{MaxIterations: 20, ValidationTimeoutMs: 90000, DefaultBranch: "develop",
+		Agents: []string{"quality", "testing"}, ClaudeArgs: "--model opus"}
+	assert.Equal(t, "max_iterations=20, codex_enabled=false, validation_timeout=1m30s, base_branch=develop, "+
+		"agents=quality testing, claude_args=--model opus", runSettings(cfg, opts{}, processor.ModeFull))
+	assert.Equal(t, "max_iterations=5, codex_enabled=true, validation_timeout=none, base_branch=master, agents=all",
+		runSettings(&config.Config{DefaultBranch: "master"}, opts{MaxIterations: 5}, processor.ModeCodexOnly))
+}
 
-		// create non-md file with future mod time
-		txtFile := filepath.Join(dir, "notes.txt")
-		err := os.WriteFile(txtFile, []byte("notes"), 0o600)
-		require.NoError(t, err)
-		futureTime := startTime.Add(time.Second)
-		err = os.Chtimes(txtFile, futureTime, futureTime)
-		require.NoError(t, err)
+func TestMaxIterations(t *testing.T) {
+	assert.Equal(t, 10, maxIterations(10, &config.Config{MaxIterations: 20}), "flag wins")
+	assert.Equal(t, 20, maxIterations(0, &config.Config{MaxIterations: 20}), "config or plan frontmatter")
+	assert.Equal(t, defaultMaxIterations, maxIterations(0, &config.Config{}))
+}
 
-		result := findRecentPlan(dir, startTime)
-		assert.Empty(t, result)
-	})
+func TestResolveBaseBranch(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitOps, err := git.Open(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "stack-parent", resolveBaseBranch("stack-parent", "develop", gitOps), "flag wins")
+	assert.Equal(t, "develop", resolveBaseBranch("", "develop", gitOps), "config wins over detection")
+	assert.Equal(t, "master", resolveBaseBranch("", "", gitOps), "detected")
+}
+
+func TestCommandStatus(t *testing.T) {
+	assert.Equal(t, "ralphex-missing-command (not found in PATH)", commandStatus("ralphex-missing-command", "claude"))
+	if path, err := exec.LookPath("sh"); err == nil {
+		assert.Equal(t, "sh ("+path+")", commandStatus("", "sh"))
+	}
+}
+
+func TestPrintDryRun(t *testing.T) {
+	info := dryRunInfo{PlanFile: "docs/plans/feature.md", Mode: processor.ModeFull,
+		Branch: "feature (would be created from master)", Base: "master", Claude: "claude (/usr/bin/claude)",
+		Codex: "codex (not found in PATH)", ProgressPath: "progress-feature.txt"}
+	report := processor.DryRunReport{
+		Tasks:              []processor.DryRunTask{{Number: 2, Title: "Add API client"}},
+		ValidationCommands: []string{"go test ./..."},
+		Phases: []processor.DryRunPhase{
+			{Name: "tasks", MaxIterations: 50, Confirm: true, Prompts: []processor.DryRunPrompt{{Name: "claude", Text: "do the task\n"}}},
+			{Name: "review_first", MaxIterations: 1, Prompts: []processor.DryRunPrompt{{Name: "claude", Text: "review"}}},
+			{Name: "codex", MaxIterations: 10, Skip: "codex review disabled"},
+		},
+		Warnings: []string{`agent "pentester" not found, reference in tasks phase is left unexpanded`},
+	}
+
+	var buf bytes.Buffer
+	printDryRun(&buf, info, report)
+
+	want := `dry run: nothing is executed, git and the plan file are not modified
+plan: docs/plans/feature.md
+mode: full
+branch: feature (would be created from master)
+base: master
+claude: claude (/usr/bin/claude)
+codex: codex (not found in PATH)
+progress log: progress-feature.txt
+
+remaining tasks: 1
+  Task 2: Add API client
+
+validation commands:
+  go test ./...
+
+phases:
+  1. tasks (max 50 iterations, approval gate after)
+  2. review_first (single pass)
+  3. codex (skipped: codex review disabled)
+
+warnings:
+  agent "pentester" not found, reference in tasks phase is left unexpanded
+
+--- tasks phase, claude prompt ---
+do the task
+
+--- review_first phase, claude prompt ---
+review
+`
+	assert.Equal(t, want, buf.String())
 }
 
 func TestEnsureRepoHasCommits(t *testing.T) {
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+// slugRe matches runs of characters not allowed in plan file names.
+var slugRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// runNewCommand runs "ralphex new <template> <name>" and returns the process exit code.
+// it creates docs/plans/YYYY-MM-DD-<name>.md (plans_dir of the config) from the plan template,
+// without calling claude. without arguments it lists available templates.
+func runNewCommand(args []string, stdout, stderr io.Writer) int {
+	var o struct{}
+	parser := flags.NewParser(&o, flags.Default)
+	parser.Usage = "new <template> <name>"
+	args, err := parser.ParseArgs(args)
+	if err != nil {
+		var flagsErr *flags.Error
+		if errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(stderr, "error: load config: %v\n", err)
+		return 1
+	}
+
+	switch len(args) {
+	case 0:
+		fmt.Fprintln(stdout, "available plan templates:")
+		for _, name := range cfg.PlanTemplateNames() {
+			fmt.Fprintf(stdout, "  %s\n", name)
+		}
+		return 0
+	case 2:
+	default:
+		fmt.Fprintln(stderr, "error: usage: ralphex new <template> <name>")
+		return 1
+	}
+
+	path, err := newPlanFromTemplate(cfg, args[0], args[1], time.Now())
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(stdout, path)
+	return 0
+}
+
+// newPlanFromTemplate writes a dated plan file to the plans directory from the named template,
+// replacing {{PLAN_NAME}} and {{DATE}}. returns the path of the created plan, an existing plan is not overwritten.
+func newPlanFromTemplate(cfg *config.Config, tmplName, name string, now time.Time) (string, error) {
+	tmpl, err := cfg.PlanTemplate(tmplName)
+	if err != nil {
+		return "", err
+	}
+	slug := strings.Trim(slugRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		return "", fmt.Errorf("invalid plan name %q", name)
+	}
+
+	date := now.Format("2006-01-02")
+	path := filepath.Join(cfg.PlansDir, date+"-"+slug+".md")
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("plan %s already exists", path)
+	}
+	if err := os.MkdirAll(cfg.PlansDir, 0o750); err != nil {
+		return "", fmt.Errorf("create plans dir: %w", err)
+	}
+
+	content := strings.NewReplacer("{{PLAN_NAME}}", name, "{{DATE}}", date).Replace(tmpl)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return "", fmt.Errorf("write plan: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestNewPlanFromTemplate(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC)
+	plansDir := filepath.Join(t.TempDir(), "docs", "plans")
+	cfg := &config.Config{PlansDir: plansDir, PlanTemplates: map[string]string{
+		"api-endpoint": "# Plan: {{PLAN_NAME}}\ncreated {{DATE}}\n### Task 1: A\n- [ ] a\n",
+	}}
+
+	path, err := newPlanFromTemplate(cfg, "api-endpoint", "Orders API v2", now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(plansDir, "2026-03-15-orders-api-v2.md"), path)
+	data, err := os.ReadFile(path) //nolint:gosec // test path
+	require.NoError(t, err)
+	assert.Equal(t, "# Plan: Orders API v2\ncreated 2026-03-15\n### Task 1: A\n- [ ] a\n", string(data))
+
+	_, err = newPlanFromTemplate(cfg, "api-endpoint", "orders api v2", now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	_, err = newPlanFromTemplate(cfg, "unknown", "orders", now)
+	require.EqualError(t, err, `plan template "unknown" not found, available: api-endpoint`)
+
+	_, err = newPlanFromTemplate(cfg, "api-endpoint", " -- ", now)
+	require.EqualError(t, err, `invalid plan name " -- "`)
+}
+
+func TestRunNewCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{name: "list templates", args: nil, wantCode: 0, wantStdout: "  db-migration\n"},
+		{name: "create plan", args: []string{"db-migration", "add users table"}, wantCode: 0,
+			wantStdout: "-add-users-table.md\n"},
+		{name: "plan exists", args: []string{"db-migration", "add users table"}, wantCode: 1},
+		{name: "unknown template", args: []string{"bad", "name"}, wantCode: 1},
+		{name: "missing name", args: []string{"db-migration"}, wantCode: 1},
+		{name: "unknown flag", args: []string{"--bad-flag"}, wantCode: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tc.wantCode, runNewCommand(tc.args, &stdout, &stderr))
+			assert.Contains(t, stdout.String(), tc.wantStdout)
+		})
+	}
+
+	plans, err := filepath.Glob(filepath.Join("docs", "plans", "*-add-users-table.md"))
+	require.NoError(t, err)
+	require.Len(t, plans, 1)
+	data, err := os.ReadFile(plans[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Plan: add users table\n")
+}
@@ -2,13 +2,17 @@
 package config
 
 import (
+	"cmp"
 	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
-//go:embed defaults/config defaults/prompts/* defaults/agents/*
+//go:embed defaults/config defaults/prompts/* defaults/agents/* defaults/templates/*
 var defaultsFS embed.FS
 
 // prompt file names
@@ -30,6 +34,13 @@ const (
 //   - CodexTimeoutMsSet: tracks if codex_timeout_ms was explicitly set
 //   - IterationDelayMsSet: tracks if iteration_delay_ms was explicitly set
 //   - TaskRetryCountSet: tracks if task_retry_count was explicitly set
+//   - ValidationTimeoutSet: tracks if validation_timeout_ms was explicitly set
+//   - ValidationRetriesSet: tracks if validation_retries was explicitly set
+//   - IterationTimeoutSet: tracks if iteration_timeout was explicitly set
+//   - IdleOutputTimeoutSet: tracks if idle_output_timeout was explicitly set
+//   - ResumeSessionSet: tracks if resume_session was explicitly set
+//   - AutoAnswerSet: tracks if auto_answer_questions was explicitly set
+//   - NotifyBellSet: tracks if notify_bell was explicitly set
 type Config struct {
 	ClaudeCommand string `json:"claude_command"`
 	ClaudeArgs    string `json:"claude_args"`
@@ -48,8 +59,62 @@ type Config struct {
 	TaskRetryCount      int  `json:"task_retry_count"`
 	TaskRetryCountSet   bool `json:"-"` // tracks if task_retry_count was explicitly set in config
 
-	PlansDir  string   `json:"plans_dir"`
-	WatchDirs []string `json:"watch_dirs"` // directories to watch for progress files
+	ParallelTasks int `json:"parallel_tasks"` // max tasks executed concurrently in git worktrees, 0 or 1 is sequential
+	MaxIterations int `json:"max_iterations"` // max task iterations, 0 uses the --max-iterations default
+
+	ValidationTimeoutMs  int  `json:"validation_timeout_ms"`
+	ValidationTimeoutSet bool `json:"-"` // tracks if validation_timeout_ms was explicitly set in config
+	ValidationRetries    int  `json:"validation_retries"`
+	ValidationRetriesSet bool `json:"-"` // tracks if validation_retries was explicitly set in config
+
+	MaxCostUSD float64 `json:"max_cost_usd"` // stop the run when accumulated cost reaches this limit, 0 is unlimited
+	MaxTokens  int     `json:"max_tokens"`   // stop the run when accumulated tokens reach this limit, 0 is unlimited
+
+	IterationTimeout     time.Duration `json:"iteration_timeout"`   // max duration of a single claude run, 0 is unlimited
+	IterationTimeoutSet  bool          `json:"-"`                   // tracks if iteration_timeout was explicitly set in config
+	IdleOutputTimeout    time.Duration `json:"idle_output_timeout"` // kill claude after no output for this long, 0 disables
+	IdleOutputTimeoutSet bool          `json:"-"`                   // tracks if idle_output_timeout was explicitly set in config
+	ResumeSession        bool          `json:"resume_session"`      // continue the previous claude session on retries and review iterations
+	ResumeSessionSet     bool          `json:"-"`                   // tracks if resume_session was explicitly set in config
+
+	AutoAnswer    bool `json:"auto_answer_questions"` // answer questions of task and review runs with the first option
+	AutoAnswerSet bool `json:"-"`                     // tracks if auto_answer_questions was explicitly set in config
+
+	// lifecycle hooks, shell commands run with RALPHEX_* environment describing the run
+	HookPreTask   string `json:"hook_pre_task"`
+	HookPostTask  string `json:"hook_post_task"`
+	HookPrePhase  string `json:"hook_pre_phase"`
+	HookPostRun   string `json:"hook_post_run"`
+	HookOnFailure string `json:"hook_on_failure"`
+
+	// notifications, events lists are empty for all events
+	NotifyWebhook       string   `json:"notify_webhook"`
+	NotifyWebhookEvents []string `json:"notify_webhook_events"`
+	NotifyCommand       string   `json:"notify_command"`
+	NotifyCommandEvents []string `json:"notify_command_events"`
+	NotifyBell          bool     `json:"notify_bell"`
+	NotifyBellSet       bool     `json:"-"` // tracks if notify_bell was explicitly set in config
+	NotifyBellEvents    []string `json:"notify_bell_events"`
+
+	PlansDir string `json:"plans_dir"`
+
+	// plan format, patterns of task headers and checkboxes compiled into PlanGrammar
+	TaskHeaderPattern string       `json:"task_header_pattern"`
+	CheckboxPattern   string       `json:"checkbox_pattern"`
+	PlanGrammar       plan.Grammar `json:"-"`
+
+	DefaultBranch string   `json:"default_branch"` // base branch of feature branches and reviewed diffs, empty is detected
+	Branch        string   `json:"branch"`         // feature branch name from plan frontmatter, empty derives it from the plan name
+	Agents        []string `json:"agents"`         // custom agents used by review prompts, empty means all
+	WatchDirs     []string `json:"watch_dirs"`     // directories to watch for progress files
+	Pipeline      string   `json:"pipeline"`       // comma-separated phase pipeline for full mode, empty uses default
+	ConfirmPhases []string `json:"confirm_phases"` // phases followed by an approval gate
+
+	// executors assigned to roles: claude, codex or a custom executor name, empty keeps the built-in default
+	TaskExecutor            string                    `json:"task_executor"`
+	ReviewExecutor          string                    `json:"review_executor"`
+	ExternalReviewExecutors []string                  `json:"external_review_executors"`
+	Executors               map[string]ExecutorConfig `json:"executors"` // custom executors keyed by name
 
 	// output colors (RGB values as comma-separated strings)
 	Colors ColorConfig `json:"-"`
@@ -61,9 +126,15 @@ type Config struct {
 	CodexPrompt        string `json:"-"`
 	MakePlanPrompt     string `json:"-"`
 
+	// custom prompts for pipeline custom:<name> phases, keyed by file name without extension
+	CustomPrompts map[string]string `json:"-"`
+
 	// custom agents (loaded separately from files)
 	CustomAgents []CustomAgent `json:"-"`
 
+	// plan templates keyed by name, used by --template and "ralphex new"
+	PlanTemplates map[string]string `json:"-"`
+
 	configDir string // private, global config directory set by Load()
 	localDir  string // private, local project config directory (.ralphex/) if found
 }
@@ -156,6 +227,10 @@ func loadWithLocal(globalDir, localDir string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("load prompts: %w", err)
 	}
+	customPrompts, err := pl.LoadCustom(localPromptsPath, globalPromptsPath)
+	if err != nil {
+		return nil, fmt.Errorf("load custom prompts: %w", err)
+	}
 
 	// load agents
 	var localAgentsPath, globalAgentsPath string
@@ -169,38 +244,136 @@ func loadWithLocal(globalDir, localDir string) (*Config, error) {
 		return nil, fmt.Errorf("load agents: %w", err)
 	}
 
+	// load plan templates
+	var localTemplatesPath, globalTemplatesPath string
+	if localDir != "" {
+		localTemplatesPath = filepath.Join(localDir, "templates")
+	}
+	globalTemplatesPath = filepath.Join(globalDir, "templates")
+	templates, err := newTemplateLoader(embedFS).Load(localTemplatesPath, globalTemplatesPath)
+	if err != nil {
+		return nil, fmt.Errorf("load plan templates: %w", err)
+	}
+
+	grammar, err := plan.NewGrammar(values.TaskHeaderPattern, values.CheckboxPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan format: %w", err)
+	}
+
 	// assemble config
 	c := &Config{
-		ClaudeCommand:        values.ClaudeCommand,
-		ClaudeArgs:           values.ClaudeArgs,
-		CodexEnabled:         values.CodexEnabled,
-		CodexEnabledSet:      values.CodexEnabledSet,
-		CodexCommand:         values.CodexCommand,
-		CodexModel:           values.CodexModel,
-		CodexReasoningEffort: values.CodexReasoningEffort,
-		CodexTimeoutMs:       values.CodexTimeoutMs,
-		CodexTimeoutMsSet:    values.CodexTimeoutMsSet,
-		CodexSandbox:         values.CodexSandbox,
-		IterationDelayMs:     values.IterationDelayMs,
-		IterationDelayMsSet:  values.IterationDelayMsSet,
-		TaskRetryCount:       values.TaskRetryCount,
-		TaskRetryCountSet:    values.TaskRetryCountSet,
-		PlansDir:             values.PlansDir,
-		WatchDirs:            values.WatchDirs,
-		Colors:               colors,
-		TaskPrompt:           prompts.Task,
-		ReviewFirstPrompt:    prompts.ReviewFirst,
-		ReviewSecondPrompt:   prompts.ReviewSecond,
-		CodexPrompt:          prompts.Codex,
-		MakePlanPrompt:       prompts.MakePlan,
-		CustomAgents:         agents,
-		configDir:            globalDir,
-		localDir:             localDir,
+		ClaudeCommand:           values.ClaudeCommand,
+		ClaudeArgs:              values.ClaudeArgs,
+		CodexEnabled:            values.CodexEnabled,
+		CodexEnabledSet:         values.CodexEnabledSet,
+		CodexCommand:            values.CodexCommand,
+		CodexModel:              values.CodexModel,
+		CodexReasoningEffort:    values.CodexReasoningEffort,
+		CodexTimeoutMs:          values.CodexTimeoutMs,
+		CodexTimeoutMsSet:       values.CodexTimeoutMsSet,
+		CodexSandbox:            values.CodexSandbox,
+		IterationDelayMs:        values.IterationDelayMs,
+		IterationDelayMsSet:     values.IterationDelayMsSet,
+		TaskRetryCount:          values.TaskRetryCount,
+		TaskRetryCountSet:       values.TaskRetryCountSet,
+		MaxIterations:           values.MaxIterations,
+		ParallelTasks:           values.ParallelTasks,
+		ValidationTimeoutMs:     values.ValidationTimeoutMs,
+		ValidationTimeoutSet:    values.ValidationTimeoutSet,
+		ValidationRetries:       values.ValidationRetries,
+		ValidationRetriesSet:    values.ValidationRetriesSet,
+		MaxCostUSD:              values.MaxCostUSD,
+		MaxTokens:               values.MaxTokens,
+		IterationTimeout:        values.IterationTimeout,
+		IterationTimeoutSet:     values.IterationTimeoutSet,
+		IdleOutputTimeout:       values.IdleOutputTimeout,
+		IdleOutputTimeoutSet:    values.IdleOutputTimeoutSet,
+		ResumeSession:           values.ResumeSession,
+		ResumeSessionSet:        values.ResumeSessionSet,
+		AutoAnswer:              values.AutoAnswer,
+		AutoAnswerSet:           values.AutoAnswerSet,
+		HookPreTask:             values.HookPreTask,
+		HookPostTask:            values.HookPostTask,
+		HookPrePhase:            values.HookPrePhase,
+		HookPostRun:             values.HookPostRun,
+		HookOnFailure:           values.HookOnFailure,
+		NotifyWebhook:           values.NotifyWebhook,
+		NotifyWebhookEvents:     values.NotifyWebhookEvents,
+		NotifyCommand:           values.NotifyCommand,
+		NotifyCommandEvents:     values.NotifyCommandEvents,
+		NotifyBell:              values.NotifyBell,
+		NotifyBellSet:           values.NotifyBellSet,
+		NotifyBellEvents:        values.NotifyBellEvents,
+		PlansDir:                values.PlansDir,
+		TaskHeaderPattern:       values.TaskHeaderPattern,
+		CheckboxPattern:         values.CheckboxPattern,
+		PlanGrammar:             grammar,
+		DefaultBranch:           values.DefaultBranch,
+		Branch:                  values.Branch,
+		Agents:                  values.Agents,
+		WatchDirs:               values.WatchDirs,
+		Pipeline:                values.Pipeline,
+		ConfirmPhases:           values.ConfirmPhases,
+		TaskExecutor:            values.TaskExecutor,
+		ReviewExecutor:          values.ReviewExecutor,
+		ExternalReviewExecutors: values.ExternalReviewExecutors,
+		Executors:               values.Executors,
+		Colors:                  colors,
+		TaskPrompt:              prompts.Task,
+		ReviewFirstPrompt:       prompts.ReviewFirst,
+		ReviewSecondPrompt:      prompts.ReviewSecond,
+		CodexPrompt:             prompts.Codex,
+		MakePlanPrompt:          prompts.MakePlan,
+		CustomPrompts:           customPrompts,
+		CustomAgents:            agents,
+		PlanTemplates:           templates,
+		configDir:               globalDir,
+		localDir:                localDir,
 	}
 
 	return c, nil
 }
 
+// WithPlan returns a copy of the config with settings from the frontmatter of the plan file on top,
+// plan settings win. config files are not loaded again, the plan can set only keys of planFrontmatterKeys.
+func (c *Config) WithPlan(planFile string) (*Config, error) {
+	fm, err := newValuesLoader(defaultsFS).LoadPlan(Values{}, planFile)
+	if err != nil {
+		return nil, fmt.Errorf("load plan settings: %w", err)
+	}
+
+	res := *c
+	if fm.MaxIterations > 0 {
+		res.MaxIterations = fm.MaxIterations
+	}
+	if fm.CodexEnabledSet {
+		res.CodexEnabled, res.CodexEnabledSet = fm.CodexEnabled, true
+	}
+	if fm.ClaudeArgs != "" {
+		res.ClaudeArgs = fm.ClaudeArgs
+	}
+	if fm.ValidationTimeoutSet {
+		res.ValidationTimeoutMs, res.ValidationTimeoutSet = fm.ValidationTimeoutMs, true
+	}
+	if fm.DefaultBranch != "" {
+		res.DefaultBranch = fm.DefaultBranch
+	}
+	if fm.Branch != "" {
+		res.Branch = fm.Branch
+	}
+	if len(fm.Agents) > 0 {
+		res.Agents = fm.Agents
+	}
+	if fm.TaskHeaderPattern != "" || fm.CheckboxPattern != "" {
+		res.TaskHeaderPattern = cmp.Or(fm.TaskHeaderPattern, res.TaskHeaderPattern)
+		res.CheckboxPattern = cmp.Or(fm.CheckboxPattern, res.CheckboxPattern)
+		if res.PlanGrammar, err = plan.NewGrammar(res.TaskHeaderPattern, res.CheckboxPattern); err != nil {
+			return nil, fmt.Errorf("invalid plan format: %w", err)
+		}
+	}
+	return &res, nil
+}
+
 // DefaultConfigDir returns the default configuration directory path.
 // returns ~/.config/ralphex/ on all platforms.
 // if os.UserHomeDir() fails, falls back to ./.config/ralphex/ silently -
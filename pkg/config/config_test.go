@@ -28,10 +28,12 @@ func Test_defaultsFS_PromptFiles(t *testing.T) {
 		file     string
 		contains []string
 	}{
-		{file: "defaults/prompts/task.txt", contains: []string{"{{PLAN_FILE}}", "{{PROGRESS_FILE}}", "RALPHEX:ALL_TASKS_DONE", "RALPHEX:TASK_FAILED"}},
-		{file: "defaults/prompts/review_first.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:testing}}"}},
-		{file: "defaults/prompts/review_second.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:implementation}}"}},
-		{file: "defaults/prompts/codex.txt", contains: []string{"{{CODEX_OUTPUT}}", "RALPHEX:CODEX_REVIEW_DONE", "GPT-5.2"}},
+		{file: "defaults/prompts/task.txt", contains: []string{"{{PLAN_FILE}}", "{{PROGRESS_FILE}}", "RALPHEX:ALL_TASKS_DONE", "RALPHEX:TASK_FAILED", "RALPHEX:QUESTION",
+			"RALPHEX:TASK_BLOCKED", "## Manual Steps", "<!-- ralphex:ignore -->",
+			"{{TASK_HEADER_PATTERN}}", "{{CHECKBOX_PATTERN}}"}},
+		{file: "defaults/prompts/review_first.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "RALPHEX:FINDINGS", "RALPHEX:QUESTION", "{{agent:quality}}", "{{agent:testing}}"}},
+		{file: "defaults/prompts/review_second.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "RALPHEX:FINDINGS", "RALPHEX:QUESTION", "{{agent:quality}}", "{{agent:implementation}}"}},
+		{file: "defaults/prompts/codex.txt", contains: []string{"{{CODEX_OUTPUT}}", "RALPHEX:CODEX_REVIEW_DONE", "RALPHEX:FINDINGS", "RALPHEX:QUESTION", "GPT-5.2"}},
 	}
 
 	for _, tc := range testCases {
@@ -201,6 +203,9 @@ func TestLoad_PartialConfig(t *testing.T) {
 	assert.Equal(t, 3600000, cfg.CodexTimeoutMs)
 	assert.True(t, cfg.CodexEnabled)
 	assert.Equal(t, 1, cfg.TaskRetryCount)
+	assert.Equal(t, 600000, cfg.ValidationTimeoutMs)
+	assert.Equal(t, 3, cfg.ValidationRetries)
+	assert.True(t, cfg.ValidationRetriesSet)
 }
 
 func TestLoad_EmptyConfig(t *testing.T) {
@@ -321,6 +326,92 @@ func TestLocalConfig_NoLocalDir(t *testing.T) {
 	assert.Empty(t, cfg.LocalDir())
 }
 
+func TestLocalConfig_PlanGrammar(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, ".ralphex")
+	require.NoError(t, os.MkdirAll(localDir, 0o700))
+
+	cfg, err := loadWithLocal(globalDir, localDir)
+	require.NoError(t, err)
+	assert.True(t, cfg.PlanGrammar.IsDefault())
+
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"),
+		[]byte("task_header_pattern = ^## Step (?P<num>\\d+): (?P<title>.*)$\n"), 0o600))
+	cfg, err = loadWithLocal(globalDir, localDir)
+	require.NoError(t, err)
+	assert.False(t, cfg.PlanGrammar.IsDefault())
+	assert.Equal(t, `^## Step (?P<num>\d+): (?P<title>.*)$`, cfg.PlanGrammar.TaskHeaderPattern())
+	require.Len(t, cfg.PlanGrammar.Parse("## Step 1: A\n- [ ] a\n").Tasks, 1)
+
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("checkbox_pattern = ^- \\[[ x]\\]\n"), 0o600))
+	_, err = loadWithLocal(globalDir, localDir)
+	require.ErrorContains(t, err, `invalid plan format: checkbox pattern has no "state" group`)
+}
+
+func TestLocalConfig_PlanFrontmatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, ".ralphex")
+	require.NoError(t, os.MkdirAll(localDir, 0o700))
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "config"),
+		[]byte("max_iterations = 20\ncodex_enabled = true\nclaude_args = --global\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("claude_args = --local\n"), 0o600))
+
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("---\ncodex_enabled: false\nbranch: cache\n---\n# Plan\n"), 0o600))
+
+	loaded, err := loadWithLocal(globalDir, localDir)
+	require.NoError(t, err)
+	cfg, err := loaded.WithPlan(planFile)
+	require.NoError(t, err)
+	assert.False(t, cfg.CodexEnabled, "plan wins over global")
+	assert.Equal(t, "--local", cfg.ClaudeArgs, "local wins over global")
+	assert.Equal(t, 20, cfg.MaxIterations)
+	assert.Equal(t, "cache", cfg.Branch)
+
+	require.NoError(t, os.WriteFile(planFile, []byte("---\nclaude_args: --plan\n---\n# Plan\n"), 0o600))
+	cfg, err = loaded.WithPlan(planFile)
+	require.NoError(t, err)
+	assert.Equal(t, "--plan", cfg.ClaudeArgs, "plan wins over local")
+	assert.True(t, cfg.CodexEnabled)
+	assert.Empty(t, cfg.Branch)
+
+	assert.Equal(t, "--local", loaded.ClaudeArgs, "loaded config is not modified")
+
+	_, err = loaded.WithPlan(filepath.Join(tmpDir, "missing.md"))
+	require.ErrorContains(t, err, "load plan settings")
+}
+
+func TestConfig_WithPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{MaxIterations: 20, ClaudeArgs: "--loaded", ClaudeCommand: "claude", DefaultBranch: "main"}
+
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("---\nmax_iterations: 5\nagents: [quality]\n"+
+		"task_header_pattern: ^##\\s+Step\\s+(?P<num>\\d+):\\s*(?P<title>.*)$\n---\n# Plan\n## Step 1: A\n- [ ] a\n"), 0o600))
+	planCfg, err := cfg.WithPlan(planFile)
+	require.NoError(t, err)
+	assert.Equal(t, 5, planCfg.MaxIterations)
+	assert.Equal(t, []string{"quality"}, planCfg.Agents)
+	assert.Equal(t, "--loaded", planCfg.ClaudeArgs, "settings not in plan are kept")
+	assert.Equal(t, "main", planCfg.DefaultBranch)
+	assert.Equal(t, "claude", planCfg.ClaudeCommand)
+	doc, err := planCfg.PlanGrammar.Load(planFile)
+	require.NoError(t, err)
+	require.Len(t, doc.Tasks, 1, "plan format from frontmatter")
+	assert.Equal(t, 20, cfg.MaxIterations, "config itself is not modified")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("---\ncheckbox_pattern: ^-(?P<text>.*)$\n---\n"), 0o600))
+	_, err = cfg.WithPlan(planFile)
+	require.ErrorContains(t, err, "invalid plan format")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("---\nhook_post_run: ./deploy.sh\n---\n"), 0o600))
+	_, err = cfg.WithPlan(planFile)
+	require.ErrorContains(t, err, "load plan settings: parse plan frontmatter: hook_post_run is not allowed in plan frontmatter")
+}
+
 func TestLocalConfig_WithLocalDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalDir := filepath.Join(tmpDir, "global")
@@ -512,6 +603,25 @@ func TestLocalConfig_LocalPromptsOverrideGlobal(t *testing.T) {
 	assert.Equal(t, "global review first", cfg.ReviewFirstPrompt)
 }
 
+func TestLocalConfig_CustomPromptsAndPipeline(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, ".ralphex")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "prompts"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "prompts"), 0o700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "security.txt"), []byte("global security"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "docs.txt"), []byte("local docs"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "config"), []byte("pipeline = tasks, custom:docs"), 0o600))
+
+	cfg, err := loadWithLocal(globalDir, localDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tasks, custom:docs", cfg.Pipeline)
+	assert.Equal(t, map[string]string{"security": "global security", "docs": "local docs"}, cfg.CustomPrompts)
+}
+
 func TestLocalConfig_LocalAgentsReplaceGlobal(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalDir := filepath.Join(tmpDir, "global")
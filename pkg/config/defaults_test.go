@@ -449,7 +449,7 @@ func TestReset_ResetsConfigFile(t *testing.T) {
 	data, err = os.ReadFile(configPath) //nolint:gosec // test
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "claude_command = claude")
-	assert.NotContains(t, string(data), "custom")
+	assert.NotContains(t, string(data), "claude_command = custom")
 }
 
 func TestReset_ResetsPromptsDirectory(t *testing.T) {
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// built-in executor names, custom executors are defined in [executor:NAME] config sections
+const (
+	ExecutorClaude = "claude"
+	ExecutorCodex  = "codex"
+)
+
+// custom executor types
+const (
+	ExecutorTypeCommand = "command" // agent CLI run as a command
+	ExecutorTypeOpenAI  = "openai"  // OpenAI-compatible chat completions API, e.g. ollama, vLLM or llama.cpp server
+)
+
+// executor output formats
+const (
+	ExecutorOutputText  = "text"  // plain text output
+	ExecutorOutputJSONL = "jsonl" // one JSON event per line, text is taken from text_path
+)
+
+// executorSectionPrefix is the config section syntax of custom executors, e.g. "[executor:gemini]".
+const executorSectionPrefix = "executor:"
+
+// executorNamePattern restricts custom executor names.
+var executorNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ExecutorConfig holds settings of a custom coding agent CLI from an [executor:NAME] config section.
+type ExecutorConfig struct {
+	Name            string            `json:"name"`
+	Type            string            `json:"type"`             // executor type, command or openai
+	Command         string            `json:"command"`          // command to execute
+	Args            string            `json:"args"`             // arguments with optional {{PROMPT}} or {{PROMPT_FILE}} placeholder
+	Output          string            `json:"output"`           // output format, text or jsonl
+	TextPath        string            `json:"text_path"`        // dot-separated path of the text field in jsonl events
+	TextFilter      map[string]string `json:"text_filter"`      // field values jsonl events must have to be used as text
+	SignalCompleted string            `json:"signal_completed"` // regex of the done signal, empty detects default signals only
+	SignalFailed    string            `json:"signal_failed"`    // regex of the failure signal, empty detects default signals only
+	SignalQuestion  string            `json:"signal_question"`  // regex of a question, the first group captures the question JSON
+	URL             string            `json:"url"`              // base URL of the openai API, e.g. http://localhost:11434/v1
+	Model           string            `json:"model"`            // model name for the openai API
+	APIKeyEnv       string            `json:"api_key_env"`      // environment variable holding the openai API key
+	ContextSize     int               `json:"context_size"`     // model context size in tokens for the openai API, 0 is the default
+	Timeout         time.Duration     `json:"timeout"`          // max duration of a single run, 0 is unlimited
+}
+
+// parseExecutorSections parses [executor:NAME] sections of a config file.
+// returns nil if the file has no executor sections.
+func parseExecutorSections(cfg *ini.File) (map[string]ExecutorConfig, error) {
+	var executors map[string]ExecutorConfig
+	for _, section := range cfg.Sections() {
+		name, ok := strings.CutPrefix(section.Name(), executorSectionPrefix)
+		if !ok {
+			continue
+		}
+		ec, err := parseExecutorSection(strings.TrimSpace(name), section)
+		if err != nil {
+			return nil, fmt.Errorf("executor %q: %w", name, err)
+		}
+		if executors == nil {
+			executors = make(map[string]ExecutorConfig)
+		}
+		executors[ec.Name] = ec
+	}
+	return executors, nil
+}
+
+// parseExecutorSection parses a single executor section.
+func parseExecutorSection(name string, section *ini.Section) (ExecutorConfig, error) {
+	if !executorNamePattern.MatchString(name) {
+		return ExecutorConfig{}, errors.New("invalid name, use letters, digits, - and _")
+	}
+	if name == ExecutorClaude || name == ExecutorCodex {
+		return ExecutorConfig{}, errors.New("name is reserved for the built-in executor")
+	}
+
+	ec := ExecutorConfig{Name: name, Type: ExecutorTypeCommand, Output: ExecutorOutputText}
+	if key, err := section.GetKey("type"); err == nil {
+		switch val := strings.TrimSpace(key.String()); val {
+		case ExecutorTypeCommand, ExecutorTypeOpenAI:
+			ec.Type = val
+		default:
+			return ExecutorConfig{}, fmt.Errorf("invalid type %q, expected command or openai", val)
+		}
+	}
+	if key, err := section.GetKey("timeout"); err == nil {
+		val, durErr := key.Duration()
+		if durErr != nil {
+			return ExecutorConfig{}, fmt.Errorf("invalid timeout: %w", durErr)
+		}
+		if val < 0 {
+			return ExecutorConfig{}, fmt.Errorf("invalid timeout: must be non-negative, got %s", val)
+		}
+		ec.Timeout = val
+	}
+	if ec.Type == ExecutorTypeOpenAI {
+		return parseOpenAIExecutor(ec, section)
+	}
+
+	ec.Command = strings.TrimSpace(section.Key("command").String())
+	if ec.Command == "" {
+		return ExecutorConfig{}, errors.New("command is required")
+	}
+	ec.Args = strings.TrimSpace(section.Key("args").String())
+	if strings.Contains(ec.Args, "{{PROMPT}}") && strings.Contains(ec.Args, "{{PROMPT_FILE}}") {
+		return ExecutorConfig{}, errors.New("args can't use both {{PROMPT}} and {{PROMPT_FILE}}")
+	}
+
+	if key, err := section.GetKey("output"); err == nil {
+		switch val := strings.TrimSpace(key.String()); val {
+		case ExecutorOutputText, ExecutorOutputJSONL:
+			ec.Output = val
+		default:
+			return ExecutorConfig{}, fmt.Errorf("invalid output %q, expected text or jsonl", val)
+		}
+	}
+
+	ec.TextPath = strings.TrimSpace(section.Key("text_path").String())
+	if ec.Output == ExecutorOutputJSONL && ec.TextPath == "" {
+		return ExecutorConfig{}, errors.New("text_path is required for jsonl output")
+	}
+
+	for _, item := range splitList(section.Key("text_filter").String()) {
+		field, value, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(field) == "" {
+			return ExecutorConfig{}, fmt.Errorf("invalid text_filter %q, expected field=value", item)
+		}
+		if ec.TextFilter == nil {
+			ec.TextFilter = make(map[string]string)
+		}
+		ec.TextFilter[strings.TrimSpace(field)] = strings.TrimSpace(value)
+	}
+
+	for _, sig := range []struct {
+		key string
+		val *string
+	}{{"signal_completed", &ec.SignalCompleted}, {"signal_failed", &ec.SignalFailed}, {"signal_question", &ec.SignalQuestion}} {
+		*sig.val = strings.TrimSpace(section.Key(sig.key).String())
+		if *sig.val == "" {
+			continue
+		}
+		re, err := regexp.Compile(*sig.val)
+		if err != nil {
+			return ExecutorConfig{}, fmt.Errorf("invalid %s: %w", sig.key, err)
+		}
+		if sig.key == "signal_question" && re.NumSubexp() == 0 {
+			return ExecutorConfig{}, errors.New("invalid signal_question: no group capturing the question JSON")
+		}
+	}
+	return ec, nil
+}
+
+// parseOpenAIExecutor parses settings of an openai executor section.
+func parseOpenAIExecutor(ec ExecutorConfig, section *ini.Section) (ExecutorConfig, error) {
+	ec.Output = ""
+	ec.URL = strings.TrimSpace(section.Key("url").String())
+	if ec.URL == "" {
+		return ExecutorConfig{}, errors.New("url is required for openai executor")
+	}
+	if u, err := url.Parse(ec.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ExecutorConfig{}, fmt.Errorf("invalid url %q, expected http(s)://host[:port]/path", ec.URL)
+	}
+	ec.Model = strings.TrimSpace(section.Key("model").String())
+	if ec.Model == "" {
+		return ExecutorConfig{}, errors.New("model is required for openai executor")
+	}
+	ec.APIKeyEnv = strings.TrimSpace(section.Key("api_key_env").String())
+
+	if key, err := section.GetKey("context_size"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil || val <= 0 {
+			return ExecutorConfig{}, fmt.Errorf("invalid context_size %q, expected positive number of tokens", key.String())
+		}
+		ec.ContextSize = val
+	}
+	return ec, nil
+}
+
+// validateExecutorRoles checks that executors assigned to roles are built-in or defined in config.
+func (v *Values) validateExecutorRoles() error {
+	roles := []struct{ key, name string }{
+		{"task_executor", v.TaskExecutor},
+		{"review_executor", v.ReviewExecutor},
+	}
+	for _, name := range v.ExternalReviewExecutors {
+		roles = append(roles, struct{ key, name string }{"external_review_executor", name})
+	}
+	for _, role := range roles {
+		if role.name == "" || role.name == ExecutorClaude || role.name == ExecutorCodex {
+			continue
+		}
+		ec, ok := v.Executors[role.name]
+		if !ok {
+			return fmt.Errorf("invalid %s: executor %q is not defined, add an [executor:%s] section", role.key, role.name, role.name)
+		}
+		// openai executors can't run tools, they only review the diff given to them
+		if ec.Type == ExecutorTypeOpenAI && role.key != "external_review_executor" {
+			return fmt.Errorf("invalid %s: openai executor %q can't edit code, use it as external_review_executor", role.key, role.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValuesLoader_Load_Executors(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	global := `external_review_executor = local-llm, aider, local-llm
+
+[executor:gemini]
+command = gemini
+args = --yolo --output-format stream-json -p {{PROMPT}}
+output = jsonl
+text_path = content
+text_filter = type=message, role=assistant
+timeout = 45m
+signal_completed = (?m)^STATUS: DONE$
+signal_question = ASK: (\{.*\})
+
+[executor:aider]
+command = aider
+args = --message-file {{PROMPT_FILE}}
+
+[executor:local-llm]
+type = openai
+url = http://localhost:11434/v1
+model = qwen2.5-coder:32b
+api_key_env = LOCAL_LLM_KEY
+context_size = 65536
+timeout = 20m
+`
+	local := `task_executor = opencode
+
+[executor:opencode]
+command = opencode
+args = run
+
+[executor:aider]
+command = /opt/aider/bin/aider
+args = --yes --message {{PROMPT}}
+`
+	require.NoError(t, os.WriteFile(globalConfig, []byte(global), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte(local), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "opencode", values.TaskExecutor)
+	assert.Empty(t, values.ReviewExecutor)
+	assert.Equal(t, []string{"local-llm", "aider"}, values.ExternalReviewExecutors, "duplicates are dropped")
+	require.Len(t, values.Executors, 4)
+	assert.Equal(t, ExecutorConfig{Name: "gemini", Type: ExecutorTypeCommand, Command: "gemini", Args: "--yolo --output-format stream-json -p {{PROMPT}}",
+		Output: ExecutorOutputJSONL, TextPath: "content", TextFilter: map[string]string{"type": "message", "role": "assistant"},
+		SignalCompleted: `(?m)^STATUS: DONE$`, SignalQuestion: `ASK: (\{.*\})`, Timeout: 45 * time.Minute},
+		values.Executors["gemini"])
+	assert.Equal(t, ExecutorConfig{Name: "opencode", Type: ExecutorTypeCommand, Command: "opencode", Args: "run",
+		Output: ExecutorOutputText},
+		values.Executors["opencode"])
+	assert.Equal(t, ExecutorConfig{Name: "local-llm", Type: ExecutorTypeOpenAI, URL: "http://localhost:11434/v1",
+		Model: "qwen2.5-coder:32b", APIKeyEnv: "LOCAL_LLM_KEY", ContextSize: 65536, Timeout: 20 * time.Minute},
+		values.Executors["local-llm"])
+	assert.Equal(t, "/opt/aider/bin/aider", values.Executors["aider"].Command, "local section replaces global one")
+
+	// embedded defaults keep built-in executors
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.TaskExecutor)
+	assert.Empty(t, values.Executors)
+}
+
+func TestValuesLoader_Load_ExecutorErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{name: "undefined role executor", config: "review_executor = gemini",
+			wantErr: `invalid review_executor: executor "gemini" is not defined`},
+		{name: "undefined external reviewer", config: "external_review_executor = codex, gemini",
+			wantErr: `invalid external_review_executor: executor "gemini" is not defined`},
+		{name: "missing command", config: "[executor:gemini]\nargs = -p {{PROMPT}}", wantErr: `executor "gemini": command is required`},
+		{name: "reserved name", config: "[executor:codex]\ncommand = codex", wantErr: "name is reserved"},
+		{name: "invalid name", config: "[executor:my agent]\ncommand = agent", wantErr: "invalid name"},
+		{name: "invalid output", config: "[executor:gemini]\ncommand = gemini\noutput = xml", wantErr: `invalid output "xml"`},
+		{name: "jsonl without text path", config: "[executor:gemini]\ncommand = gemini\noutput = jsonl",
+			wantErr: "text_path is required"},
+		{name: "both prompt placeholders", config: "[executor:gemini]\ncommand = gemini\nargs = {{PROMPT}} {{PROMPT_FILE}}",
+			wantErr: "can't use both"},
+		{name: "invalid filter", config: "[executor:gemini]\ncommand = gemini\noutput = jsonl\ntext_path = text\ntext_filter = role",
+			wantErr: `invalid text_filter "role"`},
+		{name: "invalid signal pattern", config: "[executor:gemini]\ncommand = gemini\nsignal_failed = (FAILED",
+			wantErr: "invalid signal_failed: error parsing regexp"},
+		{name: "question pattern without group", config: "[executor:gemini]\ncommand = gemini\nsignal_question = ASK:.*",
+			wantErr: "invalid signal_question: no group capturing the question JSON"},
+		{name: "invalid timeout", config: "[executor:gemini]\ncommand = gemini\ntimeout = soon", wantErr: "invalid timeout"},
+		{name: "invalid type", config: "[executor:gemini]\ntype = grpc\ncommand = gemini", wantErr: `invalid type "grpc"`},
+		{name: "openai without url", config: "[executor:llm]\ntype = openai\nmodel = qwen", wantErr: "url is required"},
+		{name: "openai invalid url", config: "[executor:llm]\ntype = openai\nurl = localhost:11434\nmodel = qwen",
+			wantErr: `invalid url "localhost:11434"`},
+		{name: "openai without model", config: "[executor:llm]\ntype = openai\nurl = http://localhost:11434/v1",
+			wantErr: "model is required"},
+		{name: "openai invalid context size", config: "[executor:llm]\ntype = openai\nurl = http://llm/v1\nmodel = q\ncontext_size = 0",
+			wantErr: `invalid context_size "0"`},
+		{name: "openai task executor", config: "task_executor = llm\n[executor:llm]\ntype = openai\nurl = http://llm/v1\nmodel = q",
+			wantErr: `invalid task_executor: openai executor "llm" can't edit code`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config")
+			require.NoError(t, os.WriteFile(configPath, []byte(tc.config), 0o600))
+
+			_, err := newValuesLoader(defaultsFS).Load("", configPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
@@ -63,6 +63,49 @@ func (p *promptLoader) Load(localDir, globalDir string) (Prompts, error) {
 	return prompts, nil
 }
 
+// LoadCustom loads user-provided prompt files that are not built-in prompts.
+// these are used by custom:<name> pipeline phases. local files override global ones per name,
+// there is no embedded fallback.
+func (p *promptLoader) LoadCustom(localDir, globalDir string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, dir := range []string{globalDir, localDir} {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read prompts directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") || isBuiltinPrompt(entry.Name()) {
+				continue
+			}
+			content, err := p.loadPromptFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			if content == "" {
+				continue
+			}
+			result[strings.TrimSuffix(entry.Name(), ".txt")] = content
+		}
+	}
+	return result, nil
+}
+
+// isBuiltinPrompt returns true if filename is one of the built-in prompt files.
+func isBuiltinPrompt(filename string) bool {
+	switch filename {
+	case taskPromptFile, reviewFirstPromptFile, reviewSecondPromptFile, codexPromptFile, makePlanPromptFile:
+		return true
+	default:
+		return false
+	}
+}
+
 // loadPromptWithLocalFallback loads a prompt file with fallback chain: local → global → embedded.
 // localDir can be empty to skip local lookup.
 func (p *promptLoader) loadPromptWithLocalFallback(localDir, globalDir, filename string) (string, error) {
@@ -430,3 +430,32 @@ func TestPromptLoader_Load_MakePlanPrompt_LocalOverridesGlobal(t *testing.T) {
 
 	assert.Equal(t, "local make plan", prompts.MakePlan)
 }
+
+func TestPromptLoader_LoadCustom(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global", "prompts")
+	localDir := filepath.Join(tmpDir, "local", "prompts")
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "subdir"), 0o700))
+
+	// built-in prompts are not custom
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "task.txt"), []byte("global task prompt"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "security.txt"), []byte("global security"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "docs.txt"), []byte("global docs"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "notes.md"), []byte("not a prompt"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "security.txt"), []byte("# comment\nlocal security"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "empty.txt"), []byte("# only comment\n"), 0o600))
+
+	loader := newPromptLoader(defaultsFS)
+	custom, err := loader.LoadCustom(localDir, globalDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"security": "local security", "docs": "global docs"}, custom)
+}
+
+func TestPromptLoader_LoadCustom_NoDirs(t *testing.T) {
+	loader := newPromptLoader(defaultsFS)
+	custom, err := loader.LoadCustom("", filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, custom)
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// planTemplateExt is the extension of plan template files.
+const planTemplateExt = ".md"
+
+// templateLoader loads plan templates from config directories with embedded fallback.
+type templateLoader struct {
+	embedFS embed.FS
+}
+
+// newTemplateLoader creates a new templateLoader with the given embedded filesystem.
+func newTemplateLoader(embedFS embed.FS) *templateLoader {
+	return &templateLoader{embedFS: embedFS}
+}
+
+// Load loads plan templates keyed by name, the file name without the .md extension.
+// each template is taken with fallback chain: local → global → embedded, an empty file falls back
+// to the next location. unlike prompts, comment lines are not stripped, "#" starts markdown headers.
+func (tl *templateLoader) Load(localDir, globalDir string) (map[string]string, error) {
+	result := make(map[string]string)
+
+	entries, err := tl.embedFS.ReadDir("defaults/templates")
+	if err != nil {
+		return nil, fmt.Errorf("read embedded templates dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), planTemplateExt) {
+			continue
+		}
+		data, err := tl.embedFS.ReadFile("defaults/templates/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read embedded template %s: %w", entry.Name(), err)
+		}
+		result[strings.TrimSuffix(entry.Name(), planTemplateExt)] = string(data)
+	}
+
+	for _, dir := range []string{globalDir, localDir} {
+		if err := tl.loadDir(dir, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// loadDir adds templates of a directory to result, replacing templates with the same name.
+// a missing directory is not an error.
+func (tl *templateLoader) loadDir(dir string, result map[string]string) error {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read templates directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), planTemplateExt) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path) //nolint:gosec // path is constructed internally
+		if err != nil {
+			return fmt.Errorf("read template file %s: %w", path, err)
+		}
+		if strings.TrimSpace(string(data)) == "" {
+			continue
+		}
+		result[strings.TrimSuffix(entry.Name(), planTemplateExt)] = strings.ReplaceAll(string(data), "\r\n", "\n")
+	}
+	return nil
+}
+
+// PlanTemplate returns the content of the plan template with the given name.
+func (c *Config) PlanTemplate(name string) (string, error) {
+	tmpl, ok := c.PlanTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("plan template %q not found, available: %s", name, strings.Join(c.PlanTemplateNames(), ", "))
+	}
+	return tmpl, nil
+}
+
+// PlanTemplateNames returns sorted names of available plan templates.
+func (c *Config) PlanTemplateNames() []string {
+	names := make([]string, 0, len(c.PlanTemplates))
+	for name := range c.PlanTemplates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateLoader_Load_Embedded(t *testing.T) {
+	templates, err := newTemplateLoader(defaultsFS).Load("", filepath.Join(t.TempDir(), "nonexistent"))
+	require.NoError(t, err)
+
+	for _, name := range []string{"api-endpoint", "db-migration", "cli-command"} {
+		require.Contains(t, templates, name)
+		assert.Contains(t, templates[name], "# Plan: {{PLAN_NAME}}", name)
+		assert.Contains(t, templates[name], "### Task 1:", name)
+	}
+}
+
+func TestTemplateLoader_Load_LocalOverridesGlobal(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.MkdirAll(globalDir, 0o700))
+	require.NoError(t, os.MkdirAll(localDir, 0o700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "api-endpoint.md"), []byte("# global api"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "service.md"), []byte("# global service"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "service.md"), []byte("# local service\r\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "db-migration.md"), []byte("  \n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "notes.txt"), []byte("not a template"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "sub.md"), 0o700))
+
+	templates, err := newTemplateLoader(defaultsFS).Load(localDir, globalDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "# global api", templates["api-endpoint"], "global overrides embedded")
+	assert.Equal(t, "# local service\n", templates["service"], "local overrides global")
+	assert.Contains(t, templates["db-migration"], "{{PLAN_NAME}}", "empty file falls back to embedded")
+	assert.Contains(t, templates, "cli-command")
+	assert.NotContains(t, templates, "notes")
+	assert.NotContains(t, templates, "sub")
+}
+
+func TestTemplateLoader_Load_UnreadableDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	notDir := filepath.Join(tmpDir, "templates")
+	require.NoError(t, os.WriteFile(notDir, []byte("file"), 0o600))
+
+	_, err := newTemplateLoader(defaultsFS).Load(notDir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read templates directory")
+}
+
+func TestConfig_PlanTemplate(t *testing.T) {
+	cfg := &Config{PlanTemplates: map[string]string{"b": "tmpl b", "a": "tmpl a"}}
+	assert.Equal(t, []string{"a", "b"}, cfg.PlanTemplateNames())
+
+	tmpl, err := cfg.PlanTemplate("b")
+	require.NoError(t, err)
+	assert.Equal(t, "tmpl b", tmpl)
+
+	_, err = cfg.PlanTemplate("c")
+	require.EqualError(t, err, `plan template "c" not found, available: a, b`)
+}
+
+func TestLoad_PlanTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global")
+	localDir := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "templates"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "templates"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "templates", "service.md"), []byte("# global"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "templates", "service.md"), []byte("# local"), 0o600))
+
+	cfg, err := loadWithLocal(globalDir, "")
+	require.NoError(t, err)
+	assert.Equal(t, "# global", cfg.PlanTemplates["service"])
+	assert.Contains(t, cfg.PlanTemplates, "api-endpoint")
+
+	cfg, err = loadWithLocal(globalDir, localDir)
+	require.NoError(t, err)
+	assert.Equal(t, "# local", cfg.PlanTemplates["service"])
+}
@@ -4,9 +4,13 @@ import (
 	"embed"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"gopkg.in/ini.v1"
+
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 // Values holds scalar configuration values.
@@ -28,8 +32,48 @@ type Values struct {
 	IterationDelayMsSet  bool // tracks if iteration_delay_ms was explicitly set
 	TaskRetryCount       int
 	TaskRetryCountSet    bool // tracks if task_retry_count was explicitly set
+	MaxIterations        int  // max task iterations, 0 uses the --max-iterations default
+	ParallelTasks        int  // max tasks executed concurrently in git worktrees, 0 or 1 is sequential
+	ValidationTimeoutMs  int
+	ValidationTimeoutSet bool // tracks if validation_timeout_ms was explicitly set
+	ValidationRetries    int
+	ValidationRetriesSet bool    // tracks if validation_retries was explicitly set
+	MaxCostUSD           float64 // stop the run when accumulated cost reaches this limit, 0 is unlimited
+	MaxTokens            int     // stop the run when accumulated tokens reach this limit, 0 is unlimited
+	IterationTimeout     time.Duration
+	IterationTimeoutSet  bool // tracks if iteration_timeout was explicitly set
+	IdleOutputTimeout    time.Duration
+	IdleOutputTimeoutSet bool   // tracks if idle_output_timeout was explicitly set
+	ResumeSession        bool   // continue the claude session of the previous run on task retries and review iterations
+	ResumeSessionSet     bool   // tracks if resume_session was explicitly set
+	AutoAnswer           bool   // answer questions of task and review runs with the first option instead of asking
+	AutoAnswerSet        bool   // tracks if auto_answer_questions was explicitly set
+	HookPreTask          string // shell command run before each task iteration
+	HookPostTask         string // shell command run after each task iteration
+	HookPrePhase         string // shell command run before each pipeline phase
+	HookPostRun          string // shell command run when the run ends
+	HookOnFailure        string // shell command run when the run fails
+	NotifyWebhook        string
+	NotifyWebhookEvents  []string // events posted to notify_webhook, empty means all
+	NotifyCommand        string
+	NotifyCommandEvents  []string // events notify_command runs for, empty means all
+	NotifyBell           bool
+	NotifyBellSet        bool     // tracks if notify_bell was explicitly set
+	NotifyBellEvents     []string // events ringing the terminal bell, empty means all
 	PlansDir             string
+	TaskHeaderPattern    string   // regexp of plan task headers, empty uses the default
+	CheckboxPattern      string   // regexp of plan checkboxes, empty uses the default
+	DefaultBranch        string   // base branch of feature branches and reviewed diffs, empty is detected
+	Branch               string   // feature branch name, set by plan frontmatter only
+	Agents               []string // custom agents used by review prompts, empty means all
 	WatchDirs            []string // directories to watch for progress files
+	Pipeline             string   // comma-separated phase pipeline for full mode
+	ConfirmPhases        []string // phases followed by an approval gate
+
+	TaskExecutor            string                    // executor of the task phase, empty is claude
+	ReviewExecutor          string                    // executor of claude review phases, empty is claude
+	ExternalReviewExecutors []string                  // executors of the external review loop run in parallel, empty is codex
+	Executors               map[string]ExecutorConfig // custom executors from [executor:NAME] sections
 }
 
 // valuesLoader implements ValuesLoader with embedded filesystem fallback.
@@ -70,9 +114,72 @@ func (vl *valuesLoader) Load(localConfigPath, globalConfigPath string) (Values,
 	result.mergeFrom(&global)
 	result.mergeFrom(&local)
 
+	// roles may refer to executors defined in another config file, checked after merge
+	if roleErr := result.validateExecutorRoles(); roleErr != nil {
+		return Values{}, roleErr
+	}
+
 	return result, nil
 }
 
+// LoadPlan merges settings from the frontmatter of a plan file over values, plan settings win.
+// returns values unchanged if the plan has no frontmatter.
+func (vl *valuesLoader) LoadPlan(values Values, planFile string) (Values, error) {
+	doc, err := plan.Load(planFile)
+	if err != nil {
+		return Values{}, err
+	}
+	if strings.TrimSpace(doc.Frontmatter) == "" {
+		return values, nil
+	}
+
+	fm, err := vl.parsePlanFrontmatter([]byte(doc.Frontmatter))
+	if err != nil {
+		return Values{}, fmt.Errorf("parse plan frontmatter: %w", err)
+	}
+	values.mergeFrom(&fm)
+	return values, nil
+}
+
+// planFrontmatterKeys lists the keys allowed in plan frontmatter. plans come with the repository and
+// its branches, so settings running commands (executors, hooks, notifications) are accepted only in config.
+var planFrontmatterKeys = []string{"max_iterations", "codex_enabled", "claude_args", "validation_timeout_ms",
+	"default_branch", "base_branch", "branch", "agents", "task_header_pattern", "checkbox_pattern"}
+
+// parsePlanFrontmatter parses plan frontmatter into Values. it takes the keys of planFrontmatterKeys,
+// "key: value" lines of yaml-style frontmatter included. plan-only keys are "branch", the name of
+// the feature branch, and "base_branch", an alias of default_branch. other keys and sections are rejected.
+func (vl *valuesLoader) parsePlanFrontmatter(data []byte) (Values, error) {
+	cfg, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, data)
+	if err != nil {
+		return Values{}, fmt.Errorf("parse config: %w", err)
+	}
+	for _, sec := range cfg.Sections() {
+		if sec.Name() != ini.DefaultSection {
+			return Values{}, fmt.Errorf("section [%s] is not allowed in plan frontmatter, set it in config", sec.Name())
+		}
+	}
+	section := cfg.Section("")
+	for _, key := range section.Keys() {
+		if !slices.Contains(planFrontmatterKeys, key.Name()) {
+			return Values{}, fmt.Errorf("%s is not allowed in plan frontmatter, set it in config (allowed: %s)",
+				key.Name(), strings.Join(planFrontmatterKeys, ", "))
+		}
+	}
+
+	values, err := vl.parseValuesFromBytes(data)
+	if err != nil {
+		return Values{}, err
+	}
+	if key, err := section.GetKey("base_branch"); err == nil {
+		values.DefaultBranch = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("branch"); err == nil {
+		values.Branch = strings.TrimSpace(key.String())
+	}
+	return values, nil
+}
+
 // parseValuesFromFile reads a config file and parses it into Values.
 // returns empty Values (not error) if file doesn't exist.
 func (vl *valuesLoader) parseValuesFromFile(path string) (Values, error) {
@@ -177,23 +284,208 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.TaskRetryCount = val
 		values.TaskRetryCountSet = true
 	}
+	if key, err := section.GetKey("max_iterations"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid max_iterations: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid max_iterations: must be non-negative, got %d", val)
+		}
+		values.MaxIterations = val
+	}
+	if key, err := section.GetKey("parallel_tasks"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid parallel_tasks: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid parallel_tasks: must be non-negative, got %d", val)
+		}
+		values.ParallelTasks = val
+	}
+
+	// validation gate settings
+	if key, err := section.GetKey("validation_timeout_ms"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid validation_timeout_ms: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid validation_timeout_ms: must be non-negative, got %d", val)
+		}
+		values.ValidationTimeoutMs = val
+		values.ValidationTimeoutSet = true
+	}
+	if key, err := section.GetKey("validation_retries"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid validation_retries: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid validation_retries: must be non-negative, got %d", val)
+		}
+		values.ValidationRetries = val
+		values.ValidationRetriesSet = true
+	}
+
+	// claude run timeouts, duration values like "45m"
+	if key, err := section.GetKey("iteration_timeout"); err == nil {
+		val, durErr := key.Duration()
+		if durErr != nil {
+			return Values{}, fmt.Errorf("invalid iteration_timeout: %w", durErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid iteration_timeout: must be non-negative, got %s", val)
+		}
+		values.IterationTimeout = val
+		values.IterationTimeoutSet = true
+	}
+	if key, err := section.GetKey("idle_output_timeout"); err == nil {
+		val, durErr := key.Duration()
+		if durErr != nil {
+			return Values{}, fmt.Errorf("invalid idle_output_timeout: %w", durErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid idle_output_timeout: must be non-negative, got %s", val)
+		}
+		values.IdleOutputTimeout = val
+		values.IdleOutputTimeoutSet = true
+	}
+	if key, err := section.GetKey("resume_session"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid resume_session: %w", boolErr)
+		}
+		values.ResumeSession = val
+		values.ResumeSessionSet = true
+	}
+	if key, err := section.GetKey("auto_answer_questions"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid auto_answer_questions: %w", boolErr)
+		}
+		values.AutoAnswer = val
+		values.AutoAnswerSet = true
+	}
+
+	// budget limits
+	if key, err := section.GetKey("max_cost_usd"); err == nil {
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return Values{}, fmt.Errorf("invalid max_cost_usd: %w", floatErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid max_cost_usd: must be non-negative, got %v", val)
+		}
+		values.MaxCostUSD = val
+	}
+	if key, err := section.GetKey("max_tokens"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid max_tokens: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid max_tokens: must be non-negative, got %d", val)
+		}
+		values.MaxTokens = val
+	}
+
+	// lifecycle hooks
+	if key, err := section.GetKey("hook_pre_task"); err == nil {
+		values.HookPreTask = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("hook_post_task"); err == nil {
+		values.HookPostTask = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("hook_pre_phase"); err == nil {
+		values.HookPrePhase = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("hook_post_run"); err == nil {
+		values.HookPostRun = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("hook_on_failure"); err == nil {
+		values.HookOnFailure = strings.TrimSpace(key.String())
+	}
+
+	// notifications
+	if key, err := section.GetKey("notify_webhook"); err == nil {
+		values.NotifyWebhook = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("notify_webhook_events"); err == nil {
+		values.NotifyWebhookEvents = splitList(key.String())
+	}
+	if key, err := section.GetKey("notify_command"); err == nil {
+		values.NotifyCommand = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("notify_command_events"); err == nil {
+		values.NotifyCommandEvents = splitList(key.String())
+	}
+	if key, err := section.GetKey("notify_bell"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid notify_bell: %w", boolErr)
+		}
+		values.NotifyBell = val
+		values.NotifyBellSet = true
+	}
+	if key, err := section.GetKey("notify_bell_events"); err == nil {
+		values.NotifyBellEvents = splitList(key.String())
+	}
 
 	// paths
 	if key, err := section.GetKey("plans_dir"); err == nil {
 		values.PlansDir = key.String()
 	}
 
+	// plan format
+	if key, err := section.GetKey("task_header_pattern"); err == nil {
+		values.TaskHeaderPattern = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("checkbox_pattern"); err == nil {
+		values.CheckboxPattern = strings.TrimSpace(key.String())
+	}
+
+	// git
+	if key, err := section.GetKey("default_branch"); err == nil {
+		values.DefaultBranch = strings.TrimSpace(key.String())
+	}
+
+	// custom agents allowed in review prompts
+	if key, err := section.GetKey("agents"); err == nil {
+		values.Agents = splitList(key.String())
+	}
+
 	// watch directories (comma-separated)
 	if key, err := section.GetKey("watch_dirs"); err == nil {
-		val := strings.TrimSpace(key.String())
-		if val != "" {
-			for p := range strings.SplitSeq(val, ",") {
-				if t := strings.TrimSpace(p); t != "" {
-					values.WatchDirs = append(values.WatchDirs, t)
-				}
+		values.WatchDirs = splitList(key.String())
+	}
+
+	// execution pipeline (validated by the processor)
+	if key, err := section.GetKey("pipeline"); err == nil {
+		values.Pipeline = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("confirm_phases"); err == nil {
+		values.ConfirmPhases = splitList(key.String())
+	}
+
+	// executors assigned to roles, validated after merge
+	if key, err := section.GetKey("task_executor"); err == nil {
+		values.TaskExecutor = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("review_executor"); err == nil {
+		values.ReviewExecutor = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("external_review_executor"); err == nil {
+		for _, name := range splitList(key.String()) {
+			if !slices.Contains(values.ExternalReviewExecutors, name) {
+				values.ExternalReviewExecutors = append(values.ExternalReviewExecutors, name)
 			}
 		}
 	}
+	if values.Executors, err = parseExecutorSections(cfg); err != nil {
+		return Values{}, err
+	}
 
 	return values, nil
 }
@@ -234,10 +526,133 @@ func (dst *Values) mergeFrom(src *Values) {
 		dst.TaskRetryCount = src.TaskRetryCount
 		dst.TaskRetryCountSet = true
 	}
+	if src.MaxIterations > 0 {
+		dst.MaxIterations = src.MaxIterations
+	}
+	if src.ParallelTasks > 0 {
+		dst.ParallelTasks = src.ParallelTasks
+	}
+	if src.ValidationTimeoutSet {
+		dst.ValidationTimeoutMs = src.ValidationTimeoutMs
+		dst.ValidationTimeoutSet = true
+	}
+	if src.ValidationRetriesSet {
+		dst.ValidationRetries = src.ValidationRetries
+		dst.ValidationRetriesSet = true
+	}
+	if src.IterationTimeoutSet {
+		dst.IterationTimeout = src.IterationTimeout
+		dst.IterationTimeoutSet = true
+	}
+	if src.IdleOutputTimeoutSet {
+		dst.IdleOutputTimeout = src.IdleOutputTimeout
+		dst.IdleOutputTimeoutSet = true
+	}
+	if src.ResumeSessionSet {
+		dst.ResumeSession = src.ResumeSession
+		dst.ResumeSessionSet = true
+	}
+	if src.AutoAnswerSet {
+		dst.AutoAnswer = src.AutoAnswer
+		dst.AutoAnswerSet = true
+	}
+	if src.MaxCostUSD > 0 {
+		dst.MaxCostUSD = src.MaxCostUSD
+	}
+	if src.MaxTokens > 0 {
+		dst.MaxTokens = src.MaxTokens
+	}
+	if src.HookPreTask != "" {
+		dst.HookPreTask = src.HookPreTask
+	}
+	if src.HookPostTask != "" {
+		dst.HookPostTask = src.HookPostTask
+	}
+	if src.HookPrePhase != "" {
+		dst.HookPrePhase = src.HookPrePhase
+	}
+	if src.HookPostRun != "" {
+		dst.HookPostRun = src.HookPostRun
+	}
+	if src.HookOnFailure != "" {
+		dst.HookOnFailure = src.HookOnFailure
+	}
+	if src.NotifyWebhook != "" {
+		dst.NotifyWebhook = src.NotifyWebhook
+	}
+	if len(src.NotifyWebhookEvents) > 0 {
+		dst.NotifyWebhookEvents = src.NotifyWebhookEvents
+	}
+	if src.NotifyCommand != "" {
+		dst.NotifyCommand = src.NotifyCommand
+	}
+	if len(src.NotifyCommandEvents) > 0 {
+		dst.NotifyCommandEvents = src.NotifyCommandEvents
+	}
+	if src.NotifyBellSet {
+		dst.NotifyBell = src.NotifyBell
+		dst.NotifyBellSet = true
+	}
+	if len(src.NotifyBellEvents) > 0 {
+		dst.NotifyBellEvents = src.NotifyBellEvents
+	}
 	if src.PlansDir != "" {
 		dst.PlansDir = src.PlansDir
 	}
+	if src.TaskHeaderPattern != "" {
+		dst.TaskHeaderPattern = src.TaskHeaderPattern
+	}
+	if src.CheckboxPattern != "" {
+		dst.CheckboxPattern = src.CheckboxPattern
+	}
+	if src.DefaultBranch != "" {
+		dst.DefaultBranch = src.DefaultBranch
+	}
+	if src.Branch != "" {
+		dst.Branch = src.Branch
+	}
+	if len(src.Agents) > 0 {
+		dst.Agents = src.Agents
+	}
 	if len(src.WatchDirs) > 0 {
 		dst.WatchDirs = src.WatchDirs
 	}
+	if src.Pipeline != "" {
+		dst.Pipeline = src.Pipeline
+	}
+	if len(src.ConfirmPhases) > 0 {
+		dst.ConfirmPhases = src.ConfirmPhases
+	}
+	if src.TaskExecutor != "" {
+		dst.TaskExecutor = src.TaskExecutor
+	}
+	if src.ReviewExecutor != "" {
+		dst.ReviewExecutor = src.ReviewExecutor
+	}
+	if len(src.ExternalReviewExecutors) > 0 {
+		dst.ExternalReviewExecutors = src.ExternalReviewExecutors
+	}
+	// executor sections are merged by name, a section in local config replaces the global one
+	for name, ec := range src.Executors {
+		if dst.Executors == nil {
+			dst.Executors = make(map[string]ExecutorConfig)
+		}
+		dst.Executors[name] = ec
+	}
+}
+
+// splitList splits a comma-separated value, skipping empty items. the value may be wrapped in brackets,
+// like a yaml flow sequence in plan frontmatter, e.g. `["a", "b"]`.
+func splitList(val string) []string {
+	val = strings.TrimSpace(val)
+	if strings.HasPrefix(val, "[") && strings.HasSuffix(val, "]") {
+		val = val[1 : len(val)-1]
+	}
+	var res []string
+	for p := range strings.SplitSeq(val, ",") {
+		if t := strings.Trim(strings.TrimSpace(p), `"'`); t != "" {
+			res = append(res, t)
+		}
+	}
+	return res
 }
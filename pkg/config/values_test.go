@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,7 +33,16 @@ func TestValuesLoader_Load_EmbeddedOnly(t *testing.T) {
 	assert.Equal(t, 2000, values.IterationDelayMs)
 	assert.Equal(t, 1, values.TaskRetryCount)
 	assert.True(t, values.TaskRetryCountSet)
+	assert.Equal(t, 600000, values.ValidationTimeoutMs)
+	assert.True(t, values.ValidationTimeoutSet)
+	assert.Equal(t, 3, values.ValidationRetries)
+	assert.True(t, values.ValidationRetriesSet)
+	assert.Equal(t, 30*time.Minute, values.IdleOutputTimeout)
+	assert.True(t, values.IdleOutputTimeoutSet)
+	assert.Zero(t, values.IterationTimeout)
+	assert.False(t, values.IterationTimeoutSet)
 	assert.Equal(t, "docs/plans", values.PlansDir)
+	assert.Empty(t, values.Pipeline, "default pipeline is built into the processor")
 }
 
 func TestValuesLoader_Load_GlobalOnly(t *testing.T) {
@@ -128,6 +138,18 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 		{name: "negative task_retry_count", config: "task_retry_count = -1", errPart: "task_retry_count"},
 		{name: "negative codex_timeout_ms", config: "codex_timeout_ms = -100", errPart: "codex_timeout_ms"},
 		{name: "negative iteration_delay_ms", config: "iteration_delay_ms = -50", errPart: "iteration_delay_ms"},
+		{name: "invalid validation_timeout_ms", config: "validation_timeout_ms = soon", errPart: "validation_timeout_ms"},
+		{name: "negative validation_timeout_ms", config: "validation_timeout_ms = -1", errPart: "validation_timeout_ms"},
+		{name: "negative validation_retries", config: "validation_retries = -1", errPart: "validation_retries"},
+		{name: "invalid max_cost_usd", config: "max_cost_usd = lots", errPart: "max_cost_usd"},
+		{name: "negative max_cost_usd", config: "max_cost_usd = -1.5", errPart: "max_cost_usd"},
+		{name: "negative max_tokens", config: "max_tokens = -1", errPart: "max_tokens"},
+		{name: "invalid iteration_timeout", config: "iteration_timeout = 45", errPart: "iteration_timeout"},
+		{name: "negative iteration_timeout", config: "iteration_timeout = -1m", errPart: "iteration_timeout"},
+		{name: "invalid idle_output_timeout", config: "idle_output_timeout = soon", errPart: "idle_output_timeout"},
+		{name: "invalid notify_bell", config: "notify_bell = maybe", errPart: "notify_bell"},
+		{name: "invalid resume_session", config: "resume_session = sometimes", errPart: "resume_session"},
+		{name: "invalid auto_answer_questions", config: "auto_answer_questions = maybe", errPart: "auto_answer_questions"},
 	}
 
 	for _, tc := range tests {
@@ -218,6 +240,324 @@ func TestValuesLoader_Load_ExplicitZeroIterationDelayMs(t *testing.T) {
 	assert.True(t, values.IterationDelayMsSet)
 }
 
+func TestValuesLoader_Load_ExplicitZeroValidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("validation_timeout_ms = 1000\nvalidation_retries = 5"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("validation_timeout_ms = 0\nvalidation_retries = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	// explicit zero in local config overrides global and embedded defaults
+	assert.Equal(t, 0, values.ValidationTimeoutMs)
+	assert.True(t, values.ValidationTimeoutSet)
+	assert.Equal(t, 0, values.ValidationRetries)
+	assert.True(t, values.ValidationRetriesSet)
+}
+
+func TestValuesLoader_Load_Timeouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("iteration_timeout = 2h\nidle_output_timeout = 45m"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("idle_output_timeout = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2*time.Hour, values.IterationTimeout)
+	assert.True(t, values.IterationTimeoutSet)
+	assert.Zero(t, values.IdleOutputTimeout, "explicit zero in local config disables idle timeout")
+	assert.True(t, values.IdleOutputTimeoutSet)
+}
+
+func TestValuesLoader_Load_BudgetLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("max_cost_usd = 10\nmax_tokens = 5000000"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("max_cost_usd = 2.5"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 2.5, values.MaxCostUSD, 1e-9)
+	assert.Equal(t, 5000000, values.MaxTokens)
+
+	// embedded defaults have no limits
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Zero(t, values.MaxCostUSD)
+	assert.Zero(t, values.MaxTokens)
+}
+
+func TestValuesLoader_Load_Hooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("hook_pre_task = docker compose up -d db\n"+
+		"hook_post_task = make mocks\nhook_post_run = notify-send \"$RALPHEX_RESULT\"\nhook_on_failure = ./alert.sh"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("hook_pre_phase = echo $RALPHEX_PHASE\nhook_post_task = go generate ./..."), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "docker compose up -d db", values.HookPreTask)
+	assert.Equal(t, "go generate ./...", values.HookPostTask, "local overrides global")
+	assert.Equal(t, "echo $RALPHEX_PHASE", values.HookPrePhase)
+	assert.Equal(t, `notify-send "$RALPHEX_RESULT"`, values.HookPostRun)
+	assert.Equal(t, "./alert.sh", values.HookOnFailure)
+
+	// embedded defaults have no hooks
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.HookPreTask)
+	assert.Empty(t, values.HookPostRun)
+}
+
+func TestValuesLoader_Load_Notifications(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("notify_webhook = https://hooks.example.com/x\n"+
+		"notify_webhook_events = failed, max_iterations\nnotify_command = ./notify.sh\nnotify_bell = true"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("notify_bell = false\nnotify_bell_events = question"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://hooks.example.com/x", values.NotifyWebhook)
+	assert.Equal(t, []string{"failed", "max_iterations"}, values.NotifyWebhookEvents)
+	assert.Equal(t, "./notify.sh", values.NotifyCommand)
+	assert.Empty(t, values.NotifyCommandEvents)
+	assert.False(t, values.NotifyBell, "explicit false in local config disables the bell")
+	assert.True(t, values.NotifyBellSet)
+	assert.Equal(t, []string{"question"}, values.NotifyBellEvents)
+
+	// embedded defaults have no notifications
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.NotifyWebhook)
+	assert.Empty(t, values.NotifyCommand)
+	assert.False(t, values.NotifyBell)
+}
+
+func TestValuesLoader_Load_ResumeSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("resume_session = true"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.ResumeSession)
+	assert.True(t, values.ResumeSessionSet)
+
+	localConfig := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(localConfig, []byte("resume_session = false"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.ResumeSession, "explicit false in local config disables resuming")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.ResumeSession)
+	assert.False(t, values.ResumeSessionSet)
+}
+
+func TestValuesLoader_Load_AutoAnswer(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("auto_answer_questions = true"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.True(t, values.AutoAnswer)
+	assert.True(t, values.AutoAnswerSet)
+
+	localConfig := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(localConfig, []byte("auto_answer_questions = false"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.False(t, values.AutoAnswer, "explicit false in local config asks again")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.False(t, values.AutoAnswer)
+	assert.False(t, values.AutoAnswerSet)
+}
+
+func TestValuesLoader_Load_DefaultBranch(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("default_branch = develop"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "develop", values.DefaultBranch)
+
+	localConfig := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(localConfig, []byte("default_branch = release/2.0 "), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "release/2.0", values.DefaultBranch, "local config wins")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.DefaultBranch, "detected by default")
+}
+
+func TestValuesLoader_Load_PlanFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	require.NoError(t, os.WriteFile(globalConfig, []byte(
+		"task_header_pattern = ^##\\s+Step\\s+(?P<num>\\d+)\\s+-\\s+(?P<title>.*)$\n"+
+			"checkbox_pattern = ^\\s*\\*\\s+\\((?P<state>[ x])\\) (?P<text>.*)$\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, `^##\s+Step\s+(?P<num>\d+)\s+-\s+(?P<title>.*)$`, values.TaskHeaderPattern)
+	assert.Equal(t, `^\s*\*\s+\((?P<state>[ x])\) (?P<text>.*)$`, values.CheckboxPattern)
+
+	localConfig := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(localConfig, []byte("task_header_pattern = ^### Phase (?P<num>\\d+)$"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, `^### Phase (?P<num>\d+)$`, values.TaskHeaderPattern, "local config wins")
+	assert.Equal(t, `^\s*\*\s+\((?P<state>[ x])\) (?P<text>.*)$`, values.CheckboxPattern, "global kept")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.TaskHeaderPattern, "default grammar")
+	assert.Empty(t, values.CheckboxPattern, "default grammar")
+}
+
+func TestValuesLoader_Load_MaxIterationsAndAgents(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("max_iterations = 30\nagents = quality, testing\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 30, values.MaxIterations)
+	assert.Equal(t, []string{"quality", "testing"}, values.Agents)
+
+	localConfig := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(localConfig, []byte("max_iterations = 80"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, 80, values.MaxIterations, "local config wins")
+	assert.Equal(t, []string{"quality", "testing"}, values.Agents, "global kept")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Zero(t, values.MaxIterations, "cli default by default")
+	assert.Empty(t, values.Agents, "all agents by default")
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("max_iterations = -1"), 0o600))
+	_, err = loader.Load(localConfig, globalConfig)
+	require.ErrorContains(t, err, "invalid max_iterations: must be non-negative")
+}
+
+func TestValuesLoader_LoadPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	loader := newValuesLoader(defaultsFS)
+	base := Values{MaxIterations: 30, CodexEnabled: true, CodexEnabledSet: true, ClaudeArgs: "--verbose", DefaultBranch: "main",
+		ValidationTimeoutMs: 600000, ValidationTimeoutSet: true}
+
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte(`---
+# per-plan settings
+max_iterations: 10
+codex_enabled: false
+claude_args: "--model opus --verbose"
+validation_timeout_ms: 0
+base_branch: develop
+branch: feature/cache
+agents: [quality, "testing"]
+---
+# Plan
+### Task 1: A
+- [ ] a
+`), 0o600))
+
+	values, err := loader.LoadPlan(base, planFile)
+	require.NoError(t, err)
+	assert.Equal(t, 10, values.MaxIterations)
+	assert.False(t, values.CodexEnabled)
+	assert.Equal(t, "--model opus --verbose", values.ClaudeArgs)
+	assert.Zero(t, values.ValidationTimeoutMs)
+	assert.Equal(t, "develop", values.DefaultBranch)
+	assert.Equal(t, "feature/cache", values.Branch)
+	assert.Equal(t, []string{"quality", "testing"}, values.Agents)
+
+	noFrontmatter := filepath.Join(tmpDir, "plain.md")
+	require.NoError(t, os.WriteFile(noFrontmatter, []byte("# Plan\n### Task 1: A\n- [ ] a\n"), 0o600))
+	values, err = loader.LoadPlan(base, noFrontmatter)
+	require.NoError(t, err)
+	assert.Equal(t, base, values, "plan without frontmatter keeps values")
+
+	invalid := filepath.Join(tmpDir, "invalid.md")
+	require.NoError(t, os.WriteFile(invalid, []byte("---\nmax_iterations: many\n---\n# Plan\n"), 0o600))
+	_, err = loader.LoadPlan(base, invalid)
+	require.ErrorContains(t, err, "parse plan frontmatter: invalid max_iterations")
+
+	// settings running commands are accepted only in config, plans come with the repository
+	for _, fm := range []string{"claude_command: /tmp/evil.sh", "hook_post_task: curl evil.example", "hook_pre_phase = rm -rf .",
+		"task_executor: gemini", "notify_command: sh -c id", "[executor:evil]\ncommand = /tmp/evil.sh"} {
+		rejected := filepath.Join(tmpDir, "rejected.md")
+		require.NoError(t, os.WriteFile(rejected, []byte("---\nmax_iterations: 5\n"+fm+"\n---\n# Plan\n"), 0o600))
+		_, err = loader.LoadPlan(base, rejected)
+		require.ErrorContains(t, err, "is not allowed in plan frontmatter", fm)
+	}
+	command := filepath.Join(tmpDir, "command.md")
+	require.NoError(t, os.WriteFile(command, []byte("---\nclaude_command: /tmp/evil.sh\n---\n"), 0o600))
+	_, err = loader.LoadPlan(base, command)
+	require.EqualError(t, err, "parse plan frontmatter: claude_command is not allowed in plan frontmatter, set it in config "+
+		"(allowed: max_iterations, codex_enabled, claude_args, validation_timeout_ms, default_branch, base_branch, branch, agents, "+
+		"task_header_pattern, checkbox_pattern)")
+
+	_, err = loader.LoadPlan(base, filepath.Join(tmpDir, "missing.md"))
+	require.ErrorContains(t, err, "read plan file")
+}
+
+func TestValuesLoader_Load_ConfirmPhases(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	require.NoError(t, os.WriteFile(globalConfig, []byte("confirm_phases = tasks, custom:security ,"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tasks", "custom:security"}, values.ConfirmPhases)
+
+	localConfig := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(localConfig, []byte("confirm_phases = codex"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"codex"}, values.ConfirmPhases, "local config wins")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.ConfirmPhases, "no gates by default")
+}
+
 func TestValuesLoader_Load_LocalOverridesCodexEnabled(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalConfig := filepath.Join(tmpDir, "global")
@@ -266,6 +606,7 @@ codex_sandbox = none
 iteration_delay_ms = 500
 task_retry_count = 5
 plans_dir = my/plans
+pipeline = tasks, codex, custom:security
 `
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0o600))
 
@@ -286,6 +627,7 @@ plans_dir = my/plans
 	assert.Equal(t, 5, values.TaskRetryCount)
 	assert.True(t, values.TaskRetryCountSet)
 	assert.Equal(t, "my/plans", values.PlansDir)
+	assert.Equal(t, "tasks, codex, custom:security", values.Pipeline)
 }
 
 func TestValues_mergeFrom(t *testing.T) {
@@ -297,12 +639,16 @@ func TestValues_mergeFrom(t *testing.T) {
 		src := Values{
 			ClaudeCommand: "src-claude",
 			ClaudeArgs:    "src-args",
+			Pipeline:      "tasks, codex",
+			ParallelTasks: 3,
 		}
 		dst.mergeFrom(&src)
 
 		assert.Equal(t, "src-claude", dst.ClaudeCommand)
 		assert.Equal(t, "src-args", dst.ClaudeArgs)
 		assert.Equal(t, "dst-plans", dst.PlansDir)
+		assert.Equal(t, "tasks, codex", dst.Pipeline)
+		assert.Equal(t, 3, dst.ParallelTasks)
 	})
 
 	t.Run("empty source doesn't overwrite", func(t *testing.T) {
@@ -393,6 +739,7 @@ codex_timeout_ms = 7200000
 codex_sandbox = none
 iteration_delay_ms = 5000
 task_retry_count = 3
+parallel_tasks = 4
 plans_dir = custom/plans
 `)
 		values, err := vl.parseValuesFromBytes(data)
@@ -410,9 +757,20 @@ plans_dir = custom/plans
 		assert.Equal(t, 5000, values.IterationDelayMs)
 		assert.Equal(t, 3, values.TaskRetryCount)
 		assert.True(t, values.TaskRetryCountSet)
+		assert.Equal(t, 4, values.ParallelTasks)
 		assert.Equal(t, "custom/plans", values.PlansDir)
 	})
 
+	t.Run("invalid parallel_tasks", func(t *testing.T) {
+		_, err := vl.parseValuesFromBytes([]byte("parallel_tasks = -1"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid parallel_tasks: must be non-negative")
+
+		_, err = vl.parseValuesFromBytes([]byte("parallel_tasks = many"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid parallel_tasks")
+	})
+
 	t.Run("empty config", func(t *testing.T) {
 		data := []byte("")
 		values, err := vl.parseValuesFromBytes(data)
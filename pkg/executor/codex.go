@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -35,6 +37,7 @@ func (r *execCodexRunner) Run(ctx context.Context, name string, args ...string)
 	// use exec.Command (not CommandContext) because we handle cancellation ourselves
 	// to ensure the entire process group is killed, not just the direct child
 	cmd := exec.Command(name, args...) //nolint:noctx // intentional: we handle context cancellation via process group kill
+	cmd.Dir = WorkDir(ctx)
 
 	// create new process group so we can kill all descendants on cleanup
 	setupProcessGroup(cmd)
@@ -76,8 +79,14 @@ type CodexExecutor struct {
 type codexFilterState struct {
 	headerCount int             // tracks "--------" separators seen (show content between first two)
 	seen        map[string]bool // track all shown lines for deduplication
+	tokensNext  bool            // previous line was "tokens used" without a count
+	tokens      int             // total tokens reported by codex
 }
 
+// codexTokensPattern matches the token usage line codex prints to stderr,
+// either "tokens used: 1234" or "tokens used" with the count on the next line.
+var codexTokensPattern = regexp.MustCompile(`(?i)^(?:\[[^\]]*\]\s*)?tokens used:?\s*([\d,]*)$`)
+
 // Run executes codex CLI with the given prompt and returns filtered output.
 // stderr is streamed line-by-line to OutputHandler for progress indication.
 // stdout is captured entirely as the final response (returned in Result.Output).
@@ -133,8 +142,11 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 
 	// process stderr for progress display (header block + bold summaries)
 	stderrDone := make(chan error, 1)
+	var tokens int
 	go func() {
-		stderrDone <- e.processStderr(ctx, streams.Stderr)
+		var err error
+		tokens, err = e.processStderr(ctx, streams.Stderr)
+		stderrDone <- err
 	}()
 
 	// read stdout entirely as final response
@@ -165,12 +177,13 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	signal := detectSignal(stdoutContent)
 
 	// return stdout content as the result (the actual answer from codex)
-	return Result{Output: stdoutContent, Signal: signal, Error: finalErr}
+	return Result{Output: stdoutContent, Signal: signal, Error: finalErr, Usage: Usage{OtherTokens: tokens}}
 }
 
 // processStderr reads stderr line-by-line, filters for progress display.
 // shows header block (between first two "--------" separators) and bold summaries.
-func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) error {
+// returns the total tokens reported by codex, 0 if not reported.
+func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) (int, error) {
 	state := &codexFilterState{}
 	scanner := bufio.NewScanner(r)
 	// increase buffer size for large output lines
@@ -180,11 +193,12 @@ func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) error {
 	for scanner.Scan() {
 		select {
 		case <-ctx.Done():
-			return fmt.Errorf("context done: %w", ctx.Err())
+			return state.tokens, fmt.Errorf("context done: %w", ctx.Err())
 		default:
 		}
 
 		line := scanner.Text()
+		state.trackTokens(line)
 		if show, filtered := e.shouldDisplay(line, state); show {
 			if e.OutputHandler != nil {
 				e.OutputHandler(filtered + "\n")
@@ -193,9 +207,33 @@ func (e *CodexExecutor) processStderr(ctx context.Context, r io.Reader) error {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("read stderr: %w", err)
+		return state.tokens, fmt.Errorf("read stderr: %w", err)
+	}
+	return state.tokens, nil
+}
+
+// trackTokens picks up the token count from the codex usage line.
+func (s *codexFilterState) trackTokens(line string) {
+	trimmed := strings.TrimSpace(line)
+	count := ""
+	switch {
+	case s.tokensNext:
+		s.tokensNext = false
+		count = trimmed
+	default:
+		m := codexTokensPattern.FindStringSubmatch(trimmed)
+		if m == nil {
+			return
+		}
+		if m[1] == "" {
+			s.tokensNext = true
+			return
+		}
+		count = m[1]
+	}
+	if n, err := strconv.Atoi(strings.ReplaceAll(count, ",", "")); err == nil {
+		s.tokens = n
 	}
-	return nil
 }
 
 // readStdout reads the entire stdout content as the final response.
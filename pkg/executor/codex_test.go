@@ -59,6 +59,34 @@ func TestCodexExecutor_Run_Success(t *testing.T) {
 	assert.Equal(t, "<<<RALPHEX:CODEX_REVIEW_DONE>>>", result.Signal)
 }
 
+func TestCodexExecutor_Run_Usage(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   int
+	}{
+		{name: "count on next line", stderr: "--------\nmodel: gpt-5\n--------\n**Done**\ntokens used\n12,345\n", want: 12345},
+		{name: "count on same line", stderr: "[2025-01-01T10:00:00] tokens used: 4567\n", want: 4567},
+		{name: "not reported", stderr: "**Done**\n", want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockCodexRunner{
+				runFunc: func(_ context.Context, _ string, _ ...string) (CodexStreams, func() error, error) {
+					return mockStreams(tc.stderr, "no issues"), mockWait(), nil
+				},
+			}
+			e := &CodexExecutor{runner: mock}
+
+			result := e.Run(context.Background(), "analyze code")
+			require.NoError(t, result.Error)
+			assert.Equal(t, tc.want, result.Usage.Tokens())
+			assert.Equal(t, tc.want, result.Usage.OtherTokens)
+		})
+	}
+}
+
 func TestCodexExecutor_Run_StreamsStderr(t *testing.T) {
 	// stderr contains header block and bold summaries for progress display
 	stderr := `--------
@@ -422,7 +450,7 @@ func TestCodexExecutor_processStderr_contextCancellation(t *testing.T) {
 	}()
 
 	e := &CodexExecutor{}
-	err := e.processStderr(ctx, pr)
+	_, err := e.processStderr(ctx, pr)
 
 	// should return context.Canceled or nil (depending on timing)
 	if err != nil {
@@ -492,7 +520,7 @@ func TestCodexExecutor_processStderr_readError(t *testing.T) {
 	e := &CodexExecutor{}
 	errReader := &failingReader{err: errors.New("read failed")}
 
-	err := e.processStderr(context.Background(), errReader)
+	_, err := e.processStderr(context.Background(), errReader)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "read stderr")
@@ -635,7 +663,7 @@ func TestCodexExecutor_processStderr_largeLines(t *testing.T) {
 				},
 			}
 
-			err := e.processStderr(context.Background(), strings.NewReader(stderr))
+			_, err := e.processStderr(context.Background(), strings.NewReader(stderr))
 
 			require.NoError(t, err, "should handle %d byte line without error", tc.size)
 			assert.Contains(t, shown, largeContent, "large content should be captured")
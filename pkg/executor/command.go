@@ -0,0 +1,282 @@
+package executor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// prompt placeholders of CommandExecutor arguments
+const (
+	promptPlaceholder     = "{{PROMPT}}"
+	promptFilePlaceholder = "{{PROMPT_FILE}}"
+)
+
+// signals reported for matches of custom signal patterns
+const (
+	signalFailed   = "<<<RALPHEX:TASK_FAILED>>>"
+	signalQuestion = "<<<RALPHEX:QUESTION>>>"
+	signalEnd      = "<<<RALPHEX:END>>>"
+)
+
+// completionSignals are signals prompts ask for when the task, review or plan is done.
+var completionSignals = []string{
+	"<<<RALPHEX:ALL_TASKS_DONE>>>",
+	"<<<RALPHEX:REVIEW_DONE>>>",
+	"<<<RALPHEX:CODEX_REVIEW_DONE>>>",
+	"<<<RALPHEX:PLAN_READY>>>",
+}
+
+// SignalPatterns detect signals in the output of agents that don't print <<<RALPHEX:...>>> signals verbatim.
+// default signals are detected with or without them.
+type SignalPatterns struct {
+	Completed *regexp.Regexp // done, reported as the completion signal the prompt asks for
+	Failed    *regexp.Regexp // failure, reported as <<<RALPHEX:TASK_FAILED>>>
+	Question  *regexp.Regexp // question, the first group captures the JSON payload {"question": ..., "options": [...]}
+}
+
+// CommandExecutor runs a coding agent CLI described by configuration, e.g. gemini-cli, opencode or aider.
+// The prompt is passed as an argument ({{PROMPT}} in Args), as a path to a temporary file
+// ({{PROMPT_FILE}} in Args) or on stdin if Args has no prompt placeholder.
+// Output is plain text or JSONL events with the text at TextPath. Signals are detected in the text,
+// with Signals patterns if set.
+type CommandExecutor struct {
+	Name          string            // executor name used in errors, defaults to Command
+	Command       string            // command to execute
+	Args          string            // arguments (space-separated, quotes supported) with optional prompt placeholder
+	JSONL         bool              // output is one JSON event per line
+	TextPath      string            // dot-separated path of the text field in JSONL events, e.g. "part.text"
+	TextFilter    map[string]string // dot-separated paths and values JSONL events must have to be used as text
+	Signals       SignalPatterns    // custom signal patterns, default signals are detected without them
+	Timeout       time.Duration     // max duration of a single run, 0 is unlimited
+	OutputHandler func(text string) // called for each text chunk, can be nil
+}
+
+// Run executes the command with the given prompt and collects its text output.
+// a non-zero exit is an error only if the command produced no text.
+func (e *CommandExecutor) Run(ctx context.Context, prompt string) Result {
+	name := e.Name
+	if name == "" {
+		name = e.Command
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{Error: fmt.Errorf("context already canceled: %w", err)}
+	}
+
+	args, stdin, cleanup, err := e.buildArgs(prompt)
+	if err != nil {
+		return Result{Error: fmt.Errorf("%s: %w", name, err)}
+	}
+	defer cleanup()
+
+	runCtx := ctx
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, e.Timeout, ErrIterationTimeout)
+		defer cancel()
+	}
+
+	// use exec.Command (not CommandContext) because we handle cancellation ourselves
+	// to ensure the entire process group is killed, not just the direct child
+	cmd := exec.Command(e.Command, args...) //nolint:noctx,gosec // intentional: process group kill, command from user config
+	cmd.Dir = WorkDir(ctx)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	setupProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return Result{Error: fmt.Errorf("%s: create stdout pipe: %w", name, err)}
+	}
+	cmd.Stderr = cmd.Stdout
+	if startErr := cmd.Start(); startErr != nil {
+		return Result{Error: fmt.Errorf("start %s: %w", name, startErr)}
+	}
+	cleanupGroup := newProcessGroupCleanup(cmd, runCtx.Done())
+
+	result := e.parseOutput(stdout)
+	result.Output, result.Signal = e.detectSignals(result.Output, prompt)
+	waitErr := cleanupGroup.Wait()
+
+	if ctx.Err() == nil && runCtx.Err() != nil {
+		result.Error = fmt.Errorf("%s killed: %w after %s", name, context.Cause(runCtx), e.Timeout)
+		return result
+	}
+	if result.Error != nil || waitErr == nil {
+		return result
+	}
+	if ctx.Err() != nil {
+		result.Error = fmt.Errorf("%s: %w", name, ctx.Err())
+		return result
+	}
+	// limit notices come with a non-zero exit, the typed error is kept for the caller to wait and retry
+	if rl := detectRateLimit(result.Output, time.Now()); rl != nil && result.Signal == "" {
+		result.Error = rl
+		return result
+	}
+	// non-zero exit might still have useful output
+	if strings.TrimSpace(result.Output) == "" {
+		result.Error = fmt.Errorf("%s exited with error: %w", name, waitErr)
+	}
+	return result
+}
+
+// buildArgs splits Args and puts the prompt in place of its placeholder.
+// returns the prompt as stdin content if Args has no placeholder, and a cleanup function removing the prompt file.
+func (e *CommandExecutor) buildArgs(prompt string) (args []string, stdin string, cleanup func(), err error) {
+	args = splitArgs(e.Args)
+	cleanup = func() {}
+
+	switch {
+	case strings.Contains(e.Args, promptPlaceholder):
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(arg, promptPlaceholder, prompt)
+		}
+		return args, "", cleanup, nil
+
+	case strings.Contains(e.Args, promptFilePlaceholder):
+		f, createErr := os.CreateTemp("", "ralphex-prompt-*.txt")
+		if createErr != nil {
+			return nil, "", nil, fmt.Errorf("create prompt file: %w", createErr)
+		}
+		cleanup = func() { _ = os.Remove(f.Name()) }
+		_, writeErr := f.WriteString(prompt)
+		if closeErr := f.Close(); writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr != nil {
+			cleanup()
+			return nil, "", nil, fmt.Errorf("write prompt file: %w", writeErr)
+		}
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(arg, promptFilePlaceholder, f.Name())
+		}
+		return args, "", cleanup, nil
+
+	default:
+		return args, prompt, cleanup, nil
+	}
+}
+
+// parseOutput reads command output, passing text to OutputHandler.
+// lines that are not JSON events are kept as-is in JSONL mode, they usually are errors printed by the command.
+func (e *CommandExecutor) parseOutput(r io.Reader) Result {
+	var output strings.Builder
+	emit := func(text string) {
+		output.WriteString(text)
+		if e.OutputHandler != nil {
+			e.OutputHandler(text)
+		}
+	}
+
+	path := strings.Split(e.TextPath, ".")
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, maxScannerBuffer)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !e.JSONL {
+			emit(line + "\n")
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var event any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			emit(line + "\n")
+			continue
+		}
+		if !e.matchFilter(event) {
+			continue
+		}
+		for _, v := range jsonValues(event, path) {
+			if text, ok := v.(string); ok && text != "" {
+				emit(text)
+			}
+		}
+	}
+
+	res := Result{Output: output.String()}
+	if err := scanner.Err(); err != nil {
+		res.Error = fmt.Errorf("read output: %w", err)
+	}
+	return res
+}
+
+// detectSignals returns the signal of the output. a custom question is appended to the output
+// as the <<<RALPHEX:QUESTION>>> signal, so it is parsed like a question of claude.
+func (e *CommandExecutor) detectSignals(output, prompt string) (string, string) {
+	if re := e.Signals.Question; re != nil && !strings.Contains(output, signalQuestion) {
+		if m := re.FindStringSubmatch(output); len(m) > 1 {
+			output = strings.TrimRight(output, "\n") + "\n" + signalQuestion + "\n" + strings.TrimSpace(m[1]) + "\n" + signalEnd + "\n"
+		}
+	}
+	if re := e.Signals.Failed; re != nil && re.MatchString(output) {
+		return output, signalFailed
+	}
+	if sig := detectSignal(output); sig != "" {
+		return output, sig
+	}
+	if re := e.Signals.Completed; re != nil && re.MatchString(output) {
+		return output, promptCompletionSignal(prompt)
+	}
+	return output, ""
+}
+
+// promptCompletionSignal returns the completion signal mentioned first in the prompt,
+// <<<RALPHEX:ALL_TASKS_DONE>>> if the prompt mentions none.
+func promptCompletionSignal(prompt string) string {
+	res, pos := completionSignals[0], -1
+	for _, sig := range completionSignals {
+		if i := strings.Index(prompt, sig); i >= 0 && (pos < 0 || i < pos) {
+			res, pos = sig, i
+		}
+	}
+	return res
+}
+
+// matchFilter returns true if the event has all TextFilter field values.
+func (e *CommandExecutor) matchFilter(event any) bool {
+	for field, want := range e.TextFilter {
+		values := jsonValues(event, strings.Split(field, "."))
+		if len(values) == 0 || fmt.Sprint(values[0]) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonValues returns values found at the path in a decoded JSON value.
+// arrays on the way are walked element by element, so "message.content.text" collects
+// the text of every content block.
+func jsonValues(v any, path []string) []any {
+	if arr, ok := v.([]any); ok {
+		var res []any
+		for _, item := range arr {
+			res = append(res, jsonValues(item, path)...)
+		}
+		return res
+	}
+	if len(path) == 0 {
+		return []any{v}
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+	next, ok := obj[path[0]]
+	if !ok {
+		return nil
+	}
+	return jsonValues(next, path[1:])
+}
@@ -0,0 +1,170 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandExecutor_Run(t *testing.T) {
+	t.Run("prompt on stdin", func(t *testing.T) {
+		var streamed strings.Builder
+		e := &CommandExecutor{Command: "sh", Args: `-c "cat; echo; echo '<<<RALPHEX:REVIEW_DONE>>>'"`,
+			OutputHandler: func(text string) { streamed.WriteString(text) }}
+		result := e.Run(context.Background(), "review the code")
+		require.NoError(t, result.Error)
+		assert.Equal(t, "review the code\n<<<RALPHEX:REVIEW_DONE>>>\n", result.Output)
+		assert.Equal(t, "<<<RALPHEX:REVIEW_DONE>>>", result.Signal)
+		assert.Equal(t, result.Output, streamed.String())
+	})
+
+	t.Run("prompt argument", func(t *testing.T) {
+		e := &CommandExecutor{Command: "sh", Args: `-c 'echo "got: $1"' sh {{PROMPT}}`}
+		result := e.Run(context.Background(), "fix the bug")
+		require.NoError(t, result.Error)
+		assert.Equal(t, "got: fix the bug\n", result.Output)
+	})
+
+	t.Run("prompt file", func(t *testing.T) {
+		e := &CommandExecutor{Command: "sh", Args: `-c 'cat "$1"; echo; echo "$1"' sh {{PROMPT_FILE}}`}
+		result := e.Run(context.Background(), "implement task 1")
+		require.NoError(t, result.Error)
+		lines := strings.Split(strings.TrimSpace(result.Output), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, "implement task 1", lines[0])
+		assert.NoFileExists(t, lines[1], "prompt file is removed")
+	})
+
+	t.Run("runs in work dir", func(t *testing.T) {
+		dir := t.TempDir()
+		e := &CommandExecutor{Command: "pwd"}
+		result := e.Run(WithWorkDir(context.Background(), dir), "")
+		require.NoError(t, result.Error)
+		resolved, err := filepath.EvalSymlinks(dir)
+		require.NoError(t, err)
+		assert.Contains(t, []string{dir, resolved}, strings.TrimSpace(result.Output))
+	})
+
+	t.Run("non-zero exit with output is not an error", func(t *testing.T) {
+		e := &CommandExecutor{Command: "sh", Args: `-c "echo partial work; exit 1"`}
+		result := e.Run(context.Background(), "")
+		require.NoError(t, result.Error)
+		assert.Equal(t, "partial work\n", result.Output)
+	})
+
+	t.Run("non-zero exit without output", func(t *testing.T) {
+		e := &CommandExecutor{Name: "gemini", Command: "sh", Args: `-c "exit 2"`}
+		result := e.Run(context.Background(), "")
+		require.Error(t, result.Error)
+		assert.Contains(t, result.Error.Error(), "gemini exited with error: command wait: exit status 2")
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		e := &CommandExecutor{Command: "sh", Args: `-c "echo 'API Error: 429 rate limit exceeded'; exit 1"`}
+		result := e.Run(context.Background(), "")
+		var rl *RateLimitError
+		require.ErrorAs(t, result.Error, &rl)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		e := &CommandExecutor{Command: "sleep", Args: "5", Timeout: 100 * time.Millisecond}
+		start := time.Now()
+		result := e.Run(context.Background(), "")
+		require.ErrorIs(t, result.Error, ErrIterationTimeout)
+		assert.Less(t, time.Since(start), 4*time.Second)
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		e := &CommandExecutor{Name: "agent", Command: "ralphex-missing-agent"}
+		result := e.Run(context.Background(), "")
+		require.Error(t, result.Error)
+		assert.Contains(t, result.Error.Error(), "start agent")
+	})
+}
+
+func TestCommandExecutor_Run_JSONL(t *testing.T) {
+	events := `{"type":"init","session_id":"abc"}
+{"type":"message","role":"user","content":"prompt with <<<RALPHEX:ALL_TASKS_DONE>>>"}
+{"type":"message","role":"assistant","content":"working on it\n"}
+warning: slow response
+{"type":"message","role":"assistant","content":"<<<RALPHEX:REVIEW_DONE>>>"}
+`
+	data := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(data, []byte(events), 0o600))
+
+	e := &CommandExecutor{Command: "cat", Args: data, JSONL: true, TextPath: "content",
+		TextFilter: map[string]string{"type": "message", "role": "assistant"}}
+	result := e.Run(context.Background(), "")
+	require.NoError(t, result.Error)
+	assert.Equal(t, "working on it\nwarning: slow response\n<<<RALPHEX:REVIEW_DONE>>>", result.Output)
+	assert.Equal(t, "<<<RALPHEX:REVIEW_DONE>>>", result.Signal, "signals in filtered events are ignored")
+}
+
+func TestCommandExecutor_Run_SignalPatterns(t *testing.T) {
+	signals := SignalPatterns{
+		Completed: regexp.MustCompile(`(?m)^STATUS: DONE$`),
+		Failed:    regexp.MustCompile(`(?m)^STATUS: FAILED$`),
+		Question:  regexp.MustCompile(`(?s)ASK:\s*(\{.*?\})\s*END`),
+	}
+	tests := []struct {
+		name       string
+		output     string
+		prompt     string
+		wantSignal string
+		wantOutput string
+	}{
+		{name: "completed in task prompt", output: "STATUS: DONE", prompt: "output <<<RALPHEX:ALL_TASKS_DONE>>> or <<<RALPHEX:TASK_FAILED>>>",
+			wantSignal: "<<<RALPHEX:ALL_TASKS_DONE>>>"},
+		{name: "completed in review prompt", output: "STATUS: DONE", prompt: "when clean output <<<RALPHEX:REVIEW_DONE>>>",
+			wantSignal: "<<<RALPHEX:REVIEW_DONE>>>"},
+		{name: "completed in plan prompt", output: "STATUS: DONE", prompt: "emit <<<RALPHEX:PLAN_READY>>>, see <<<RALPHEX:REVIEW_DONE>>>",
+			wantSignal: "<<<RALPHEX:PLAN_READY>>>"},
+		{name: "completed without signal in prompt", output: "STATUS: DONE", wantSignal: "<<<RALPHEX:ALL_TASKS_DONE>>>"},
+		{name: "failed wins", output: "STATUS: DONE\nSTATUS: FAILED", wantSignal: "<<<RALPHEX:TASK_FAILED>>>"},
+		{name: "default signals still detected", output: "<<<RALPHEX:CODEX_REVIEW_DONE>>>", prompt: "<<<RALPHEX:REVIEW_DONE>>>",
+			wantSignal: "<<<RALPHEX:CODEX_REVIEW_DONE>>>"},
+		{name: "no match", output: "STATUS: DONE later"},
+		{name: "question", output: `ASK: {"question": "Which cache?", "options": ["Redis", "In-memory"]} END`,
+			wantOutput: `ASK: {"question": "Which cache?", "options": ["Redis", "In-memory"]} END` + "\n<<<RALPHEX:QUESTION>>>\n" +
+				`{"question": "Which cache?", "options": ["Redis", "In-memory"]}` + "\n<<<RALPHEX:END>>>\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out.txt")
+			require.NoError(t, os.WriteFile(out, []byte(tc.output), 0o600))
+			e := &CommandExecutor{Command: "cat", Args: out, Signals: signals}
+			result := e.Run(context.Background(), tc.prompt)
+			require.NoError(t, result.Error)
+			assert.Equal(t, tc.wantSignal, result.Signal)
+			if tc.wantOutput != "" {
+				assert.Equal(t, tc.wantOutput, result.Output)
+			}
+		})
+	}
+}
+
+func TestJSONValues(t *testing.T) {
+	event := map[string]any{
+		"type": "assistant",
+		"message": map[string]any{"content": []any{
+			map[string]any{"type": "text", "text": "first"},
+			map[string]any{"type": "tool_use"},
+			map[string]any{"type": "text", "text": "second"},
+		}},
+		"done": true,
+	}
+	assert.Equal(t, []any{"first", "second"}, jsonValues(event, []string{"message", "content", "text"}))
+	assert.Equal(t, []any{"assistant"}, jsonValues(event, []string{"type"}))
+	assert.Equal(t, []any{true}, jsonValues(event, []string{"done"}))
+	assert.Empty(t, jsonValues(event, []string{"message", "missing"}))
+	assert.Empty(t, jsonValues(event, []string{"type", "nested"}))
+}
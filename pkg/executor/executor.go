@@ -5,11 +5,13 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 //go:generate moq -out mocks/command_runner.go -pkg mocks -skip-ensure -fmt goimports . CommandRunner
@@ -23,6 +25,59 @@ type Result struct {
 	Output string // accumulated text output
 	Signal string // detected signal (COMPLETED, FAILED, etc.) or empty
 	Error  error  // execution error if any
+	Usage  Usage  // token usage and cost reported by the tool, zero if not reported
+
+	SessionID string // session id reported by the tool, empty if not reported
+}
+
+// Usage holds token counts, cost and duration reported for an execution.
+type Usage struct {
+	InputTokens         int
+	OutputTokens        int
+	CacheReadTokens     int
+	CacheCreationTokens int
+	OtherTokens         int // tokens reported without input/output breakdown (codex)
+	CostUSD             float64
+	Duration            time.Duration
+}
+
+// Tokens returns the total number of tokens, including cache reads and writes.
+func (u Usage) Tokens() int {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheCreationTokens + u.OtherTokens
+}
+
+// IsZero returns true if no usage was reported.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		InputTokens:         u.InputTokens + o.InputTokens,
+		OutputTokens:        u.OutputTokens + o.OutputTokens,
+		CacheReadTokens:     u.CacheReadTokens + o.CacheReadTokens,
+		CacheCreationTokens: u.CacheCreationTokens + o.CacheCreationTokens,
+		OtherTokens:         u.OtherTokens + o.OtherTokens,
+		CostUSD:             u.CostUSD + o.CostUSD,
+		Duration:            u.Duration + o.Duration,
+	}
+}
+
+// String returns a short human-readable usage summary, e.g. "12345 tokens (in 100, out 2000, cache 10245), $0.42".
+func (u Usage) String() string {
+	res := fmt.Sprintf("%d tokens", u.Tokens())
+	if u.InputTokens > 0 || u.OutputTokens > 0 {
+		res += fmt.Sprintf(" (in %d, out %d", u.InputTokens, u.OutputTokens)
+		if cached := u.CacheReadTokens + u.CacheCreationTokens; cached > 0 {
+			res += fmt.Sprintf(", cache %d", cached)
+		}
+		res += ")"
+	}
+	if u.CostUSD > 0 {
+		res += fmt.Sprintf(", $%.4f", u.CostUSD)
+	}
+	return res
 }
 
 // CommandRunner abstracts command execution for testing.
@@ -31,6 +86,52 @@ type CommandRunner interface {
 	Run(ctx context.Context, name string, args ...string) (output io.Reader, wait func() error, err error)
 }
 
+// workDirKey is the context key for the command working directory.
+type workDirKey struct{}
+
+// WithWorkDir returns a context that makes executors run commands in dir instead of the current directory.
+// used to run claude inside a git worktree for parallel task execution.
+func WithWorkDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workDirKey{}, dir)
+}
+
+// WorkDir returns the working directory set by WithWorkDir, or empty string for the current directory.
+func WorkDir(ctx context.Context) string {
+	dir, _ := ctx.Value(workDirKey{}).(string)
+	return dir
+}
+
+// diffArgsKey is the context key for git diff arguments of the reviewed changes.
+type diffArgsKey struct{}
+
+// WithDiffArgs returns a context describing the changes under review as git diff arguments,
+// e.g. "master...HEAD", no arguments mean uncommitted changes.
+// used by executors that can't run git themselves to include the diff in the request.
+func WithDiffArgs(ctx context.Context, args ...string) context.Context {
+	return context.WithValue(ctx, diffArgsKey{}, args)
+}
+
+// DiffArgs returns git diff arguments set by WithDiffArgs, ok is false if they were not set.
+func DiffArgs(ctx context.Context) (args []string, ok bool) {
+	args, ok = ctx.Value(diffArgsKey{}).([]string)
+	return args, ok
+}
+
+// resumeSessionKey is the context key for the session id to continue.
+type resumeSessionKey struct{}
+
+// WithResumeSession returns a context making claude continue the session with the given id
+// (claude --resume) instead of starting with a fresh context.
+func WithResumeSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, resumeSessionKey{}, sessionID)
+}
+
+// ResumeSession returns the session id set by WithResumeSession, or empty string for a new session.
+func ResumeSession(ctx context.Context) string {
+	id, _ := ctx.Value(resumeSessionKey{}).(string)
+	return id
+}
+
 // execClaudeRunner is the default command runner using os/exec.
 type execClaudeRunner struct{}
 
@@ -43,6 +144,7 @@ func (r *execClaudeRunner) Run(ctx context.Context, name string, args ...string)
 	// use exec.Command (not CommandContext) because we handle cancellation ourselves
 	// to ensure the entire process group is killed, not just the direct child
 	cmd := exec.Command(name, args...) //nolint:noctx // intentional: we handle context cancellation via process group kill
+	cmd.Dir = WorkDir(ctx)
 
 	// filter out ANTHROPIC_API_KEY from environment (claude uses different auth)
 	cmd.Env = filterEnv(os.Environ(), "ANTHROPIC_API_KEY")
@@ -136,8 +238,9 @@ func filterEnv(env []string, keysToRemove ...string) []string {
 
 // streamEvent represents a JSON event from claude CLI stream output.
 type streamEvent struct {
-	Type    string `json:"type"`
-	Message struct {
+	Type      string `json:"type"`
+	SessionID string `json:"session_id"` // set on the "system" init event and the final "result" event
+	Message   struct {
 		Content []struct {
 			Type string `json:"type"`
 			Text string `json:"text"`
@@ -152,14 +255,36 @@ type streamEvent struct {
 		Text string `json:"text"`
 	} `json:"delta"`
 	Result json.RawMessage `json:"result"` // can be string or object with "output" field
+
+	// error details of "error" events and failed results, error can be a string or an object
+	IsError bool            `json:"is_error"`
+	Error   json.RawMessage `json:"error"`
+
+	// session summary fields of the final "result" event
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	DurationMs   int64   `json:"duration_ms"`
+	Usage        struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	} `json:"usage"`
 }
 
+// ErrIterationTimeout is returned when a claude run exceeds ClaudeExecutor.Timeout.
+var ErrIterationTimeout = errors.New("iteration timeout")
+
+// ErrIdleTimeout is returned when claude produces no output for ClaudeExecutor.IdleTimeout.
+var ErrIdleTimeout = errors.New("idle output timeout")
+
 // ClaudeExecutor runs claude CLI commands with streaming JSON parsing.
 type ClaudeExecutor struct {
 	Command       string            // command to execute, defaults to "claude"
 	Args          string            // additional arguments (space-separated), defaults to standard args
 	OutputHandler func(text string) // called for each text chunk, can be nil
 	Debug         bool              // enable debug output
+	Timeout       time.Duration     // max duration of a single run, 0 is unlimited
+	IdleTimeout   time.Duration     // max time without stream output before the run is killed, 0 disables
 	cmdRunner     CommandRunner     // for testing, nil uses default
 }
 
@@ -181,6 +306,9 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 			"--verbose",
 		}
 	}
+	if sessionID := ResumeSession(ctx); sessionID != "" {
+		args = append(args, "--resume", sessionID)
+	}
 	args = append(args, "-p", prompt)
 
 	runner := e.cmdRunner
@@ -188,31 +316,81 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 		runner = &execClaudeRunner{}
 	}
 
-	stdout, wait, err := runner.Run(ctx, cmd, args...)
+	// the run context is canceled with a cause on timeout, which kills the whole process group
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if e.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		runCtx, cancelTimeout = context.WithTimeoutCause(runCtx, e.Timeout, ErrIterationTimeout)
+		defer cancelTimeout()
+	}
+
+	stdout, wait, err := runner.Run(runCtx, cmd, args...)
 	if err != nil {
 		return Result{Error: err}
 	}
 
+	if e.IdleTimeout > 0 {
+		idle := time.AfterFunc(e.IdleTimeout, func() { cancel(ErrIdleTimeout) })
+		defer idle.Stop()
+		stdout = &activityReader{r: stdout, onRead: func() { idle.Reset(e.IdleTimeout) }}
+	}
+
 	result := e.parseStream(stdout)
+	waitErr := wait()
+
+	// timeouts of this run are reported even if the process managed to print something
+	if ctx.Err() == nil && runCtx.Err() != nil {
+		timeout := e.Timeout
+		cause := context.Cause(runCtx)
+		if errors.Is(cause, ErrIdleTimeout) {
+			timeout = e.IdleTimeout
+		}
+		result.Error = fmt.Errorf("claude killed: %w after %s", cause, timeout)
+		return result
+	}
+
+	// limit notices come with a non-zero exit, the typed error is kept for the caller to wait and retry
+	var rateLimit *RateLimitError
+	if ctx.Err() == nil && errors.As(result.Error, &rateLimit) {
+		return result
+	}
 
-	if err := wait(); err != nil {
+	if err := waitErr; err != nil {
 		// check if it was context cancellation
 		if ctx.Err() != nil {
-			return Result{Output: result.Output, Signal: result.Signal, Error: ctx.Err()}
+			return Result{Output: result.Output, Signal: result.Signal, Error: ctx.Err(), Usage: result.Usage, SessionID: result.SessionID}
 		}
 		// non-zero exit might still have useful output
 		if result.Output == "" {
-			return Result{Error: fmt.Errorf("claude exited with error: %w", err)}
+			return Result{Error: fmt.Errorf("claude exited with error: %w", err), Usage: result.Usage, SessionID: result.SessionID}
 		}
 	}
 
 	return result
 }
 
+// activityReader calls onRead whenever the wrapped reader returns data, used for idle detection.
+type activityReader struct {
+	r      io.Reader
+	onRead func()
+}
+
+func (a *activityReader) Read(p []byte) (int, error) {
+	n, err := a.r.Read(p)
+	if n > 0 {
+		a.onRead()
+	}
+	return n, err //nolint:wrapcheck // io.Reader contract, io.EOF must not be wrapped
+}
+
 // parseStream reads and parses the JSON stream from claude CLI.
 func (e *ClaudeExecutor) parseStream(r io.Reader) Result {
 	var output strings.Builder
 	var signal string
+	var usage Usage
+	var rateLimit *RateLimitError
+	var sessionID string
 
 	scanner := bufio.NewScanner(r)
 	// increase buffer size for large JSON lines (large diffs with parallel agents)
@@ -236,10 +414,23 @@ func (e *ClaudeExecutor) parseStream(r io.Reader) Result {
 			if e.OutputHandler != nil {
 				e.OutputHandler(line + "\n")
 			}
+			if rl := detectRateLimit(line, time.Now()); rl != nil {
+				rateLimit = rl
+			}
 			continue
 		}
 
+		if event.Type == "result" {
+			usage = usage.Add(eventUsage(&event))
+		}
+		if sessionID == "" {
+			sessionID = event.SessionID
+		}
+
 		text := e.extractText(&event)
+		if rl := eventRateLimit(&event, text, time.Now()); rl != nil {
+			rateLimit = rl
+		}
 		if text != "" {
 			output.WriteString(text)
 			if e.OutputHandler != nil {
@@ -254,10 +445,27 @@ func (e *ClaudeExecutor) parseStream(r io.Reader) Result {
 	}
 
 	if err := scanner.Err(); err != nil {
-		return Result{Output: output.String(), Signal: signal, Error: fmt.Errorf("stream read: %w", err)}
+		return Result{Output: output.String(), Signal: signal, Error: fmt.Errorf("stream read: %w", err), Usage: usage,
+			SessionID: sessionID}
 	}
 
-	return Result{Output: output.String(), Signal: signal}
+	res := Result{Output: output.String(), Signal: signal, Usage: usage, SessionID: sessionID}
+	if rateLimit != nil && signal == "" {
+		res.Error = rateLimit
+	}
+	return res
+}
+
+// eventUsage extracts usage and cost from the session summary of a "result" event.
+func eventUsage(event *streamEvent) Usage {
+	return Usage{
+		InputTokens:         event.Usage.InputTokens,
+		OutputTokens:        event.Usage.OutputTokens,
+		CacheReadTokens:     event.Usage.CacheReadInputTokens,
+		CacheCreationTokens: event.Usage.CacheCreationInputTokens,
+		CostUSD:             event.TotalCostUSD,
+		Duration:            time.Duration(event.DurationMs) * time.Millisecond,
+	}
 }
 
 // extractText extracts text content from various event types.
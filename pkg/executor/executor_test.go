@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"io"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -30,6 +33,149 @@ func TestClaudeExecutor_Run_Success(t *testing.T) {
 	assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", result.Signal)
 }
 
+func TestClaudeExecutor_Run_Usage(t *testing.T) {
+	jsonStream := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"done"}}
+{"type":"result","subtype":"success","result":"done","duration_ms":12500,"total_cost_usd":0.1234,` +
+		`"usage":{"input_tokens":100,"output_tokens":2000,"cache_read_input_tokens":5000,"cache_creation_input_tokens":300}}`
+
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			return strings.NewReader(jsonStream), func() error { return nil }, nil
+		},
+	}
+	e := &ClaudeExecutor{cmdRunner: mock}
+
+	result := e.Run(context.Background(), "test prompt")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "done", result.Output)
+	assert.Equal(t, Usage{InputTokens: 100, OutputTokens: 2000, CacheReadTokens: 5000, CacheCreationTokens: 300,
+		CostUSD: 0.1234, Duration: 12500 * time.Millisecond}, result.Usage)
+	assert.Equal(t, 7400, result.Usage.Tokens())
+}
+
+func TestClaudeExecutor_Run_Timeouts(t *testing.T) {
+	// hangingRunner emits lines at the given interval until the run context is canceled
+	hangingRunner := func(interval time.Duration) *mocks.CommandRunnerMock {
+		return &mocks.CommandRunnerMock{
+			RunFunc: func(ctx context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				pr, pw := io.Pipe()
+				go func() {
+					_, _ = pw.Write([]byte(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"working"}}` + "\n"))
+					ticker := time.NewTicker(interval)
+					defer ticker.Stop()
+					for {
+						select {
+						case <-ctx.Done():
+							_ = pw.Close()
+							return
+						case <-ticker.C:
+							_, _ = pw.Write([]byte(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"."}}` + "\n"))
+						}
+					}
+				}()
+				return pr, func() error { return errors.New("signal: killed") }, nil
+			},
+		}
+	}
+
+	t.Run("idle timeout", func(t *testing.T) {
+		e := &ClaudeExecutor{cmdRunner: hangingRunner(time.Hour), IdleTimeout: 50 * time.Millisecond}
+		result := e.Run(context.Background(), "test prompt")
+		require.Error(t, result.Error)
+		require.ErrorIs(t, result.Error, ErrIdleTimeout)
+		assert.Contains(t, result.Error.Error(), "after 50ms")
+		assert.Equal(t, "working", result.Output)
+	})
+
+	t.Run("output keeps idle timer alive until iteration timeout", func(t *testing.T) {
+		e := &ClaudeExecutor{cmdRunner: hangingRunner(10 * time.Millisecond), IdleTimeout: 100 * time.Millisecond,
+			Timeout: 300 * time.Millisecond}
+		result := e.Run(context.Background(), "test prompt")
+		require.Error(t, result.Error)
+		require.ErrorIs(t, result.Error, ErrIterationTimeout)
+		assert.NotErrorIs(t, result.Error, ErrIdleTimeout)
+	})
+
+	t.Run("parent cancellation is not a timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		e := &ClaudeExecutor{cmdRunner: hangingRunner(time.Hour), IdleTimeout: time.Hour}
+		result := e.Run(ctx, "test prompt")
+		require.Error(t, result.Error)
+		require.ErrorIs(t, result.Error, context.DeadlineExceeded)
+		assert.NotErrorIs(t, result.Error, ErrIdleTimeout)
+	})
+}
+
+func TestUsage(t *testing.T) {
+	a := Usage{InputTokens: 10, OutputTokens: 20, CacheReadTokens: 30, CostUSD: 0.5, Duration: time.Second}
+	b := Usage{OtherTokens: 100, CostUSD: 0.25}
+
+	sum := a.Add(b)
+	assert.Equal(t, 160, sum.Tokens())
+	assert.InDelta(t, 0.75, sum.CostUSD, 1e-9)
+	assert.Equal(t, time.Second, sum.Duration)
+	assert.False(t, sum.IsZero())
+	assert.True(t, Usage{}.IsZero())
+
+	assert.Equal(t, "60 tokens (in 10, out 20, cache 30), $0.5000", a.String())
+	assert.Equal(t, "100 tokens", Usage{OtherTokens: 100}.String())
+}
+
+func TestClaudeExecutor_Run_RateLimit(t *testing.T) {
+	t.Run("limit notice with reset time", func(t *testing.T) {
+		jsonStream := `{"type":"assistant","message":{"content":[{"type":"text","text":"Claude AI usage limit reached|1773154800"}]}}
+{"type":"result","subtype":"success","is_error":true,"result":"Claude AI usage limit reached|1773154800"}`
+		mock := &mocks.CommandRunnerMock{
+			RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(jsonStream), func() error { return errors.New("exit status 1") }, nil
+			},
+		}
+		e := &ClaudeExecutor{cmdRunner: mock}
+
+		result := e.Run(context.Background(), "test prompt")
+
+		require.Error(t, result.Error)
+		var rl *RateLimitError
+		require.ErrorAs(t, result.Error, &rl)
+		assert.Equal(t, "Claude AI usage limit reached", rl.Message)
+		assert.Equal(t, time.Unix(1773154800, 0), rl.ResetAt)
+	})
+
+	t.Run("overload on stderr", func(t *testing.T) {
+		mock := &mocks.CommandRunnerMock{
+			RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(`API Error: 529 {"type":"error","error":{"type":"overloaded_error"}}`),
+					func() error { return errors.New("exit status 1") }, nil
+			},
+		}
+		e := &ClaudeExecutor{cmdRunner: mock}
+
+		result := e.Run(context.Background(), "test prompt")
+
+		var rl *RateLimitError
+		require.ErrorAs(t, result.Error, &rl)
+		assert.True(t, rl.ResetAt.IsZero())
+	})
+
+	t.Run("signal wins over limit notice", func(t *testing.T) {
+		jsonStream := `{"type":"assistant","message":{"content":[{"type":"text","text":"<<<RALPHEX:ALL_TASKS_DONE>>>"}]}}
+{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`
+		mock := &mocks.CommandRunnerMock{
+			RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				return strings.NewReader(jsonStream), func() error { return nil }, nil
+			},
+		}
+		e := &ClaudeExecutor{cmdRunner: mock}
+
+		result := e.Run(context.Background(), "test prompt")
+
+		require.NoError(t, result.Error)
+		assert.Equal(t, "<<<RALPHEX:ALL_TASKS_DONE>>>", result.Signal)
+	})
+}
+
 func TestClaudeExecutor_Run_StartError(t *testing.T) {
 	mock := &mocks.CommandRunnerMock{
 		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
@@ -383,6 +529,30 @@ func TestClaudeExecutor_Run_WithCustomArgs(t *testing.T) {
 	assert.Equal(t, []string{"--custom-arg", "--another-arg", "value", "-p", "test prompt"}, capturedArgs)
 }
 
+func TestClaudeExecutor_Run_Session(t *testing.T) {
+	jsonStream := `{"type":"system","subtype":"init","session_id":"4f0c6a2e-1b7d-4c1e-9d55-3a1f0e2b8c77"}
+{"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}
+{"type":"result","subtype":"success","result":"ok","session_id":"4f0c6a2e-1b7d-4c1e-9d55-3a1f0e2b8c77"}`
+
+	var capturedArgs []string
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, args ...string) (io.Reader, func() error, error) {
+			capturedArgs = args
+			return strings.NewReader(jsonStream), func() error { return nil }, nil
+		},
+	}
+	e := &ClaudeExecutor{cmdRunner: mock, Args: "--verbose"}
+
+	result := e.Run(context.Background(), "test prompt")
+	require.NoError(t, result.Error)
+	assert.Equal(t, "4f0c6a2e-1b7d-4c1e-9d55-3a1f0e2b8c77", result.SessionID)
+	assert.Equal(t, []string{"--verbose", "-p", "test prompt"}, capturedArgs)
+
+	result = e.Run(WithResumeSession(context.Background(), result.SessionID), "fix it")
+	require.NoError(t, result.Error)
+	assert.Equal(t, []string{"--verbose", "--resume", "4f0c6a2e-1b7d-4c1e-9d55-3a1f0e2b8c77", "-p", "fix it"}, capturedArgs)
+}
+
 func TestClaudeExecutor_Run_WithCustomCommandAndArgs(t *testing.T) {
 	var capturedCmd string
 	var capturedArgs []string
@@ -525,3 +695,42 @@ func TestClaudeExecutor_parseStream_multipleLargeLines(t *testing.T) {
 	require.NoError(t, result.Error)
 	assert.Len(t, result.Output, lineSize*numLines, "should contain all output from all lines")
 }
+
+func TestWithWorkDir(t *testing.T) {
+	assert.Empty(t, WorkDir(context.Background()))
+	assert.Equal(t, "/tmp/wt", WorkDir(WithWorkDir(context.Background(), "/tmp/wt")))
+
+	t.Run("runner uses work dir", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("uses pwd")
+		}
+		dir, err := filepath.EvalSymlinks(t.TempDir())
+		require.NoError(t, err)
+
+		runner := &execClaudeRunner{}
+		stdout, wait, err := runner.Run(WithWorkDir(t.Context(), dir), "pwd")
+		require.NoError(t, err)
+		out, err := io.ReadAll(stdout)
+		require.NoError(t, err)
+		require.NoError(t, wait())
+		assert.Equal(t, dir, strings.TrimSpace(string(out)))
+	})
+}
+
+func TestWithDiffArgs(t *testing.T) {
+	_, ok := DiffArgs(context.Background())
+	assert.False(t, ok)
+
+	args, ok := DiffArgs(WithDiffArgs(context.Background(), "master...HEAD"))
+	assert.True(t, ok)
+	assert.Equal(t, []string{"master...HEAD"}, args)
+
+	args, ok = DiffArgs(WithDiffArgs(context.Background()))
+	assert.True(t, ok, "no arguments mean uncommitted changes")
+	assert.Empty(t, args)
+}
+
+func TestWithResumeSession(t *testing.T) {
+	assert.Empty(t, ResumeSession(context.Background()))
+	assert.Equal(t, "abc", ResumeSession(WithResumeSession(context.Background(), "abc")))
+}
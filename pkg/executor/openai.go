@@ -0,0 +1,341 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultContextSize is the model context size in tokens used if OpenAIExecutor.ContextSize is not set.
+	DefaultContextSize = 32768
+
+	charsPerToken   = 4    // rough estimate of characters per token for code and diffs
+	minDiffChunk    = 1024 // min diff characters per request, smaller context sizes are rejected
+	maxErrorBodyLen = 4096 // max length of an error response body kept in the error
+)
+
+// openAISystemPrompt tells the model that it can't run the commands review prompts ask for.
+const openAISystemPrompt = "You are a code reviewer. You can't run commands or read files, " +
+	"the git diff to review is included in the message, use it instead of running git commands."
+
+// OpenAIExecutor reviews code with a model served by an OpenAI-compatible chat completions API,
+// e.g. ollama, vLLM or llama.cpp server. The model can't run tools, so the executor gathers
+// the git diff described by WithDiffArgs itself and sends it with the prompt, split into as many
+// requests as needed to fit the context size. Responses are streamed to OutputHandler.
+type OpenAIExecutor struct {
+	Name          string            // executor name used in errors, defaults to Model
+	URL           string            // base URL of the API, e.g. http://localhost:11434/v1
+	Model         string            // model name
+	APIKey        string            // bearer token, can be empty for local servers
+	ContextSize   int               // model context size in tokens, DefaultContextSize if 0
+	Timeout       time.Duration     // max duration of a single run, 0 is unlimited
+	OutputHandler func(text string) // called for each text chunk, can be nil
+	Client        *http.Client      // http client, http.DefaultClient if nil
+
+	gitDiff func(ctx context.Context, args []string) (string, error) // diff source, runs git if nil
+}
+
+// chatMessage is a chat completions API message.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatStreamEvent is a streamed chat completions API event.
+type chatStreamEvent struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Run sends the prompt with the reviewed diff to the model and collects the streamed response.
+// without diff arguments in the context the prompt is sent as-is.
+func (e *OpenAIExecutor) Run(ctx context.Context, prompt string) Result {
+	name := e.Name
+	if name == "" {
+		name = e.Model
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{Error: fmt.Errorf("context already canceled: %w", err)}
+	}
+
+	runCtx := ctx
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, e.Timeout, ErrIterationTimeout)
+		defer cancel()
+	}
+
+	messages, err := e.buildMessages(runCtx, prompt)
+	if err != nil {
+		return Result{Error: fmt.Errorf("%s: %w", name, err)}
+	}
+
+	start := time.Now()
+	var res Result
+	var output strings.Builder
+	for i, msg := range messages {
+		if len(messages) > 1 {
+			header := fmt.Sprintf("### diff part %d/%d\n\n", i+1, len(messages))
+			if i > 0 {
+				header = "\n\n" + header
+			}
+			e.emit(&output, header)
+		}
+		usage, reqErr := e.complete(runCtx, msg, &output)
+		res.Usage = res.Usage.Add(usage)
+		if reqErr != nil {
+			res.Output = output.String()
+			if ctx.Err() == nil && runCtx.Err() != nil {
+				res.Error = fmt.Errorf("%s: %w after %s", name, context.Cause(runCtx), e.Timeout)
+				return res
+			}
+			res.Error = fmt.Errorf("%s: %w", name, reqErr)
+			return res
+		}
+	}
+	res.Usage.Duration = time.Since(start)
+	res.Output = output.String()
+	res.Signal = detectSignal(res.Output)
+	return res
+}
+
+// buildMessages returns the user message of every request, one per diff chunk.
+func (e *OpenAIExecutor) buildMessages(ctx context.Context, prompt string) ([]string, error) {
+	args, ok := DiffArgs(ctx)
+	if !ok {
+		return []string{prompt}, nil
+	}
+	gitDiff := e.gitDiff
+	if gitDiff == nil {
+		gitDiff = runGitDiff
+	}
+	diff, err := gitDiff(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	diffCmd := strings.TrimSpace("git diff " + strings.Join(args, " "))
+	if strings.TrimSpace(diff) == "" {
+		return []string{fmt.Sprintf("%s\n\n## Diff\n\n`%s` shows no changes.\n", prompt, diffCmd)}, nil
+	}
+
+	contextSize := e.ContextSize
+	if contextSize <= 0 {
+		contextSize = DefaultContextSize
+	}
+	// a quarter of the context is left for the response
+	budget := contextSize*3/4*charsPerToken - len(openAISystemPrompt) - len(prompt) - 200
+	if budget < minDiffChunk {
+		return nil, fmt.Errorf("context size %d tokens is too small for the review prompt", contextSize)
+	}
+
+	chunks := splitDiff(diff, budget)
+	messages := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		part := ""
+		if len(chunks) > 1 {
+			part = fmt.Sprintf(" (part %d of %d, review this part only)", i+1, len(chunks))
+		}
+		messages = append(messages, fmt.Sprintf("%s\n\n## Diff\n\nOutput of `%s`%s:\n\n```diff\n%s```\n",
+			prompt, diffCmd, part, chunk))
+	}
+	return messages, nil
+}
+
+// complete sends a single streamed chat completions request and writes the response text to output.
+func (e *OpenAIExecutor) complete(ctx context.Context, message string, output *strings.Builder) (Usage, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":          e.Model,
+		"stream":         true,
+		"stream_options": map[string]any{"include_usage": true},
+		"messages": []chatMessage{
+			{Role: "system", Content: openAISystemPrompt},
+			{Role: "user", Content: message},
+		},
+	})
+	if err != nil {
+		return Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(e.URL, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Usage{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Usage{}, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Usage{}, responseError(resp)
+	}
+
+	var usage Usage
+	scanner := bufio.NewScanner(resp.Body)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, maxScannerBuffer)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue // blank separators, comments and other SSE fields
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var event chatStreamEvent
+		if jsonErr := json.Unmarshal([]byte(data), &event); jsonErr != nil {
+			return usage, fmt.Errorf("decode stream event: %w", jsonErr)
+		}
+		if event.Error != nil {
+			return usage, fmt.Errorf("stream error: %s", event.Error.Message)
+		}
+		if event.Usage != nil {
+			usage.InputTokens, usage.OutputTokens = event.Usage.PromptTokens, event.Usage.CompletionTokens
+		}
+		for _, choice := range event.Choices {
+			e.emit(output, choice.Delta.Content)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return usage, fmt.Errorf("read response: %w", err)
+	}
+	return usage, nil
+}
+
+// emit appends text to output and passes it to OutputHandler.
+func (e *OpenAIExecutor) emit(output *strings.Builder, text string) {
+	if text == "" {
+		return
+	}
+	output.WriteString(text)
+	if e.OutputHandler != nil {
+		e.OutputHandler(text)
+	}
+}
+
+// responseError converts an unsuccessful API response to an error.
+// 429 and 503 are returned as RateLimitError with the reset time from the Retry-After header.
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen))
+	msg := strings.TrimSpace(string(data))
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+		msg = apiErr.Error.Message
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		rl := &RateLimitError{Message: fmt.Sprintf("http %d: %s", resp.StatusCode, msg)}
+		if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && sec > 0 {
+			rl.ResetAt = time.Now().Add(time.Duration(sec) * time.Second)
+		}
+		return rl
+	}
+	return fmt.Errorf("http %d: %s", resp.StatusCode, msg)
+}
+
+// runGitDiff returns the output of git diff with the given arguments in the context working directory.
+func runGitDiff(ctx context.Context, args []string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"diff", "--no-color", "--no-ext-diff"}, args...)...)
+	cmd.Dir = WorkDir(ctx)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git diff: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// splitDiff splits a unified diff into chunks of at most size characters.
+// files are kept whole when they fit a chunk, larger files are split at line boundaries with
+// their "diff --git" header repeated in every part. lines are never split, so a single line
+// longer than size makes its chunk exceed the limit.
+func splitDiff(diff string, size int) []string {
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, file := range splitDiffFiles(diff) {
+		if cur.Len()+len(file) > size {
+			flush()
+		}
+		if len(file) <= size {
+			cur.WriteString(file)
+			continue
+		}
+		header, body, _ := strings.Cut(file, "\n")
+		cur.WriteString(header + "\n")
+		partStart := cur.Len() // lines of the current part start after the header
+		for _, line := range strings.SplitAfter(body, "\n") {
+			if cur.Len() > partStart && cur.Len()+len(line) > size {
+				flush()
+				cur.WriteString(header + "\n")
+			}
+			cur.WriteString(line)
+		}
+		flush()
+	}
+	flush()
+	return chunks
+}
+
+// splitDiffFiles splits a unified diff into per-file sections starting with "diff --git".
+func splitDiffFiles(diff string) []string {
+	if !strings.HasSuffix(diff, "\n") {
+		diff += "\n"
+	}
+	var files []string
+	start := 0
+	for i := 0; i < len(diff); {
+		next := strings.IndexByte(diff[i:], '\n')
+		if next < 0 {
+			break
+		}
+		if i > start && strings.HasPrefix(diff[i:], "diff --git ") {
+			files = append(files, diff[start:i])
+			start = i
+		}
+		i += next + 1
+	}
+	return append(files, diff[start:])
+}
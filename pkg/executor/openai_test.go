@@ -0,0 +1,258 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chatRequest is a chat completions request received by the stub server.
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Stream   bool          `json:"stream"`
+	Messages []chatMessage `json:"messages"`
+	Auth     string        `json:"-"`
+}
+
+// newChatStub starts a stub chat completions server streaming the response for each request.
+func newChatStub(t *testing.T, respond func(req chatRequest) []string) (*httptest.Server, func() []chatRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		var req chatRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		req.Auth = r.Header.Get("Authorization")
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, text := range respond(req) {
+			event, err := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]any{"content": text}}}})
+			assert.NoError(t, err)
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", event)
+			w.(http.Flusher).Flush()
+		}
+		_, _ = fmt.Fprint(w, `data: {"choices":[],"usage":{"prompt_tokens":100,"completion_tokens":20}}`+"\n\n")
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []chatRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestOpenAIExecutor_Run(t *testing.T) {
+	srv, requests := newChatStub(t, func(chatRequest) []string { return []string{"found issue ", "in foo.go:10"} })
+	var gotArgs []string
+	var streamed strings.Builder
+	e := &OpenAIExecutor{URL: srv.URL + "/v1/", Model: "qwen", APIKey: "secret",
+		OutputHandler: func(text string) { streamed.WriteString(text) },
+		gitDiff: func(_ context.Context, args []string) (string, error) {
+			gotArgs = args
+			return "diff --git a/foo.go b/foo.go\n+func foo() {}\n", nil
+		}}
+
+	result := e.Run(WithDiffArgs(context.Background(), "master...HEAD"), "Review the changes. Run: git diff master...HEAD")
+	require.NoError(t, result.Error)
+	assert.Equal(t, "found issue in foo.go:10", result.Output)
+	assert.Equal(t, result.Output, streamed.String())
+	assert.Equal(t, []string{"master...HEAD"}, gotArgs)
+	assert.Equal(t, 100, result.Usage.InputTokens)
+	assert.Equal(t, 20, result.Usage.OutputTokens)
+
+	reqs := requests()
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "qwen", reqs[0].Model)
+	assert.True(t, reqs[0].Stream)
+	assert.Equal(t, "Bearer secret", reqs[0].Auth)
+	require.Len(t, reqs[0].Messages, 2)
+	assert.Equal(t, "system", reqs[0].Messages[0].Role)
+	user := reqs[0].Messages[1].Content
+	assert.True(t, strings.HasPrefix(user, "Review the changes."))
+	assert.Contains(t, user, "Output of `git diff master...HEAD`:")
+	assert.Contains(t, user, "```diff\ndiff --git a/foo.go b/foo.go\n+func foo() {}\n```")
+}
+
+func TestOpenAIExecutor_Run_Chunks(t *testing.T) {
+	srv, requests := newChatStub(t, func(req chatRequest) []string {
+		if strings.Contains(req.Messages[1].Content, "b.go") {
+			return []string{"issue in b.go"}
+		}
+		return []string{"issue in a.go"}
+	})
+
+	fileA := "diff --git a/a.go b/a.go\n" + strings.Repeat("+a line of code in a.go\n", 300)
+	fileB := "diff --git a/b.go b/b.go\n" + strings.Repeat("+a line of code in b.go\n", 300)
+	e := &OpenAIExecutor{URL: srv.URL + "/v1", Model: "qwen", ContextSize: 3000,
+		gitDiff: func(context.Context, []string) (string, error) { return fileA + fileB, nil }}
+
+	result := e.Run(WithDiffArgs(context.Background()), "Review uncommitted changes.")
+	require.NoError(t, result.Error)
+	assert.Equal(t, "### diff part 1/2\n\nissue in a.go\n\n### diff part 2/2\n\nissue in b.go", result.Output)
+	assert.Equal(t, 200, result.Usage.InputTokens, "usage of all requests is summed")
+
+	reqs := requests()
+	require.Len(t, reqs, 2)
+	assert.Contains(t, reqs[0].Messages[1].Content, "Output of `git diff` (part 1 of 2, review this part only):")
+	assert.NotContains(t, reqs[0].Messages[1].Content, "b.go")
+	assert.Contains(t, reqs[1].Messages[1].Content, "(part 2 of 2, review this part only)")
+}
+
+func TestOpenAIExecutor_Run_NoDiff(t *testing.T) {
+	srv, requests := newChatStub(t, func(chatRequest) []string { return []string{"no issues"} })
+
+	t.Run("without diff args", func(t *testing.T) {
+		e := &OpenAIExecutor{URL: srv.URL + "/v1", Model: "qwen",
+			gitDiff: func(context.Context, []string) (string, error) { return "", errors.New("unexpected") }}
+		result := e.Run(context.Background(), "just a prompt")
+		require.NoError(t, result.Error)
+		assert.Equal(t, "just a prompt", requests()[0].Messages[1].Content)
+	})
+
+	t.Run("empty diff", func(t *testing.T) {
+		e := &OpenAIExecutor{URL: srv.URL + "/v1", Model: "qwen",
+			gitDiff: func(context.Context, []string) (string, error) { return "\n", nil }}
+		result := e.Run(WithDiffArgs(context.Background()), "check fixes")
+		require.NoError(t, result.Error)
+		assert.Equal(t, "no issues", result.Output)
+		assert.Contains(t, requests()[1].Messages[1].Content, "`git diff` shows no changes.")
+	})
+}
+
+func TestOpenAIExecutor_Run_Errors(t *testing.T) {
+	diff := func(context.Context, []string) (string, error) { return "diff --git a/a b/a\n+x\n", nil }
+
+	t.Run("rate limit", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"message":"too many requests"}}`))
+		}))
+		defer srv.Close()
+		e := &OpenAIExecutor{URL: srv.URL, Model: "qwen", gitDiff: diff}
+		result := e.Run(WithDiffArgs(context.Background()), "review")
+		var rl *RateLimitError
+		require.ErrorAs(t, result.Error, &rl)
+		assert.Equal(t, "http 429: too many requests", rl.Message)
+		assert.WithinDuration(t, time.Now().Add(30*time.Second), rl.ResetAt, 5*time.Second)
+	})
+
+	t.Run("http error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "model not found", http.StatusNotFound)
+		}))
+		defer srv.Close()
+		e := &OpenAIExecutor{Name: "local", URL: srv.URL, Model: "qwen", gitDiff: diff}
+		result := e.Run(WithDiffArgs(context.Background()), "review")
+		require.EqualError(t, result.Error, "local: http 404: model not found")
+	})
+
+	t.Run("stream error", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\n")
+			_, _ = fmt.Fprint(w, "data: {\"error\":{\"message\":\"out of memory\"}}\n\n")
+		}))
+		defer srv.Close()
+		e := &OpenAIExecutor{URL: srv.URL, Model: "qwen", gitDiff: diff}
+		result := e.Run(WithDiffArgs(context.Background()), "review")
+		require.EqualError(t, result.Error, "qwen: stream error: out of memory")
+		assert.Equal(t, "partial", result.Output)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body) // server notices the closed connection only after the body is read
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}))
+		defer srv.Close()
+		e := &OpenAIExecutor{URL: srv.URL, Model: "qwen", Timeout: 100 * time.Millisecond, gitDiff: diff}
+		result := e.Run(WithDiffArgs(context.Background()), "review")
+		require.ErrorIs(t, result.Error, ErrIterationTimeout)
+	})
+
+	t.Run("diff failure", func(t *testing.T) {
+		e := &OpenAIExecutor{URL: "http://localhost:1", Model: "qwen",
+			gitDiff: func(context.Context, []string) (string, error) { return "", errors.New("git diff: bad revision") }}
+		result := e.Run(WithDiffArgs(context.Background(), "master...HEAD"), "review")
+		require.EqualError(t, result.Error, "qwen: git diff: bad revision")
+	})
+
+	t.Run("context too small", func(t *testing.T) {
+		e := &OpenAIExecutor{URL: "http://localhost:1", Model: "qwen", ContextSize: 100, gitDiff: diff}
+		result := e.Run(WithDiffArgs(context.Background()), "review")
+		require.Error(t, result.Error)
+		assert.Contains(t, result.Error.Error(), "context size 100 tokens is too small")
+	})
+}
+
+func TestRunGitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "foo.txt"), []byte("hello\n"), 0o600))
+	git("add", "foo.txt")
+
+	ctx := WithWorkDir(context.Background(), dir)
+	diff, err := runGitDiff(ctx, []string{"HEAD"})
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+hello")
+
+	_, err = runGitDiff(ctx, []string{"no-such-branch...HEAD"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "git diff")
+}
+
+func TestSplitDiff(t *testing.T) {
+	fileA := "diff --git a/a b/a\n+1\n"
+	fileB := "diff --git a/b b/b\n+2\n"
+	large := "diff --git a/c b/c\n+3333\n+4444\n+5555\n"
+
+	tests := []struct {
+		name string
+		diff string
+		size int
+		want []string
+	}{
+		{name: "fits", diff: fileA + fileB, size: 100, want: []string{fileA + fileB}},
+		{name: "split by files", diff: fileA + fileB, size: 30, want: []string{fileA, fileB}},
+		{name: "large file split by lines with header", diff: fileA + large, size: 32, want: []string{fileA,
+			"diff --git a/c b/c\n+3333\n+4444\n", "diff --git a/c b/c\n+5555\n"}},
+		{name: "no trailing newline", diff: "diff --git a/a b/a\n+1", size: 100, want: []string{fileA}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, splitDiff(tc.diff, tc.size))
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitError is returned when claude reports a usage limit or an API rate limit or overload.
+// ResetAt is the reported limit reset time, zero if the message didn't include one.
+type RateLimitError struct {
+	Message string
+	ResetAt time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if e.ResetAt.IsZero() {
+		return "rate limited: " + e.Message
+	}
+	return fmt.Sprintf("rate limited until %s: %s", e.ResetAt.Format(time.DateTime), e.Message)
+}
+
+var (
+	// rateLimitPattern matches limit and overload messages printed by claude code,
+	// e.g. "Claude AI usage limit reached|1760000000", "5-hour limit reached ∙ resets 3pm",
+	// "API Error: 529 {...overloaded_error...}".
+	rateLimitPattern = regexp.MustCompile(`(?i)(usage limit reached|\blimit reached\b.*\bresets?\b|` +
+		`api error: (429|529)|rate_limit(_error)?|overloaded_error)`)
+
+	// rateLimitAssistantPattern matches assistant messages that consist of a limit notice.
+	// assistant text is checked strictly, claude may legitimately talk about rate limits in code.
+	rateLimitAssistantPattern = regexp.MustCompile(`(?i)^\s*(claude ai usage limit reached|[\w-]+ limit reached|api error: (429|529))`)
+
+	resetUnixPattern  = regexp.MustCompile(`limit reached\|(\d{10})`)
+	resetClockPattern = regexp.MustCompile(`(?i)resets?(?: at)? (\d{1,2})(?::(\d{2}))?\s*(am|pm)?`)
+)
+
+// detectRateLimit checks a message for limit or overload notices and parses the reset time relative to now.
+// returns nil if the message is not a rate limit notice.
+func detectRateLimit(text string, now time.Time) *RateLimitError {
+	if !rateLimitPattern.MatchString(text) {
+		return nil
+	}
+	res := &RateLimitError{Message: strings.TrimSpace(firstLine(text))}
+
+	if m := resetUnixPattern.FindStringSubmatch(text); m != nil {
+		if sec, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			res.ResetAt = time.Unix(sec, 0)
+			res.Message = strings.TrimSpace(strings.SplitN(res.Message, "|", 2)[0])
+		}
+		return res
+	}
+
+	if m := resetClockPattern.FindStringSubmatch(text); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2]) // empty minutes parse as 0
+		switch strings.ToLower(m[3]) {
+		case "pm":
+			if hour < 12 {
+				hour += 12
+			}
+		case "am":
+			if hour == 12 {
+				hour = 0
+			}
+		}
+		if hour < 24 && minute < 60 {
+			reset := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+			if !reset.After(now) {
+				reset = reset.AddDate(0, 0, 1)
+			}
+			res.ResetAt = reset
+		}
+	}
+	return res
+}
+
+// eventRateLimit checks a stream event for limit notices. text is the text extracted from the event.
+// error events and failed results are checked as is, assistant text only if it starts with a limit notice.
+func eventRateLimit(event *streamEvent, text string, now time.Time) *RateLimitError {
+	switch {
+	case event.Type == "error" || len(event.Error) > 0:
+		return detectRateLimit(strings.TrimSpace(text+"\n"+eventErrorText(event)), now)
+	case event.Type == "result" && event.IsError:
+		var msg string
+		if err := json.Unmarshal(event.Result, &msg); err != nil {
+			msg = string(event.Result)
+		}
+		return detectRateLimit(msg, now)
+	case event.Type == "assistant" && rateLimitAssistantPattern.MatchString(text):
+		return detectRateLimit(text, now)
+	}
+	return nil
+}
+
+// eventErrorText returns the error of an event, which is either a string or an object with type and message.
+func eventErrorText(event *streamEvent) string {
+	var msg string
+	if err := json.Unmarshal(event.Error, &msg); err == nil {
+		return msg
+	}
+	var obj struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(event.Error, &obj); err == nil {
+		return strings.TrimSpace(obj.Type + " " + obj.Message)
+	}
+	return string(event.Error)
+}
+
+// firstLine returns the first line of text.
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
@@ -0,0 +1,104 @@
+package executor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRateLimit(t *testing.T) {
+	now := time.Date(2026, 3, 10, 14, 20, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		text      string
+		want      bool
+		wantMsg   string
+		wantReset time.Time
+	}{
+		{name: "regular text", text: "implemented the rate limiter middleware"},
+		{name: "empty", text: ""},
+		{name: "usage limit with unix reset", text: "Claude AI usage limit reached|1773154800", want: true,
+			wantMsg: "Claude AI usage limit reached", wantReset: time.Unix(1773154800, 0)},
+		{name: "limit with clock reset pm", text: "5-hour limit reached ∙ resets 3pm", want: true,
+			wantMsg: "5-hour limit reached ∙ resets 3pm", wantReset: time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)},
+		{name: "clock reset with minutes", text: "Claude usage limit reached. Your limit will reset at 2:45pm (Europe/Berlin).",
+			want: true, wantMsg: "Claude usage limit reached. Your limit will reset at 2:45pm (Europe/Berlin).",
+			wantReset: time.Date(2026, 3, 10, 14, 45, 0, 0, time.Local)},
+		{name: "clock reset already passed is tomorrow", text: "weekly limit reached ∙ resets 9am", want: true,
+			wantMsg: "weekly limit reached ∙ resets 9am", wantReset: time.Date(2026, 3, 11, 9, 0, 0, 0, time.Local)},
+		{name: "12am is midnight", text: "usage limit reached, resets 12am", want: true,
+			wantMsg: "usage limit reached, resets 12am", wantReset: time.Date(2026, 3, 11, 0, 0, 0, 0, time.Local)},
+		{name: "overloaded", text: `API Error: 529 {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			want: true, wantMsg: `API Error: 529 {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`},
+		{name: "rate limit error", text: "rate_limit_error: too many requests\nmore details", want: true,
+			wantMsg: "rate_limit_error: too many requests"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := detectRateLimit(tc.text, now)
+			if !tc.want {
+				assert.Nil(t, res)
+				return
+			}
+			require.NotNil(t, res)
+			assert.Equal(t, tc.wantMsg, res.Message)
+			assert.True(t, tc.wantReset.Equal(res.ResetAt), "reset at %v, want %v", res.ResetAt, tc.wantReset)
+		})
+	}
+}
+
+func TestEventRateLimit(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		event string
+		want  string
+	}{
+		{name: "assistant text", event: `{"type":"assistant","message":{"content":[{"type":"text","text":"done"}]}}`},
+		{name: "assistant discussing limits",
+			event: `{"type":"assistant","message":{"content":[{"type":"text","text":"added handling for usage limit reached errors"}]}}`},
+		{name: "assistant limit notice",
+			event: `{"type":"assistant","message":{"content":[{"type":"text","text":"Claude AI usage limit reached|1773154800"}]}}`,
+			want:  "Claude AI usage limit reached"},
+		{name: "assistant with error code",
+			event: `{"type":"assistant","message":{"content":[{"type":"text","text":"Try again later"}]},"error":"rate_limit"}`,
+			want:  "Try again later"},
+		{name: "error event", event: `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			want: "overloaded_error Overloaded"},
+		{name: "failed result", event: `{"type":"result","subtype":"success","is_error":true,"result":"API Error: 429 rate limited"}`,
+			want: "API Error: 429 rate limited"},
+		{name: "successful result mentioning limits",
+			event: `{"type":"result","subtype":"success","is_error":false,"result":"fixed rate_limit_error handling"}`},
+		{name: "null error", event: `{"type":"assistant","message":{"content":[]},"error":null}`},
+	}
+
+	e := &ClaudeExecutor{}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var event streamEvent
+			require.NoError(t, json.Unmarshal([]byte(tc.event), &event))
+			res := eventRateLimit(&event, e.extractText(&event), now)
+			if tc.want == "" {
+				assert.Nil(t, res)
+				return
+			}
+			require.NotNil(t, res)
+			assert.Equal(t, tc.want, res.Message)
+		})
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	err := &RateLimitError{Message: "overloaded"}
+	assert.Equal(t, "rate limited: overloaded", err.Error())
+
+	reset := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	err = &RateLimitError{Message: "usage limit reached", ResetAt: reset}
+	assert.Equal(t, "rate limited until 2026-03-10 15:00:00: usage limit reached", err.Error())
+}
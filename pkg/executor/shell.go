@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// RunShell executes a shell command in dir and returns its combined stdout and stderr.
+// the command runs in its own process group, so canceling ctx (e.g. on timeout) kills
+// the whole process tree. env entries are appended to the current environment.
+func RunShell(ctx context.Context, dir, command string, env []string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("context already canceled: %w", err)
+	}
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	// use exec.Command (not CommandContext), cancellation is handled by the process group cleanup
+	cmd := exec.Command(shell, flag, command) //nolint:noctx,gosec // intentional: commands come from plan or config
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	setupProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("start command: %w", err)
+	}
+	cleanup := newProcessGroupCleanup(cmd, ctx.Done())
+	if err := cleanup.Wait(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return out.String(), fmt.Errorf("%w: %w", ctxErr, err)
+		}
+		return out.String(), err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,53 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunShell(t *testing.T) {
+	t.Run("captures combined output", func(t *testing.T) {
+		out, err := RunShell(context.Background(), "", "echo out; echo err >&2", nil)
+		require.NoError(t, err)
+		assert.Equal(t, "out\nerr\n", out)
+	})
+
+	t.Run("runs in directory with extra env", func(t *testing.T) {
+		dir := t.TempDir()
+		out, err := RunShell(context.Background(), dir, `pwd; echo "$RALPHEX_TEST_VAR"`, []string{"RALPHEX_TEST_VAR=value"})
+		require.NoError(t, err)
+		assert.Contains(t, out, "value")
+		assert.Contains(t, out, dir)
+	})
+
+	t.Run("non-zero exit returns output and error", func(t *testing.T) {
+		out, err := RunShell(context.Background(), "", "echo broken; exit 3", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit status 3")
+		assert.Equal(t, "broken\n", out)
+	})
+
+	t.Run("timeout kills command", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := RunShell(ctx, "", "sleep 10", nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := RunShell(ctx, "", "echo never", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "context already canceled")
+	})
+}
@@ -0,0 +1,47 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommitsSince returns one-line summaries (short hash and subject) of commits made after the given
+// commit on the current branch, newest first.
+func (r *Repo) CommitsSince(hash string) ([]string, error) {
+	out, err := r.runGit(r.path, "log", "--oneline", "--no-decorate", hash+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("list commits: %w", err)
+	}
+	return splitLines(out), nil
+}
+
+// ChangedFilesSince returns paths changed since the given commit, including uncommitted changes
+// of tracked files. paths are relative to the repository root.
+func (r *Repo) ChangedFilesSince(hash string) ([]string, error) {
+	out, err := r.runGit(r.path, "diff", "--name-only", hash)
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+	return splitLines(out), nil
+}
+
+// DiffStatSince returns the diffstat of changes since the given commit, including uncommitted changes
+// of tracked files. returns empty string if nothing changed.
+func (r *Repo) DiffStatSince(hash string) (string, error) {
+	out, err := r.runGit(r.path, "diff", "--stat", hash)
+	if err != nil {
+		return "", fmt.Errorf("get diffstat: %w", err)
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+// splitLines splits git output into non-empty lines.
+func splitLines(out string) []string {
+	var res []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			res = append(res, line)
+		}
+	}
+	return res
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepo_ChangesSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git CLI not available")
+	}
+
+	dir := setupTestRepo(t)
+	repo, err := Open(dir)
+	require.NoError(t, err)
+	start, err := repo.HeadHash()
+	require.NoError(t, err)
+
+	commits, err := repo.CommitsSince(start)
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+	stat, err := repo.DiffStatSince(start)
+	require.NoError(t, err)
+	assert.Empty(t, stat)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "feature.go"), []byte("package main\n"), 0o600))
+	_, err = repo.CommitAll(dir, "add feature")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\nmore\n"), 0o600))
+
+	commits, err = repo.CommitsSince(start)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Contains(t, commits[0], "add feature")
+
+	files, err := repo.ChangedFilesSince(start)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "feature.go"}, files, "committed and uncommitted changes")
+
+	stat, err = repo.DiffStatSince(start)
+	require.NoError(t, err)
+	assert.Contains(t, stat, "2 files changed, 2 insertions(+)")
+
+	_, err = repo.CommitsSince("0000000000000000000000000000000000000000")
+	require.Error(t, err)
+}
@@ -153,6 +153,15 @@ func (r *Repo) CurrentBranch() (string, error) {
 	return head.Name().Short(), nil
 }
 
+// HeadHash returns the full hash of the commit HEAD points to.
+func (r *Repo) HeadHash() (string, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("get HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
 // CreateBranch creates a new branch and switches to it.
 // Returns error if branch already exists to prevent data loss.
 func (r *Repo) CreateBranch(name string) error {
@@ -205,6 +214,39 @@ func (r *Repo) BranchExists(name string) bool {
 	return err == nil
 }
 
+// DefaultBranch returns the default branch of the repository, the branch origin/HEAD points to.
+// Falls back to a local "main" or "master" branch if origin/HEAD is not set, and to "master" if neither exists.
+func (r *Repo) DefaultBranch() string {
+	ref, err := r.repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), false)
+	if err == nil && ref.Type() == plumbing.SymbolicReference {
+		if name, ok := strings.CutPrefix(ref.Target().String(), "refs/remotes/origin/"); ok && name != "" {
+			return name
+		}
+	}
+	for _, name := range []string{"main", "master"} {
+		if r.BranchExists(name) {
+			return name
+		}
+	}
+	return "master"
+}
+
+// BaseRef returns the ref changes are diffed against for the given base branch, the default branch if empty.
+// A base branch existing only as origin/<branch>, e.g. the default branch of a clone made with a feature branch
+// checked out, resolves to the remote ref.
+func (r *Repo) BaseRef(branch string) string {
+	if branch == "" {
+		branch = r.DefaultBranch()
+	}
+	if r.BranchExists(branch) {
+		return branch
+	}
+	if _, err := r.repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), false); err == nil {
+		return "origin/" + branch
+	}
+	return branch
+}
+
 // CheckoutBranch switches to an existing branch.
 func (r *Repo) CheckoutBranch(name string) error {
 	wt, err := r.repo.Worktree()
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -148,6 +149,34 @@ func TestRepo_CurrentBranch(t *testing.T) {
 	})
 }
 
+func TestRepo_HeadHash(t *testing.T) {
+	t.Run("returns hash of current commit", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		repo, err := Open(dir)
+		require.NoError(t, err)
+
+		head, err := repo.repo.Head()
+		require.NoError(t, err)
+
+		hash, err := repo.HeadHash()
+		require.NoError(t, err)
+		assert.Equal(t, head.Hash().String(), hash)
+		assert.Len(t, hash, 40)
+	})
+
+	t.Run("fails on repo without commits", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := git.PlainInit(dir, false)
+		require.NoError(t, err)
+
+		repo, err := Open(dir)
+		require.NoError(t, err)
+
+		_, err = repo.HeadHash()
+		require.Error(t, err)
+	})
+}
+
 func TestRepo_CreateBranch(t *testing.T) {
 	t.Run("creates and switches to branch", func(t *testing.T) {
 		dir := setupTestRepo(t)
@@ -448,6 +477,52 @@ func TestRepo_BranchExists(t *testing.T) {
 	})
 }
 
+func TestRepo_DefaultBranch(t *testing.T) {
+	t.Run("uses origin HEAD", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		repo, err := Open(dir)
+		require.NoError(t, err)
+
+		ref := plumbing.NewSymbolicReference(plumbing.NewRemoteHEADReferenceName("origin"),
+			plumbing.NewRemoteReferenceName("origin", "develop"))
+		require.NoError(t, repo.repo.Storer.SetReference(ref))
+		assert.Equal(t, "develop", repo.DefaultBranch())
+	})
+
+	t.Run("falls back to local main or master", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		repo, err := Open(dir)
+		require.NoError(t, err)
+		assert.Equal(t, "master", repo.DefaultBranch())
+
+		require.NoError(t, repo.CreateBranch("main"))
+		assert.Equal(t, "main", repo.DefaultBranch())
+	})
+}
+
+func TestRepo_BaseRef(t *testing.T) {
+	dir := setupTestRepo(t)
+	repo, err := Open(dir)
+	require.NoError(t, err)
+	head, err := repo.repo.Head()
+	require.NoError(t, err)
+
+	assert.Equal(t, "master", repo.BaseRef(""), "default branch")
+	assert.Equal(t, "master", repo.BaseRef("master"))
+	assert.Equal(t, "unknown", repo.BaseRef("unknown"), "unresolved branch is used as is")
+
+	// default branch existing only on origin, e.g. a clone checked out on a feature branch
+	require.NoError(t, repo.repo.Storer.SetReference(
+		plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "develop"), head.Hash())))
+	require.NoError(t, repo.repo.Storer.SetReference(plumbing.NewSymbolicReference(
+		plumbing.NewRemoteHEADReferenceName("origin"), plumbing.NewRemoteReferenceName("origin", "develop"))))
+	assert.Equal(t, "origin/develop", repo.BaseRef(""))
+	assert.Equal(t, "origin/develop", repo.BaseRef("develop"))
+
+	require.NoError(t, repo.CreateBranch("develop"))
+	assert.Equal(t, "develop", repo.BaseRef(""), "local branch wins over remote")
+}
+
 func TestRepo_CheckoutBranch(t *testing.T) {
 	t.Run("switches to existing branch", func(t *testing.T) {
 		dir := setupTestRepo(t)
@@ -0,0 +1,113 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// MergeConflictError is returned by MergeBranch when the merge stops on conflicts.
+// the merge is aborted before returning, so the working tree is left as it was.
+type MergeConflictError struct {
+	Branch string   // branch that failed to merge
+	Files  []string // conflicting paths, relative to the repository root
+}
+
+// Error implements error.
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("merge conflict merging %s: %s", e.Branch, strings.Join(e.Files, ", "))
+}
+
+// AddWorktree creates a new worktree at path with a new branch pointing to the current HEAD.
+// go-git doesn't support linked worktrees, so this and the other worktree operations use the git CLI.
+func (r *Repo) AddWorktree(path, branch string) error {
+	if _, err := r.runGit(r.path, "worktree", "add", "-b", branch, path, "HEAD"); err != nil {
+		return fmt.Errorf("add worktree: %w", err)
+	}
+	return nil
+}
+
+// RemoveWorktree removes the worktree at path, discarding any uncommitted changes in it.
+func (r *Repo) RemoveWorktree(path string) error {
+	if _, err := r.runGit(r.path, "worktree", "remove", "--force", path); err != nil {
+		return fmt.Errorf("remove worktree: %w", err)
+	}
+	return nil
+}
+
+// DeleteBranch force-deletes a local branch.
+func (r *Repo) DeleteBranch(name string) error {
+	if _, err := r.runGit(r.path, "branch", "-D", name); err != nil {
+		return fmt.Errorf("delete branch: %w", err)
+	}
+	return nil
+}
+
+// CommitAll stages and commits all changes in the worktree at dir.
+// returns false if there was nothing to commit.
+func (r *Repo) CommitAll(dir, msg string) (bool, error) {
+	status, err := r.runGit(dir, "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("get status: %w", err)
+	}
+	if strings.TrimSpace(status) == "" {
+		return false, nil
+	}
+	if _, err := r.runGit(dir, "add", "-A"); err != nil {
+		return false, fmt.Errorf("stage changes: %w", err)
+	}
+	if _, err := r.runGit(dir, "commit", "-m", msg); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+	return true, nil
+}
+
+// MergeBranch merges branch into the current branch with a merge commit.
+// on conflicts the merge is aborted and *MergeConflictError is returned.
+func (r *Repo) MergeBranch(branch, msg string) error {
+	_, mergeErr := r.runGit(r.path, "merge", "--no-ff", "-m", msg, branch)
+	if mergeErr == nil {
+		return nil
+	}
+
+	conflicts, err := r.runGit(r.path, "diff", "--name-only", "--diff-filter=U")
+	if err != nil || strings.TrimSpace(conflicts) == "" {
+		return fmt.Errorf("merge %s: %w", branch, mergeErr)
+	}
+
+	if _, err := r.runGit(r.path, "merge", "--abort"); err != nil {
+		return fmt.Errorf("abort merge of %s: %w", branch, err)
+	}
+	return &MergeConflictError{Branch: branch, Files: strings.Fields(conflicts)}
+}
+
+// runGit runs git CLI with args in dir and returns its stdout.
+// commit identity follows getAuthor so CLI commits match the ones made via go-git.
+func (r *Repo) runGit(dir string, args ...string) (string, error) {
+	author := r.getAuthor()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+author.Name, "GIT_AUTHOR_EMAIL="+author.Email,
+		"GIT_COMMITTER_NAME="+author.Name, "GIT_COMMITTER_EMAIL="+author.Email,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && msg != "" {
+			return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return stdout.String(), fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
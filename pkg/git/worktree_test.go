@@ -0,0 +1,93 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepo_Worktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git CLI not available")
+	}
+
+	t.Run("add, commit, merge and remove", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		repo, err := Open(dir)
+		require.NoError(t, err)
+
+		wtDir := filepath.Join(t.TempDir(), "wt")
+		require.NoError(t, repo.AddWorktree(wtDir, "task-1"))
+		assert.True(t, repo.BranchExists("task-1"))
+
+		// nothing to commit in a fresh worktree
+		committed, err := repo.CommitAll(wtDir, "noop")
+		require.NoError(t, err)
+		assert.False(t, committed)
+
+		require.NoError(t, os.WriteFile(filepath.Join(wtDir, "feature.go"), []byte("package main\n"), 0o600))
+		committed, err = repo.CommitAll(wtDir, "add feature")
+		require.NoError(t, err)
+		assert.True(t, committed)
+
+		require.NoError(t, repo.MergeBranch("task-1", "merge task 1"))
+		_, err = os.Stat(filepath.Join(dir, "feature.go"))
+		require.NoError(t, err, "merged file should be in main worktree")
+
+		require.NoError(t, repo.RemoveWorktree(wtDir))
+		_, err = os.Stat(wtDir)
+		assert.True(t, os.IsNotExist(err))
+
+		require.NoError(t, repo.DeleteBranch("task-1"))
+		assert.False(t, repo.BranchExists("task-1"))
+	})
+
+	t.Run("merge conflict is aborted and reported", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		repo, err := Open(dir)
+		require.NoError(t, err)
+
+		wtDir := filepath.Join(t.TempDir(), "wt")
+		require.NoError(t, repo.AddWorktree(wtDir, "task-2"))
+		require.NoError(t, os.WriteFile(filepath.Join(wtDir, "README.md"), []byte("# from task\n"), 0o600))
+		_, err = repo.CommitAll(wtDir, "task change")
+		require.NoError(t, err)
+
+		// conflicting change on the main branch
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# from main\n"), 0o600))
+		_, err = repo.CommitAll(dir, "main change")
+		require.NoError(t, err)
+
+		err = repo.MergeBranch("task-2", "merge task 2")
+		require.Error(t, err)
+		var conflict *MergeConflictError
+		require.True(t, errors.As(err, &conflict))
+		assert.Equal(t, "task-2", conflict.Branch)
+		assert.Equal(t, []string{"README.md"}, conflict.Files)
+		assert.Contains(t, err.Error(), "merge conflict merging task-2: README.md")
+
+		// merge aborted, main content preserved and tree clean
+		data, err := os.ReadFile(filepath.Join(dir, "README.md")) //nolint:gosec // test
+		require.NoError(t, err)
+		assert.Equal(t, "# from main\n", string(data))
+		dirty, err := repo.IsDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty)
+	})
+
+	t.Run("add fails for existing branch", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		repo, err := Open(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.AddWorktree(filepath.Join(t.TempDir(), "wt1"), "dup"))
+		err = repo.AddWorktree(filepath.Join(t.TempDir(), "wt2"), "dup")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "add worktree")
+	})
+}
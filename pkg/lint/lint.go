@@ -0,0 +1,267 @@
+// Package lint checks plan files for format problems that would otherwise show up only at runtime,
+// e.g. task headers the runner doesn't recognize or checkboxes it doesn't track, and fixes the mechanical ones.
+// plans are parsed with the plan package shared by the task runner and the web dashboard.
+package lint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// Severity of a lint issue.
+type Severity string
+
+// severity constants.
+const (
+	SeverityError   Severity = "error"   // the plan doesn't run as intended
+	SeverityWarning Severity = "warning" // the plan runs, but something is likely a mistake
+)
+
+// rule names reported with issues.
+const (
+	RuleTaskHeader      = "task-header"
+	RuleCheckbox        = "checkbox"
+	RuleCheckboxOutside = "checkbox-outside-task"
+	RuleDuplicateTask   = "duplicate-task"
+	RuleTaskNumbering   = "task-numbering"
+	RuleDependency      = "dependency"
+	RuleEmptyTask       = "empty-task"
+	RuleNoTasks         = "no-tasks"
+	RuleNoTitle         = "no-title"
+	RuleNoValidation    = "no-validation"
+)
+
+// Issue is a problem found in a plan.
+type Issue struct {
+	Line     int      `json:"line"` // 1-based line number, 0 for problems of the whole plan
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+	Fixable  bool     `json:"fixable,omitempty"` // Fix resolves the issue
+}
+
+// String formats the issue as "line: severity: message (rule)", the line is omitted for plan-wide issues.
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s (%s)", i.Severity, i.Message, i.Rule)
+	}
+	return fmt.Sprintf("%d: %s: %s (%s)", i.Line, i.Severity, i.Message, i.Rule)
+}
+
+// loose patterns of task headers and checkboxes the plan parser doesn't recognize
+var (
+	looseTaskHeaderRe = regexp.MustCompile(`(?i)^#{1,6}\s*(task|iteration)\s*(\d+)\s*[:.)\-–—]?\s*(.*)$`)
+	looseCheckboxRe   = regexp.MustCompile(`^(\s*)[-*+]\s*\[\s*([ xX~-]?)\s*\]\s*(.*)$`)
+)
+
+// task is a task section found while checking a plan.
+type task struct {
+	line       int // 1-based line of the header
+	number     int
+	depends    string // raw dependency list, empty if not annotated
+	checkboxes int
+}
+
+// Check returns problems found in plan content, ordered by line.
+// the plan is parsed with the grammar, the same way the runner and the dashboard parse it. with the default
+// grammar, lines it doesn't recognize are matched against loose patterns of task headers and checkboxes.
+func Check(content string, g plan.Grammar) []Issue {
+	doc := g.Parse(content)
+	tasks := make([]task, len(doc.Tasks))
+	for i, t := range doc.Tasks {
+		tasks[i] = task{line: t.Line + 1, number: t.Number, depends: t.Depends, checkboxes: len(t.Checkboxes())}
+	}
+
+	var issues []Issue
+	for i, line := range strings.Split(content, "\n") {
+		num, trimmed := i+1, strings.TrimSpace(line)
+		kind := doc.Kind(i)
+		switch kind {
+		case plan.LineTask, plan.LineCode, plan.LineNote, plan.LineIgnored, plan.LineFrontmatter:
+			continue
+		}
+		if !g.IsDefault() && kind != plan.LineItem {
+			continue // loose patterns are variants of the default syntax
+		}
+
+		if m := looseTaskHeaderRe.FindStringSubmatch(trimmed); m != nil {
+			issues = append(issues, Issue{Line: num, Severity: SeverityError, Rule: RuleTaskHeader, Fixable: true,
+				Message: fmt.Sprintf("task header %q is not recognized, use %q", trimmed, fixHeader(m))})
+			continue
+		}
+
+		if kind != plan.LineItem {
+			m := looseCheckboxRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			issues = append(issues, Issue{Line: num, Severity: SeverityError, Rule: RuleCheckbox, Fixable: true,
+				Message: fmt.Sprintf("checkbox %q is not recognized, use %q", trimmed, strings.TrimSpace(fixCheckbox(m)))})
+			if idx := taskAt(doc, i); idx >= 0 {
+				tasks[idx].checkboxes++
+				continue
+			}
+		}
+		if taskAt(doc, i) < 0 {
+			issues = append(issues, Issue{Line: num, Severity: SeverityWarning, Rule: RuleCheckboxOutside,
+				Message: "checkbox outside of task sections, not shown in the dashboard and not part of any task"})
+		}
+	}
+
+	issues = append(issues, checkTasks(tasks, g)...)
+	if doc.Title == "" {
+		issues = append(issues, Issue{Severity: SeverityWarning, Rule: RuleNoTitle, Message: "plan has no \"# Title\" header"})
+	}
+	if doc.Section("Validation Commands") == nil {
+		issues = append(issues, Issue{Severity: SeverityWarning, Rule: RuleNoValidation,
+			Message: "plan has no \"## Validation Commands\" section, tasks are not validated between iterations"})
+	}
+
+	slices.SortStableFunc(issues, func(a, b Issue) int { return a.Line - b.Line })
+	return issues
+}
+
+// taskAt returns the index of the task section containing the line with 0-based index i, -1 if none.
+func taskAt(doc *plan.Document, i int) int {
+	return slices.IndexFunc(doc.Tasks, func(t *plan.Task) bool { return i > t.Line && i < t.End })
+}
+
+// checkTasks checks task numbers, dependencies and task sections without checkboxes.
+func checkTasks(tasks []task, g plan.Grammar) []Issue {
+	if len(tasks) == 0 {
+		msg := "plan has no \"### Task N: title\" sections"
+		if !g.IsDefault() {
+			msg = fmt.Sprintf("plan has no task sections matching %q", g.TaskHeaderPattern())
+		}
+		return []Issue{{Severity: SeverityError, Rule: RuleNoTasks, Message: msg}}
+	}
+
+	var issues []Issue
+	seen := make(map[int]bool, len(tasks))
+	for i, t := range tasks {
+		switch {
+		case seen[t.number]:
+			issues = append(issues, Issue{Line: t.line, Severity: SeverityError, Rule: RuleDuplicateTask, Fixable: true,
+				Message: fmt.Sprintf("duplicate task number %d", t.number)})
+		case t.number != i+1:
+			issues = append(issues, Issue{Line: t.line, Severity: SeverityWarning, Rule: RuleTaskNumbering, Fixable: true,
+				Message: fmt.Sprintf("task %d is task number %d in the plan, tasks are expected to be numbered 1..N", t.number, i+1)})
+		}
+		seen[t.number] = true
+
+		if t.checkboxes == 0 {
+			issues = append(issues, Issue{Line: t.line, Severity: SeverityWarning, Rule: RuleEmptyTask,
+				Message: fmt.Sprintf("task %d has no checkboxes and is treated as completed", t.number)})
+		}
+	}
+
+	for _, t := range tasks {
+		if t.depends == "" || strings.EqualFold(t.depends, "none") {
+			continue
+		}
+		for part := range strings.SplitSeq(t.depends, ",") {
+			part = strings.TrimSpace(part)
+			dep, err := strconv.Atoi(part)
+			switch {
+			case err != nil:
+				issues = append(issues, Issue{Line: t.line, Severity: SeverityError, Rule: RuleDependency,
+					Message: fmt.Sprintf("task %d: invalid dependency %q", t.number, part)})
+			case dep == t.number || !seen[dep]:
+				issues = append(issues, Issue{Line: t.line, Severity: SeverityError, Rule: RuleDependency,
+					Message: fmt.Sprintf("task %d depends on unknown task %d", t.number, dep)})
+			}
+		}
+	}
+	return issues
+}
+
+// Fix applies mechanical fixes to plan content: normalizes task headers and checkbox syntax of the
+// default grammar, and renumbers tasks 1..N in plan order, updating "(depends: ...)" references.
+// returns the fixed content, unchanged if there was nothing to fix.
+func Fix(content string, g plan.Grammar) string {
+	if g.IsDefault() {
+		content = fixSyntax(content)
+	}
+	doc := g.Parse(content)
+	doc.RenumberTasks()
+	return doc.String()
+}
+
+// fixSyntax rewrites lines matching loose patterns to the default task header and checkbox syntax.
+func fixSyntax(content string) string {
+	doc := plan.Parse(content)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		switch doc.Kind(i) {
+		case plan.LineTask, plan.LineItem, plan.LineCode, plan.LineNote, plan.LineIgnored, plan.LineFrontmatter:
+			continue
+		}
+		if m := looseTaskHeaderRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			lines[i] = fixHeader(m)
+			continue
+		}
+		if m := looseCheckboxRe.FindStringSubmatch(line); m != nil {
+			lines[i] = fixCheckbox(m)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fixHeader returns the canonical task header for a loose header match.
+func fixHeader(m []string) string {
+	kind := "Task"
+	if strings.EqualFold(m[1], "iteration") {
+		kind = "Iteration"
+	}
+	n, _ := strconv.Atoi(m[2])
+	return strings.TrimSpace(fmt.Sprintf("### %s %d: %s", kind, n, m[3]))
+}
+
+// fixCheckbox returns the canonical checkbox for a loose checkbox match, keeping indentation.
+func fixCheckbox(m []string) string {
+	mark := strings.ToLower(m[2])
+	if mark == "" {
+		mark = " "
+	}
+	return strings.TrimRight(fmt.Sprintf("%s- [%s] %s", m[1], mark, m[3]), " ")
+}
+
+// HasErrors returns true if any issue has error severity.
+func HasErrors(issues []Issue) bool {
+	return slices.ContainsFunc(issues, func(i Issue) bool { return i.Severity == SeverityError })
+}
+
+// CheckFile reads and checks the plan file at path.
+func CheckFile(path string, g plan.Grammar) ([]Issue, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // plan file path from CLI args
+	if err != nil {
+		return nil, fmt.Errorf("read plan: %w", err)
+	}
+	return Check(string(data), g), nil
+}
+
+// FixFile fixes the plan file at path in place and returns true if it was changed.
+func FixFile(path string, g plan.Grammar) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, fmt.Errorf("stat plan: %w", err)
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // plan file path from CLI args
+	if err != nil {
+		return false, fmt.Errorf("read plan: %w", err)
+	}
+	fixed := Fix(string(data), g)
+	if fixed == string(data) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(fixed), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("write plan: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,190 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+const validPlan = `# Plan: Add caching
+
+## Validation Commands
+- ` + "`go test ./...`" + `
+
+### Task 1: Add cache
+- [x] add cache package
+- [ ] write tests
+
+### Task 2: Wire cache (depends: 1)
+- [~] wire into handlers
+`
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Issue
+	}{
+		{name: "valid plan", content: validPlan},
+		{
+			name:    "unrecognized task headers",
+			content: "# Plan\n## Validation Commands\n## Task 1\n- [ ] a\n### Task 2 - Second\n- [ ] b\n### Task 3: Third\n- [ ] c\n",
+			want: []Issue{
+				{Line: 3, Severity: SeverityError, Rule: RuleTaskHeader, Fixable: true,
+					Message: `task header "## Task 1" is not recognized, use "### Task 1:"`},
+				{Line: 4, Severity: SeverityWarning, Rule: RuleCheckboxOutside,
+					Message: "checkbox outside of task sections, not shown in the dashboard and not part of any task"},
+				{Line: 5, Severity: SeverityError, Rule: RuleTaskHeader, Fixable: true,
+					Message: `task header "### Task 2 - Second" is not recognized, use "### Task 2: Second"`},
+				{Line: 6, Severity: SeverityWarning, Rule: RuleCheckboxOutside,
+					Message: "checkbox outside of task sections, not shown in the dashboard and not part of any task"},
+				{Line: 7, Severity: SeverityWarning, Rule: RuleTaskNumbering, Fixable: true,
+					Message: "task 3 is task number 1 in the plan, tasks are expected to be numbered 1..N"},
+			},
+		},
+		{
+			name:    "malformed checkboxes",
+			content: "# Plan\n## Validation Commands\n### Task 1: A\n- [] a\n* [ ] b\n-[X] c\n- [ ] d\n",
+			want: []Issue{
+				{Line: 4, Severity: SeverityError, Rule: RuleCheckbox, Fixable: true, Message: `checkbox "- [] a" is not recognized, use "- [ ] a"`},
+				{Line: 5, Severity: SeverityError, Rule: RuleCheckbox, Fixable: true, Message: `checkbox "* [ ] b" is not recognized, use "- [ ] b"`},
+				{Line: 6, Severity: SeverityError, Rule: RuleCheckbox, Fixable: true, Message: `checkbox "-[X] c" is not recognized, use "- [x] c"`},
+			},
+		},
+		{
+			name:    "duplicate numbers, dependencies and empty task",
+			content: "# Plan\n## Validation Commands\n### Task 1: A\n- [ ] a\n### Task 1: B (depends: 5)\n- [ ] b\n### Task 3: C (depends: x)\n",
+			want: []Issue{
+				{Line: 5, Severity: SeverityError, Rule: RuleDuplicateTask, Fixable: true, Message: "duplicate task number 1"},
+				{Line: 5, Severity: SeverityError, Rule: RuleDependency, Message: "task 1 depends on unknown task 5"},
+				{Line: 7, Severity: SeverityWarning, Rule: RuleEmptyTask, Message: "task 3 has no checkboxes and is treated as completed"},
+				{Line: 7, Severity: SeverityError, Rule: RuleDependency, Message: `task 3: invalid dependency "x"`},
+			},
+		},
+		{
+			name:    "code blocks ignored",
+			content: "# Plan\n## Validation Commands\n### Task 1: A\n- [ ] a\n```\n## Task 2\n- [] b\n```\n",
+		},
+		{
+			name: "manual steps and ignore blocks skipped",
+			content: "# Plan\n## Validation Commands\n### Task 1: A\n- [ ] a\n<!-- ralphex:ignore -->\n- [] b\n" +
+				"<!-- /ralphex:ignore -->\n## Manual Steps\n- [ ] c\n* [ ] d\n",
+		},
+		{
+			name:    "frontmatter skipped",
+			content: "---\nagents: [a, b]\n# comment\n---\n" + validPlan,
+		},
+		{
+			name:    "missing title, validation and tasks",
+			content: "some notes\n- [ ] todo\n",
+			want: []Issue{
+				{Severity: SeverityError, Rule: RuleNoTasks, Message: `plan has no "### Task N: title" sections`},
+				{Severity: SeverityWarning, Rule: RuleNoTitle, Message: `plan has no "# Title" header`},
+				{Severity: SeverityWarning, Rule: RuleNoValidation,
+					Message: `plan has no "## Validation Commands" section, tasks are not validated between iterations`},
+				{Line: 2, Severity: SeverityWarning, Rule: RuleCheckboxOutside,
+					Message: "checkbox outside of task sections, not shown in the dashboard and not part of any task"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Check(tc.content, plan.Grammar{}))
+		})
+	}
+}
+
+func TestFix(t *testing.T) {
+	content := `# Plan
+## Validation Commands
+## Task 1 - Base
+- [] create base
+### task 3: API (depends: 1)
+* [ ] add client
+  -[x] nested done
+### Task 3: Handlers (depends: 1, 3)
+- [ ] add handlers
+`
+	fixed := Fix(content, plan.Grammar{})
+	assert.Equal(t, `# Plan
+## Validation Commands
+### Task 1: Base
+- [ ] create base
+### Task 2: API (depends: 1)
+- [ ] add client
+  - [x] nested done
+### Task 3: Handlers (depends: 1, 2)
+- [ ] add handlers
+`, fixed)
+	assert.Empty(t, Check(fixed, plan.Grammar{}))
+	assert.Equal(t, fixed, Fix(fixed, plan.Grammar{}), "fix is idempotent")
+}
+
+func TestCheck_Fix_CustomGrammar(t *testing.T) {
+	g, err := plan.NewGrammar(`^##\s+Step\s+(?P<num>\d+)\s+-\s+(?P<title>.*)$`, "")
+	require.NoError(t, err)
+
+	content := `# Plan
+## Validation Commands
+## Step 1 - Base
+- [ ] create base
+## Step 3 - API (depends: 1)
+- [ ] add client
+### Task 5: not a task in this grammar
+`
+	issues := Check(content, g)
+	assert.Equal(t, []Issue{{Line: 5, Severity: SeverityWarning, Rule: RuleTaskNumbering, Fixable: true,
+		Message: "task 3 is task number 2 in the plan, tasks are expected to be numbered 1..N"}}, issues,
+		"default syntax is not suggested")
+
+	fixed := Fix(content, g)
+	assert.Equal(t, strings.Replace(content, "Step 3", "Step 2", 1), fixed)
+	assert.Empty(t, Check(fixed, g))
+
+	issues = Check("# Plan\n## Validation Commands\n### Task 1: A\n", g)
+	require.Len(t, issues, 1)
+	assert.Equal(t, `plan has no task sections matching "^##\\s+Step\\s+(?P<num>\\d+)\\s+-\\s+(?P<title>.*)$"`, issues[0].Message)
+}
+
+func TestHasErrors(t *testing.T) {
+	assert.False(t, HasErrors(nil))
+	assert.False(t, HasErrors([]Issue{{Severity: SeverityWarning}}))
+	assert.True(t, HasErrors([]Issue{{Severity: SeverityWarning}, {Severity: SeverityError}}))
+}
+
+func TestIssue_String(t *testing.T) {
+	assert.Equal(t, "3: error: bad header (task-header)", Issue{Line: 3, Severity: SeverityError, Rule: RuleTaskHeader,
+		Message: "bad header"}.String())
+	assert.Equal(t, "warning: no title (no-title)", Issue{Severity: SeverityWarning, Rule: RuleNoTitle, Message: "no title"}.String())
+}
+
+func TestCheckFile_FixFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Plan\n## Validation Commands\n## Task 1: A\n- [ ] a\n"), 0o600))
+
+	issues, err := CheckFile(path, plan.Grammar{})
+	require.NoError(t, err)
+	require.Len(t, issues, 3, "no tasks, bad header and checkbox outside of tasks")
+	assert.True(t, HasErrors(issues))
+
+	changed, err := FixFile(path, plan.Grammar{})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	issues, err = CheckFile(path, plan.Grammar{})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	changed, err = FixFile(path, plan.Grammar{})
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = CheckFile(filepath.Join(t.TempDir(), "missing.md"), plan.Grammar{})
+	require.Error(t, err)
+}
@@ -0,0 +1,111 @@
+// Package notify sends notifications about run completion, failures and pending questions
+// to webhooks, local commands and the terminal.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Event is the type of run event a notification is sent for.
+type Event string
+
+// event types
+const (
+	EventCompleted     Event = "completed"      // run finished successfully
+	EventFailed        Event = "failed"         // run stopped with an error
+	EventQuestion      Event = "question"       // run waits for an answer to a question
+	EventMaxIterations Event = "max_iterations" // run used all iterations without completing
+)
+
+// AllEvents lists all event types, sinks subscribe to all of them unless configured otherwise.
+var AllEvents = []Event{EventCompleted, EventFailed, EventQuestion, EventMaxIterations}
+
+// sendTimeout limits delivery of a single notification to a sink.
+const sendTimeout = 30 * time.Second
+
+// Message is a notification about a run event.
+type Message struct {
+	Event    Event     `json:"event"`
+	Text     string    `json:"text"`
+	PlanFile string    `json:"plan_file,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Mode     string    `json:"mode,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Sink delivers notifications to a destination.
+type Sink interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// route is a sink with the events it is subscribed to.
+type route struct {
+	name   string
+	sink   Sink
+	events []Event
+}
+
+// Notifier delivers messages to the sinks subscribed to the message event.
+// the zero value is a notifier without sinks.
+type Notifier struct {
+	routes []route
+}
+
+// ParseEvents converts event names to events, empty list means all events.
+func ParseEvents(names []string) ([]Event, error) {
+	if len(names) == 0 {
+		return AllEvents, nil
+	}
+	events := make([]Event, 0, len(names))
+	for _, name := range names {
+		ev := Event(strings.TrimSpace(name))
+		if !slices.Contains(AllEvents, ev) {
+			return nil, fmt.Errorf("unknown notification event %q", name)
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// Add subscribes the sink to the given events. name identifies the sink in delivery errors.
+func (n *Notifier) Add(name string, sink Sink, events []Event) {
+	n.routes = append(n.routes, route{name: name, sink: sink, events: events})
+}
+
+// Enabled returns true if any sink is subscribed to the event.
+func (n *Notifier) Enabled(ev Event) bool {
+	for _, r := range n.routes {
+		if slices.Contains(r.events, ev) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify sends the message to all sinks subscribed to its event.
+// all sinks are tried, delivery errors are combined.
+func (n *Notifier) Notify(ctx context.Context, msg Message) error {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+
+	var errs []error
+	for _, r := range n.routes {
+		if !slices.Contains(r.events, msg.Event) {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := r.sink.Send(sendCtx, msg)
+		cancel()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", r.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
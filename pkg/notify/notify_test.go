@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink records sent messages and fails with err if set.
+type recordingSink struct {
+	msgs []Message
+	err  error
+}
+
+func (s *recordingSink) Send(_ context.Context, msg Message) error {
+	s.msgs = append(s.msgs, msg)
+	return s.err
+}
+
+func TestParseEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		want    []Event
+		wantErr string
+	}{
+		{name: "empty means all", want: AllEvents},
+		{name: "subset", names: []string{"failed", " max_iterations "}, want: []Event{EventFailed, EventMaxIterations}},
+		{name: "unknown", names: []string{"completed", "started"}, wantErr: `unknown notification event "started"`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseEvents(tc.names)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	all, failures := &recordingSink{}, &recordingSink{}
+	var n Notifier
+	n.Add("all", all, AllEvents)
+	n.Add("failures", failures, []Event{EventFailed, EventMaxIterations})
+
+	require.NoError(t, n.Notify(context.Background(), Message{Event: EventCompleted, Text: "done"}))
+	require.NoError(t, n.Notify(context.Background(), Message{Event: EventFailed, Text: "broken"}))
+
+	require.Len(t, all.msgs, 2)
+	assert.Equal(t, EventCompleted, all.msgs[0].Event)
+	assert.False(t, all.msgs[0].Time.IsZero(), "time is set")
+	require.Len(t, failures.msgs, 1)
+	assert.Equal(t, "broken", failures.msgs[0].Text)
+
+	assert.True(t, n.Enabled(EventQuestion))
+	assert.False(t, (&Notifier{}).Enabled(EventQuestion))
+}
+
+func TestNotifier_Notify_Errors(t *testing.T) {
+	broken, working := &recordingSink{err: errors.New("connection refused")}, &recordingSink{}
+	var n Notifier
+	n.Add("webhook", broken, AllEvents)
+	n.Add("command", working, AllEvents)
+
+	err := n.Notify(context.Background(), Message{Event: EventFailed})
+	require.Error(t, err)
+	assert.Equal(t, "notify webhook: connection refused", err.Error())
+	assert.Len(t, working.msgs, 1, "other sinks still get the message")
+}
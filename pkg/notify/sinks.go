@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// maxErrorOutput limits command output and response body included in delivery errors.
+const maxErrorOutput = 500
+
+// Webhook posts messages as JSON to a URL.
+type Webhook struct {
+	URL    string
+	Client *http.Client // nil uses http.DefaultClient, the request is bound by the notifier timeout
+}
+
+// Send posts the message to the webhook URL, any non-2xx response is an error.
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorOutput))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body) // drain to reuse the connection
+	return nil
+}
+
+// Command runs a shell command for each message. The message is passed in RALPHEX_* environment
+// variables, RALPHEX_NOTIFICATION has the whole message as JSON.
+type Command struct {
+	Command string
+}
+
+// Send runs the command, non-zero exit is an error.
+func (c *Command) Send(ctx context.Context, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	env := []string{
+		"RALPHEX_EVENT=" + string(msg.Event),
+		"RALPHEX_MESSAGE=" + msg.Text,
+		"RALPHEX_PLAN_FILE=" + msg.PlanFile,
+		"RALPHEX_BRANCH=" + msg.Branch,
+		"RALPHEX_MODE=" + msg.Mode,
+		"RALPHEX_ERROR=" + msg.Error,
+		"RALPHEX_NOTIFICATION=" + string(data),
+	}
+	output, err := executor.RunShell(ctx, "", c.Command, env)
+	if err != nil {
+		if output = strings.TrimSpace(output); len(output) > maxErrorOutput {
+			output = output[:maxErrorOutput] + "..."
+		}
+		return fmt.Errorf("run notify command: %w: %s", err, output)
+	}
+	return nil
+}
+
+// Bell rings the terminal bell and sends an OSC 9 desktop notification, supported by iTerm2,
+// Windows Terminal, kitty and others. Terminals without OSC 9 support ignore the sequence.
+type Bell struct {
+	W io.Writer
+}
+
+// Send writes the bell and the notification escape sequence.
+func (b *Bell) Send(_ context.Context, msg Message) error {
+	text := "ralphex: " + msg.Text
+	// control characters would terminate or corrupt the escape sequence
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, text)
+	if _, err := fmt.Fprintf(b.W, "\a\x1b]9;%s\x07", text); err != nil {
+		return fmt.Errorf("write bell: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	msg := Message{Event: EventFailed, Text: "run failed", PlanFile: "docs/plans/feature.md", Branch: "feature",
+		Mode: "full", Error: "max iterations reached", Time: time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)}
+
+	t.Run("posts message as json", func(t *testing.T) {
+		var got Message
+		var contentType string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			contentType = r.Header.Get("Content-Type")
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, (&Webhook{URL: srv.URL}).Send(context.Background(), msg))
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, msg, got)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		err := (&Webhook{URL: srv.URL, Client: srv.Client()}).Send(context.Background(), msg)
+		require.Error(t, err)
+		assert.Equal(t, "webhook returned 401 Unauthorized: bad token", err.Error())
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		srv.Close()
+		err := (&Webhook{URL: srv.URL}).Send(context.Background(), msg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "post webhook")
+	})
+}
+
+func TestCommand_Send(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	msg := Message{Event: EventQuestion, Text: "which cache backend?", Branch: "feature"}
+
+	t.Run("passes message in env", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "out.txt")
+		cmd := &Command{Command: `echo "$RALPHEX_EVENT|$RALPHEX_MESSAGE|$RALPHEX_BRANCH" > ` + out +
+			`; echo "$RALPHEX_NOTIFICATION" >> ` + out}
+		require.NoError(t, cmd.Send(context.Background(), msg))
+
+		data, err := os.ReadFile(out) //nolint:gosec // test file
+		require.NoError(t, err)
+		lines := bytes.SplitN(data, []byte("\n"), 2)
+		assert.Equal(t, "question|which cache backend?|feature", string(lines[0]))
+		var got Message
+		require.NoError(t, json.Unmarshal(lines[1], &got))
+		assert.Equal(t, msg, got)
+	})
+
+	t.Run("non-zero exit", func(t *testing.T) {
+		err := (&Command{Command: "echo no route; exit 1"}).Send(context.Background(), msg)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exit status 1")
+		assert.Contains(t, err.Error(), "no route")
+	})
+}
+
+func TestBell_Send(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, (&Bell{W: &buf}).Send(context.Background(), Message{Text: "run failed:\n\x1b]bad"}))
+	assert.Equal(t, "\a\x1b]9;ralphex: run failed:  ]bad\x07", buf.String())
+}
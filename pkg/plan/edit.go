@@ -0,0 +1,177 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Load reads and parses the plan file at path with the default grammar.
+func Load(path string) (*Document, error) {
+	return Grammar{}.Load(path)
+}
+
+// Save writes the document to path, keeping permissions of an existing file.
+func (d *Document) Save(path string) error {
+	perm := os.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("stat plan file: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(d.String()), perm); err != nil {
+		return fmt.Errorf("write plan file: %w", err)
+	}
+	return nil
+}
+
+// CheckItem marks the checkbox with 0-based index among all checkboxes of the task (see Task.Checkboxes) as done.
+func (d *Document) CheckItem(task, index int) error {
+	t := d.Task(task)
+	if t == nil {
+		return fmt.Errorf("task %d not found in plan", task)
+	}
+	items := t.Checkboxes()
+	if index < 0 || index >= len(items) {
+		return fmt.Errorf("task %d has no item %d", task, index)
+	}
+	d.setState(items[index], StateDone)
+	d.parse()
+	return nil
+}
+
+// MarkTaskBlocked marks open checkboxes of the task as blocked ("[~]") and adds a "> blocked: <reason>"
+// note under the task header. with a grammar accepting neither "~" nor "-" marks the checkboxes are marked done.
+func (d *Document) MarkTaskBlocked(task int, reason string) error {
+	t := d.Task(task)
+	if t == nil {
+		return fmt.Errorf("task %d not found in plan", task)
+	}
+	marked := 0
+	for _, it := range t.Checkboxes() {
+		if it.State == StateOpen {
+			d.setState(it, StateBlocked)
+			marked++
+		}
+	}
+	if marked == 0 {
+		return fmt.Errorf("task %d has no uncompleted items", task)
+	}
+	d.insertLines(t.Line+1, "", "> "+blockedNotePrefix+" "+reason)
+	return nil
+}
+
+// AppendNote adds a "> note" line at the end of the task section.
+func (d *Document) AppendNote(task int, note string) error {
+	t := d.Task(task)
+	if t == nil {
+		return fmt.Errorf("task %d not found in plan", task)
+	}
+	last := t.End - 1 // last non-blank line of the section
+	for last > t.Line && strings.TrimSpace(d.lines[last]) == "" {
+		last--
+	}
+	lines := []string{"> " + note}
+	if d.kinds[last] != LineNote {
+		lines = []string{"", "> " + note}
+	}
+	d.insertLines(last+1, lines...)
+	return nil
+}
+
+// RenumberTasks numbers tasks 1..N in document order and maps "(depends: ...)" references to the new numbers.
+// a reference to a duplicated number maps to its first task, unknown and invalid references are kept as is.
+func (d *Document) RenumberTasks() {
+	mapping := make(map[int]int, len(d.Tasks))
+	for i, t := range d.Tasks {
+		if _, ok := mapping[t.Number]; !ok {
+			mapping[t.Number] = i + 1
+		}
+	}
+
+	re := d.grammar.taskHeaderRe()
+	for i, t := range d.Tasks {
+		line := d.lines[t.Line]
+		header := strings.TrimSpace(line)
+		m := re.FindStringSubmatchIndex(header)
+		if m == nil {
+			continue
+		}
+		changed := false
+		_, numStart, numEnd := group(re, header, m, "num")
+		// the annotation follows the number, so it's replaced first and the number position stays valid
+		if deps := remapDepends(t.Depends, mapping); deps != t.Depends {
+			if dm := dependsRe.FindStringSubmatchIndex(header); dm != nil && dm[2] >= numEnd {
+				header, changed = header[:dm[2]]+deps+header[dm[3]:], true
+			}
+		}
+		if numStart >= 0 && t.Number != i+1 {
+			header, changed = header[:numStart]+strconv.Itoa(i+1)+header[numEnd:], true
+		}
+		if changed {
+			d.lines[t.Line] = line[:len(line)-len(strings.TrimLeft(line, " \t"))] + header
+		}
+	}
+	d.parse()
+}
+
+// remapDepends maps task numbers of a dependency list.
+func remapDepends(deps string, mapping map[int]int) string {
+	if deps == "" || strings.EqualFold(deps, "none") {
+		return deps
+	}
+	var res []string
+	for part := range strings.SplitSeq(deps, ",") {
+		part = strings.TrimSpace(part)
+		if n, err := strconv.Atoi(part); err == nil {
+			if mapped, ok := mapping[n]; ok {
+				part = strconv.Itoa(mapped)
+			}
+		}
+		res = append(res, part)
+	}
+	return strings.Join(res, ", ")
+}
+
+// stateMarks lists marks written for a state, the first one accepted by the checkbox pattern is used.
+var stateMarks = map[State][]string{
+	StateOpen:    {" ", ""},
+	StateDone:    {"x", "X"},
+	StateBlocked: {"~", "-", "x", "X"},
+}
+
+// setState rewrites the checkbox mark of an item line, the rest of the line is kept.
+// the line is left as is if the checkbox pattern accepts no mark for the state.
+func (d *Document) setState(it *Item, state State) {
+	re := d.grammar.checkboxRe()
+	line := d.lines[it.Line]
+	m := re.FindStringSubmatchIndex(line)
+	if m == nil {
+		return
+	}
+	_, start, end := group(re, line, m, "state")
+	if start < 0 {
+		return
+	}
+	for _, mark := range stateMarks[state] {
+		updated := line[:start] + mark + line[end:]
+		um := re.FindStringSubmatchIndex(updated)
+		if um == nil {
+			continue
+		}
+		got, _, _ := group(re, updated, um, "state")
+		if got == mark && (parseState(mark) == StateOpen) == (state == StateOpen) {
+			d.lines[it.Line] = updated
+			return
+		}
+	}
+}
+
+// insertLines inserts lines before the line with index at and parses the document again.
+func (d *Document) insertLines(at int, lines ...string) {
+	d.lines = slices.Insert(d.lines, at, lines...)
+	d.parse()
+}
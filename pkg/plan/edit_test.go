@@ -0,0 +1,114 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument_CheckItem(t *testing.T) {
+	d := Parse("### Task 1: A\n- [ ] first\n  - [ ]  nested  \n- [x] done\n")
+	require.NoError(t, d.CheckItem(1, 1))
+	assert.Equal(t, "### Task 1: A\n- [ ] first\n  - [x]  nested  \n- [x] done\n", d.String(), "only the mark changes")
+	assert.Equal(t, StateDone, d.Tasks[0].Checkboxes()[1].State)
+
+	require.NoError(t, d.CheckItem(1, 0))
+	assert.True(t, d.Tasks[0].Done())
+
+	require.EqualError(t, d.CheckItem(1, 3), "task 1 has no item 3")
+	require.EqualError(t, d.CheckItem(2, 0), "task 2 not found in plan")
+}
+
+func TestDocument_MarkTaskBlocked(t *testing.T) {
+	content := `# Plan
+### Task 1: Deploy
+- [x] write manifest
+- [ ] apply manifest
+  - [ ] verify rollout
+### Task 2: Docs
+- [ ] update readme
+`
+	d := Parse(content)
+	require.NoError(t, d.MarkTaskBlocked(1, "no cloud credentials"))
+	assert.Equal(t, `# Plan
+### Task 1: Deploy
+
+> blocked: no cloud credentials
+- [x] write manifest
+- [~] apply manifest
+  - [~] verify rollout
+### Task 2: Docs
+- [ ] update readme
+`, d.String())
+
+	task := d.Task(1)
+	assert.True(t, task.Done())
+	assert.True(t, task.Blocked())
+	assert.Equal(t, "no cloud credentials", task.BlockedReason())
+	assert.False(t, d.Task(2).Done())
+
+	require.EqualError(t, d.MarkTaskBlocked(1, "again"), "task 1 has no uncompleted items")
+	require.EqualError(t, Parse(content).MarkTaskBlocked(5, "x"), "task 5 not found in plan")
+}
+
+func TestDocument_AppendNote(t *testing.T) {
+	d := Parse("### Task 1: A\n- [ ] a\n\n### Task 2: B\n- [ ] b")
+	require.NoError(t, d.AppendNote(1, "needs review"))
+	require.NoError(t, d.AppendNote(1, "flaky test"))
+	require.NoError(t, d.AppendNote(2, "last task"))
+	assert.Equal(t, "### Task 1: A\n- [ ] a\n\n> needs review\n> flaky test\n\n### Task 2: B\n- [ ] b\n\n> last task", d.String())
+	assert.Equal(t, []string{"needs review", "flaky test"}, d.Task(1).Notes)
+
+	require.EqualError(t, d.AppendNote(3, "x"), "task 3 not found in plan")
+}
+
+func TestDocument_RenumberTasks(t *testing.T) {
+	d := Parse(`# Plan
+### Task 1: Base
+### Task 3: API (depends: 1)
+### Iteration 3: Handlers (depends: 1, 3, 7)
+### Task 5: Docs (depends: none)
+`)
+	d.RenumberTasks()
+	assert.Equal(t, `# Plan
+### Task 1: Base
+### Task 2: API (depends: 1)
+### Iteration 3: Handlers (depends: 1, 2, 7)
+### Task 4: Docs (depends: none)
+`, d.String())
+	assert.Equal(t, 4, d.Tasks[3].Number)
+
+	unchanged := "### Task 1:   A  \n"
+	d = Parse(unchanged)
+	d.RenumberTasks()
+	assert.Equal(t, unchanged, d.String(), "headers with correct numbers are kept as is")
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(path, []byte("### Task 1: A\n- [ ] a\n"), 0o640))
+
+	d, err := Load(path)
+	require.NoError(t, err)
+	require.NoError(t, d.CheckItem(1, 0))
+	require.NoError(t, d.Save(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "### Task 1: A\n- [x] a\n", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm(), "permissions kept")
+
+	newPath := filepath.Join(t.TempDir(), "new.md")
+	require.NoError(t, d.Save(newPath))
+	info, err = os.Stat(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.md"))
+	require.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// default patterns of task headers and checkboxes.
+const (
+	DefaultTaskHeaderPattern = `^###\s+(?:Task|Iteration)\s+(?P<num>\d+):\s*(?P<title>.*)$`
+	DefaultCheckboxPattern   = `^\s*-\s+\[(?P<state>[ xX~-])\]\s*(?P<text>.*)$`
+)
+
+var (
+	defaultTaskHeaderRe = regexp.MustCompile(DefaultTaskHeaderPattern)
+	defaultCheckboxRe   = regexp.MustCompile(DefaultCheckboxPattern)
+)
+
+// Grammar defines how task headers and checkboxes of a plan are recognized. the zero value is the
+// default grammar, "### Task N: title" (or "### Iteration N: title") headers and "- [ ] text" checkboxes.
+//
+// a task header pattern is matched against the trimmed line, the "num" group captures the task number
+// and the "title" group the title. without a "num" group, or if it doesn't capture a number, tasks are
+// numbered by their position in the plan; without a "title" group the whole line is the title.
+// a checkbox pattern is matched against the whole line, the "state" group captures the mark, " " or
+// an empty mark is open, "~" and "-" are blocked, anything else is done; the "text" group captures
+// the checkbox text, the rest of the line after the match is used without it. nesting of checkboxes
+// is taken from the line indentation.
+type Grammar struct {
+	taskHeader *regexp.Regexp
+	checkbox   *regexp.Regexp
+}
+
+// NewGrammar compiles task header and checkbox patterns, an empty pattern keeps the default.
+func NewGrammar(taskHeader, checkbox string) (Grammar, error) {
+	var g Grammar
+	if taskHeader != "" && taskHeader != DefaultTaskHeaderPattern {
+		re, err := regexp.Compile(taskHeader)
+		if err != nil {
+			return Grammar{}, fmt.Errorf("compile task header pattern: %w", err)
+		}
+		g.taskHeader = re
+	}
+	if checkbox != "" && checkbox != DefaultCheckboxPattern {
+		re, err := regexp.Compile(checkbox)
+		if err != nil {
+			return Grammar{}, fmt.Errorf("compile checkbox pattern: %w", err)
+		}
+		if re.SubexpIndex("state") < 0 {
+			return Grammar{}, errors.New("checkbox pattern has no \"state\" group, e.g. `\\[(?P<state>[ xX])\\]`")
+		}
+		g.checkbox = re
+	}
+	return g, nil
+}
+
+// IsDefault returns true for the default grammar.
+func (g Grammar) IsDefault() bool {
+	return g.taskHeader == nil && g.checkbox == nil
+}
+
+// TaskHeaderPattern returns the pattern of task headers.
+func (g Grammar) TaskHeaderPattern() string {
+	return g.taskHeaderRe().String()
+}
+
+// CheckboxPattern returns the pattern of checkboxes.
+func (g Grammar) CheckboxPattern() string {
+	return g.checkboxRe().String()
+}
+
+// Parse parses plan content with the grammar, see the package level Parse.
+func (g Grammar) Parse(content string) *Document {
+	d := &Document{grammar: g, lines: strings.Split(content, "\n")}
+	d.parse()
+	return d
+}
+
+// Load reads and parses the plan file at path with the grammar.
+func (g Grammar) Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // plan file path from CLI args or config
+	if err != nil {
+		return nil, fmt.Errorf("read plan file: %w", err)
+	}
+	return g.Parse(string(data)), nil
+}
+
+func (g Grammar) taskHeaderRe() *regexp.Regexp {
+	if g.taskHeader == nil {
+		return defaultTaskHeaderRe
+	}
+	return g.taskHeader
+}
+
+func (g Grammar) checkboxRe() *regexp.Regexp {
+	if g.checkbox == nil {
+		return defaultCheckboxRe
+	}
+	return g.checkbox
+}
+
+// group returns the text of the named group of a submatch index and its position, start is -1
+// if the pattern has no such group or the group didn't participate in the match.
+func group(re *regexp.Regexp, s string, m []int, name string) (text string, start, end int) {
+	idx := re.SubexpIndex(name)
+	if idx < 0 || m[2*idx] < 0 {
+		return "", -1, -1
+	}
+	start, end = m[2*idx], m[2*idx+1]
+	return s[start:end], start, end
+}
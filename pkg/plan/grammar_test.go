@@ -0,0 +1,123 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGrammar(t *testing.T) {
+	tests := []struct {
+		name       string
+		taskHeader string
+		checkbox   string
+		wantErr    string
+		wantIsDef  bool
+	}{
+		{name: "empty patterns", wantIsDef: true},
+		{name: "default patterns", taskHeader: DefaultTaskHeaderPattern, checkbox: DefaultCheckboxPattern, wantIsDef: true},
+		{name: "custom task header", taskHeader: `^##\s+Step\s+(?P<num>\d+)\s+-\s+(?P<title>.*)$`},
+		{name: "custom checkbox", checkbox: `^\s*\*\s+\((?P<state>[ x])\)\s*(?P<text>.*)$`},
+		{name: "invalid task header", taskHeader: `^(## Step`, wantErr: "compile task header pattern: error parsing regexp"},
+		{name: "invalid checkbox", checkbox: `[`, wantErr: "compile checkbox pattern: error parsing regexp"},
+		{name: "checkbox without state group", checkbox: `^- \[[ x]\] (.*)$`, wantErr: `checkbox pattern has no "state" group`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewGrammar(tc.taskHeader, tc.checkbox)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantIsDef, g.IsDefault())
+		})
+	}
+
+	g, err := NewGrammar("", `^\s*\*\s+\((?P<state>[ x])\)\s*(?P<text>.*)$`)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTaskHeaderPattern, g.TaskHeaderPattern())
+	assert.Equal(t, `^\s*\*\s+\((?P<state>[ x])\)\s*(?P<text>.*)$`, g.CheckboxPattern())
+}
+
+func TestGrammar_Parse(t *testing.T) {
+	content := `# Plan
+
+## Step 1 - Schema (depends: none)
+* ( ) add table
+  * (x) add index
+- [ ] not a checkbox in this grammar
+
+## Step 3 - API (depends: 1)
+* ( ) add handler
+
+### Task 9: not a task in this grammar
+`
+	g, err := NewGrammar(`^##\s+Step\s+(?P<num>\d+)\s+-\s+(?P<title>.*)$`, `^\s*\*\s+\((?P<state>[ x~])\)\s*(?P<text>.*)$`)
+	require.NoError(t, err)
+
+	d := g.Parse(content)
+	assert.Equal(t, content, d.String())
+	require.Len(t, d.Tasks, 2)
+	assert.Equal(t, &Task{Number: 1, Title: "Schema", Depends: "none", Line: 2, End: 7,
+		Items: []*Item{{Text: "add table", State: StateOpen, Line: 3, Children: []*Item{
+			{Text: "add index", State: StateDone, Level: 1, Line: 4}}}}}, d.Tasks[0])
+	assert.Equal(t, 3, d.Tasks[1].Number)
+	assert.Equal(t, LineText, d.Kind(5))
+	assert.Equal(t, LineHeader, d.Kind(10))
+	assert.Len(t, d.Sections, 2, "task headers are sections too")
+	assert.True(t, d.HasOpenItems())
+
+	require.NoError(t, d.CheckItem(1, 0))
+	require.NoError(t, d.MarkTaskBlocked(3, "no access"))
+	d.RenumberTasks()
+	assert.Equal(t, `# Plan
+
+## Step 1 - Schema (depends: none)
+* (x) add table
+  * (x) add index
+- [ ] not a checkbox in this grammar
+
+## Step 2 - API (depends: 1)
+
+> blocked: no access
+* (~) add handler
+
+### Task 9: not a task in this grammar
+`, d.String())
+	assert.False(t, d.HasOpenItems())
+}
+
+func TestGrammar_ParseNumberless(t *testing.T) {
+	g, err := NewGrammar(`^###\s+Phase:\s*(?P<title>.*)$`, `^\s*-\s+\[(?P<state>[ x])\]`)
+	require.NoError(t, err)
+
+	d := g.Parse("### Phase: A\n- [ ] a\n### Phase: B (depends: 1)\n- [ ]  b \n")
+	require.Len(t, d.Tasks, 2)
+	assert.Equal(t, []int{1, 2}, []int{d.Tasks[0].Number, d.Tasks[1].Number}, "numbered by position")
+	assert.Equal(t, "B", d.Tasks[1].Title)
+	assert.Equal(t, "1", d.Tasks[1].Depends)
+	assert.Equal(t, "b", d.Tasks[1].Items[0].Text, "text after the match without a text group")
+
+	require.NoError(t, d.MarkTaskBlocked(1, "no access"))
+	assert.Equal(t, "### Phase: A\n\n> blocked: no access\n- [x] a\n### Phase: B (depends: 1)\n- [ ]  b \n", d.String(),
+		"done mark used when the grammar has no blocked mark")
+
+	d.RenumberTasks()
+	assert.Equal(t, "### Phase: A\n\n> blocked: no access\n- [x] a\n### Phase: B (depends: 1)\n- [ ]  b \n", d.String())
+}
+
+func TestGrammar_NumberedListTasks(t *testing.T) {
+	g, err := NewGrammar(`^(?P<num>\d+)\.\s+(?P<title>.+)$`, "")
+	require.NoError(t, err)
+
+	d := g.Parse("# Plan\n## Tasks\n1. Setup\n   - [ ] install\n2. Build\n   - [x] compile\n## Notes\n- [ ] outside\n")
+	require.Len(t, d.Tasks, 2)
+	assert.Equal(t, "Setup", d.Tasks[0].Title)
+	assert.Len(t, d.Tasks[0].Items, 1)
+	assert.Equal(t, 6, d.Tasks[1].End, "markdown header ends the task")
+	require.Len(t, d.Outside, 1)
+	assert.NotNil(t, d.Section("tasks"))
+}
@@ -0,0 +1,475 @@
+// Package plan implements the plan document model shared by the task runner, the web dashboard and the CLI.
+// a plan is parsed into title, sections, task sections with nested checkboxes and notes, while the
+// original lines are kept, so an unmodified document renders back byte for byte and edits touch
+// only the lines they change.
+//
+// checkboxes meant for humans are excluded from tasks: the "## Manual Steps" section, blocks between
+// "<!-- ralphex:ignore -->" and "<!-- /ralphex:ignore -->" markers and multi-line html comments,
+// e.g. with example tasks, are not parsed.
+//
+// task headers and checkboxes are recognized by a Grammar, configurable for plans written in a
+// different format, e.g. "## Step 1 - title" headers.
+//
+// a plan may start with a frontmatter block between "---" lines with per-plan settings, the block is
+// kept as is in Document.Frontmatter and is interpreted by the config package.
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LineKind classifies a line of the plan document.
+type LineKind int
+
+// line kinds.
+const (
+	LineText        LineKind = iota // any other line, including blank ones
+	LineTitle                       // the first "# Title" header
+	LineHeader                      // any other markdown header, e.g. "## Overview"
+	LineTask                        // task header, "### Task N: title" or "### Iteration N: title" by default
+	LineItem                        // checkbox, "- [ ] text" by default
+	LineNote                        // blockquote inside a task section, "> text"
+	LineCode                        // fenced code block, fences included
+	LineIgnored                     // "## Manual Steps" section body, ignore blocks and html comments, markers included
+	LineFrontmatter                 // frontmatter block at the top of the plan, "---" delimiters included
+)
+
+// State is the state of a checkbox.
+type State string
+
+// checkbox states.
+const (
+	StateOpen    State = " " // "- [ ]"
+	StateDone    State = "x" // "- [x]" or "- [X]"
+	StateBlocked State = "~" // "- [~]" or "- [-]"
+)
+
+// blockedNotePrefix starts the note with the reason of a blocked task.
+const blockedNotePrefix = "blocked:"
+
+// frontmatterDelim opens and closes the frontmatter block.
+const frontmatterDelim = "---"
+
+// ManualStepsSection is the name of the section with checkboxes for humans, excluded from tasks.
+const ManualStepsSection = "Manual Steps"
+
+// patterns for plan markdown.
+var (
+	headerRe    = regexp.MustCompile(`^(#{1,6})(?:\s+(.*))?$`)
+	dependsRe   = regexp.MustCompile(`\s*\(depends:\s*([^)]*)\)\s*$`)
+	ignoreRe    = regexp.MustCompile(`^<!--\s*ralphex:ignore\s*-->$`)
+	ignoreEndRe = regexp.MustCompile(`^<!--\s*/ralphex:ignore\s*-->$`)
+)
+
+// Document is a parsed plan.
+type Document struct {
+	Title       string
+	Frontmatter string     // body of the frontmatter block without delimiters, empty if the plan has none
+	Sections    []*Section // "## " sections in document order
+	Tasks       []*Task
+	Outside     []*Item // checkboxes outside of task sections
+
+	grammar Grammar
+	lines   []string
+	kinds   []LineKind
+}
+
+// Section is a "## " section of the plan, e.g. Overview, Context or Validation Commands.
+type Section struct {
+	Name string
+	Body string // text up to the next "#" or "##" header, nested headers and tasks included
+	Line int    // 0-based index of the header line
+	End  int    // index of the line after the section
+}
+
+// Task is a task section of the plan.
+type Task struct {
+	Number  int
+	Title   string   // title without the dependency annotation
+	Depends string   // raw list of the "(depends: ...)" annotation, empty if the header has none
+	Items   []*Item  // top-level checkboxes, nested ones are children
+	Notes   []string // blockquote lines of the section without the ">" prefix
+	Line    int      // 0-based index of the header line
+	End     int      // index of the line after the section
+}
+
+// Item is a checkbox.
+type Item struct {
+	Text     string
+	State    State
+	Level    int // nesting level, 0 for top-level checkboxes
+	Line     int // 0-based index of the checkbox line
+	Children []*Item
+}
+
+// Parse parses plan content with the default grammar. parsing never fails, problems like duplicate task
+// numbers are reported by Validate, so partially broken plans can still be shown and fixed.
+func Parse(content string) *Document {
+	return Grammar{}.Parse(content)
+}
+
+// parse builds the model from document lines.
+func (d *Document) parse() {
+	d.Title, d.Frontmatter, d.Sections, d.Tasks, d.Outside = "", "", nil, nil, nil
+	d.kinds = make([]LineKind, len(d.lines))
+	start := d.parseFrontmatter()
+
+	var task *Task
+	var section *Section
+	var parents []*Item // open checkboxes by nesting level, for nested checkboxes
+	var indents []int
+	taskRe, checkboxRe := d.grammar.taskHeaderRe(), d.grammar.checkboxRe()
+	fence := ""                      // marker of the open code fence, empty outside of code blocks
+	ignoring, manual := false, false // inside an ignore block, inside the manual steps section
+	comment := false                 // inside a multi-line html comment
+
+	for i := start; i < len(d.lines); i++ {
+		line := d.lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if ignoring || comment {
+			d.kinds[i] = LineIgnored
+			ignoring = ignoring && !ignoreEndRe.MatchString(trimmed)
+			comment = comment && !strings.Contains(trimmed, "-->")
+			continue
+		}
+
+		if fence != "" || strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			d.kinds[i] = LineCode
+			switch {
+			case fence == "":
+				fence = trimmed[:3]
+			case strings.HasPrefix(trimmed, fence):
+				fence = ""
+			}
+			continue
+		}
+
+		if ignoreRe.MatchString(trimmed) {
+			d.kinds[i] = LineIgnored
+			ignoring = true
+			continue
+		}
+		if strings.HasPrefix(trimmed, "<!--") && !strings.Contains(trimmed, "-->") {
+			d.kinds[i] = LineIgnored
+			comment = true
+			continue
+		}
+
+		m := headerRe.FindStringSubmatch(trimmed)
+		if manual && (m == nil || len(m[1]) > 2) {
+			d.kinds[i] = LineIgnored
+			continue
+		}
+
+		// task headers are not necessarily markdown headers, e.g. with a numbered list grammar
+		tm := taskRe.FindStringSubmatchIndex(trimmed)
+		if m != nil || tm != nil {
+			level := 0
+			if m != nil {
+				level = len(m[1])
+			}
+			if task != nil {
+				task.End, task = i, nil
+			}
+			if section != nil && m != nil && level <= 2 {
+				d.closeSection(section, i)
+				section = nil
+			}
+			parents, indents = nil, nil
+
+			switch {
+			case tm != nil:
+				d.kinds[i] = LineTask
+				task = d.newTask(taskRe, trimmed, tm, i)
+				d.Tasks = append(d.Tasks, task)
+			case level == 1 && d.Title == "":
+				d.kinds[i] = LineTitle
+				d.Title = strings.TrimSpace(m[2])
+			default:
+				d.kinds[i] = LineHeader
+			}
+			if m != nil && level <= 2 {
+				manual = false
+			}
+			if level == 2 {
+				section = &Section{Name: strings.TrimSpace(m[2]), Line: i}
+				d.Sections = append(d.Sections, section)
+				manual = strings.EqualFold(section.Name, ManualStepsSection)
+			}
+			continue
+		}
+
+		if cm := checkboxRe.FindStringSubmatchIndex(line); cm != nil {
+			d.kinds[i] = LineItem
+			indent := indentWidth(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+			for len(indents) > 0 && indents[len(indents)-1] >= indent {
+				parents, indents = parents[:len(parents)-1], indents[:len(indents)-1]
+			}
+			mark, _, _ := group(checkboxRe, line, cm, "state")
+			text, start, _ := group(checkboxRe, line, cm, "text")
+			if start < 0 {
+				text = line[cm[1]:]
+			}
+			item := &Item{Text: strings.TrimSpace(text), State: parseState(mark), Level: len(parents), Line: i}
+			switch {
+			case len(parents) > 0:
+				parent := parents[len(parents)-1]
+				parent.Children = append(parent.Children, item)
+			case task != nil:
+				task.Items = append(task.Items, item)
+			default:
+				d.Outside = append(d.Outside, item)
+			}
+			parents, indents = append(parents, item), append(indents, indent)
+			continue
+		}
+
+		if task != nil && strings.HasPrefix(trimmed, ">") {
+			d.kinds[i] = LineNote
+			task.Notes = append(task.Notes, strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+		}
+	}
+
+	if task != nil {
+		task.End = len(d.lines)
+	}
+	if section != nil {
+		d.closeSection(section, len(d.lines))
+	}
+}
+
+// parseFrontmatter marks the frontmatter block at the top of the document and returns the index of
+// the first line after it. a "---" line without the closing one is a thematic break, not frontmatter.
+func (d *Document) parseFrontmatter() int {
+	if len(d.lines) == 0 || strings.TrimSpace(d.lines[0]) != frontmatterDelim {
+		return 0
+	}
+	for i := 1; i < len(d.lines); i++ {
+		if strings.TrimSpace(d.lines[i]) != frontmatterDelim {
+			continue
+		}
+		for j := 0; j <= i; j++ {
+			d.kinds[j] = LineFrontmatter
+		}
+		d.Frontmatter = strings.Join(d.lines[1:i], "\n")
+		return i + 1
+	}
+	return 0
+}
+
+// closeSection sets the end and the body of a section.
+func (d *Document) closeSection(s *Section, end int) {
+	s.End = end
+	s.Body = strings.TrimSpace(strings.Join(d.lines[s.Line+1:end], "\n"))
+}
+
+// newTask creates a task from the submatch index of a task header, tasks without a number in the header
+// are numbered by position.
+func (d *Document) newTask(re *regexp.Regexp, header string, m []int, line int) *Task {
+	num, _, _ := group(re, header, m, "num")
+	number, err := strconv.Atoi(num)
+	if err != nil {
+		number = len(d.Tasks) + 1
+	}
+	title, start, _ := group(re, header, m, "title")
+	if start < 0 {
+		title = header
+	}
+	t := &Task{Number: number, Title: strings.TrimSpace(title), Line: line}
+	if dm := dependsRe.FindStringSubmatch(t.Title); dm != nil {
+		t.Title = strings.TrimSpace(strings.TrimSuffix(t.Title, dm[0]))
+		t.Depends = strings.TrimSpace(dm[1])
+	}
+	return t
+}
+
+// parseState converts a checkbox mark to its state, any mark other than open and blocked ones is done.
+func parseState(mark string) State {
+	switch strings.TrimSpace(mark) {
+	case "":
+		return StateOpen
+	case "~", "-":
+		return StateBlocked
+	default:
+		return StateDone
+	}
+}
+
+// indentWidth returns the width of leading whitespace, a tab counts as four spaces.
+func indentWidth(s string) int {
+	return len(s) + 3*strings.Count(s, "\t")
+}
+
+// String renders the document.
+func (d *Document) String() string {
+	return strings.Join(d.lines, "\n")
+}
+
+// Kind returns the kind of the line with 0-based index i.
+func (d *Document) Kind(i int) LineKind {
+	if i < 0 || i >= len(d.kinds) {
+		return LineText
+	}
+	return d.kinds[i]
+}
+
+// Section returns the "## " section with the given name, compared case-insensitively, or nil.
+func (d *Document) Section(name string) *Section {
+	idx := slices.IndexFunc(d.Sections, func(s *Section) bool { return strings.EqualFold(s.Name, name) })
+	if idx < 0 {
+		return nil
+	}
+	return d.Sections[idx]
+}
+
+// Overview returns the body of the "## Overview" section.
+func (d *Document) Overview() string {
+	return d.sectionBody("Overview")
+}
+
+// Context returns the body of the "## Context" section.
+func (d *Document) Context() string {
+	return d.sectionBody("Context")
+}
+
+// sectionBody returns the body of the named section, empty if the plan doesn't have it.
+func (d *Document) sectionBody(name string) string {
+	if s := d.Section(name); s != nil {
+		return s.Body
+	}
+	return ""
+}
+
+// ValidationCommands returns commands listed in the "## Validation Commands" section.
+// each list item up to the next header is a command, backticks around the command are optional.
+func (d *Document) ValidationCommands() []string {
+	s := d.Section("Validation Commands")
+	if s == nil {
+		return nil
+	}
+
+	var commands []string
+	for i := s.Line + 1; i < s.End && d.kinds[i] != LineHeader && d.kinds[i] != LineTask; i++ {
+		if d.kinds[i] != LineText {
+			continue
+		}
+		trimmed := strings.TrimSpace(d.lines[i])
+		item, ok := strings.CutPrefix(trimmed, "- ")
+		if !ok {
+			if item, ok = strings.CutPrefix(trimmed, "* "); !ok {
+				continue
+			}
+		}
+		item = strings.TrimSpace(item)
+		if start := strings.Index(item, "`"); start >= 0 {
+			if end := strings.Index(item[start+1:], "`"); end > 0 {
+				item = item[start+1 : start+1+end]
+			}
+		}
+		if item = strings.TrimSpace(item); item != "" {
+			commands = append(commands, item)
+		}
+	}
+	return commands
+}
+
+// Task returns the task with the given number, or nil.
+func (d *Document) Task(number int) *Task {
+	idx := slices.IndexFunc(d.Tasks, func(t *Task) bool { return t.Number == number })
+	if idx < 0 {
+		return nil
+	}
+	return d.Tasks[idx]
+}
+
+// HasOpenItems returns true if any task section has open checkboxes. checkboxes outside of task sections,
+// e.g. success criteria, are not work for the runner and are not counted, unless the plan has no task
+// sections at all and is a plain checklist.
+func (d *Document) HasOpenItems() bool {
+	if len(d.Tasks) > 0 {
+		return slices.ContainsFunc(d.Tasks, func(t *Task) bool { return !t.Done() })
+	}
+	return slices.ContainsFunc(flatten(d.Outside), func(it *Item) bool { return it.State == StateOpen })
+}
+
+// Validate checks task numbers and dependencies, returns the first problem found.
+func (d *Document) Validate() error {
+	seen := make(map[int]bool, len(d.Tasks))
+	for _, t := range d.Tasks {
+		if seen[t.Number] {
+			return fmt.Errorf("duplicate task %d", t.Number)
+		}
+		seen[t.Number] = true
+		if _, err := t.Dependencies(); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range d.Tasks {
+		deps, _ := t.Dependencies()
+		for _, dep := range deps {
+			if dep == t.Number || !seen[dep] {
+				return fmt.Errorf("task %d depends on unknown task %d", t.Number, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// Checkboxes returns all checkboxes of the task, nested ones included, in document order.
+func (t *Task) Checkboxes() []*Item {
+	return flatten(t.Items)
+}
+
+// Done returns true if the task has no open checkboxes. blocked checkboxes are not open.
+func (t *Task) Done() bool {
+	return !slices.ContainsFunc(t.Checkboxes(), func(it *Item) bool { return it.State == StateOpen })
+}
+
+// Blocked returns true if any checkbox of the task is marked blocked.
+func (t *Task) Blocked() bool {
+	return slices.ContainsFunc(t.Checkboxes(), func(it *Item) bool { return it.State == StateBlocked })
+}
+
+// BlockedReason returns the reason from the last "> blocked: ..." note of the task.
+func (t *Task) BlockedReason() string {
+	var reason string
+	for _, note := range t.Notes {
+		if r, ok := strings.CutPrefix(note, blockedNotePrefix); ok {
+			reason = strings.TrimSpace(r)
+		}
+	}
+	return reason
+}
+
+// Dependencies returns task numbers of the "(depends: ...)" annotation.
+// returns nil for tasks without the annotation and for "(depends: none)".
+func (t *Task) Dependencies() ([]int, error) {
+	if t.Depends == "" || strings.EqualFold(t.Depends, "none") {
+		return nil, nil
+	}
+	var deps []int
+	for part := range strings.SplitSeq(t.Depends, ",") {
+		dep, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("task %d: invalid dependency %q", t.Number, strings.TrimSpace(part))
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// flatten returns checkboxes with their nested checkboxes in document order.
+func flatten(items []*Item) []*Item {
+	var res []*Item
+	for _, it := range items {
+		res = append(res, it)
+		res = append(res, flatten(it.Children)...)
+	}
+	return res
+}
@@ -0,0 +1,280 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePlan = `# Add caching
+
+## Overview
+
+Cache API responses.
+
+## Context
+
+- Files involved: pkg/api
+
+## Validation Commands
+- ` + "`go test ./...`" + `
+- make lint
+
+## Implementation Steps
+
+### Task 1: Add cache
+- [x] add cache package
+- [ ] write tests
+  - [ ] hit path
+  - [x] miss path
+
+### Task 2: Wire cache (depends: 1)
+
+> blocked: no redis in CI
+- [~] wire into handlers
+
+Example of a plan in a code block:
+` + "```markdown" + `
+### Task 9: Not a task
+- [ ] not a checkbox
+` + "```" + `
+
+### Iteration 3: Docs (depends: none)
+- [ ] update readme
+
+## Post-Completion
+- [ ] manual check
+`
+
+func TestParse(t *testing.T) {
+	d := Parse(samplePlan)
+	assert.Equal(t, samplePlan, d.String(), "round trip")
+
+	assert.Equal(t, "Add caching", d.Title)
+	assert.Equal(t, "Cache API responses.", d.Overview())
+	assert.Equal(t, "- Files involved: pkg/api", d.Context())
+	assert.Equal(t, []string{"go test ./...", "make lint"}, d.ValidationCommands())
+
+	var names []string
+	for _, s := range d.Sections {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"Overview", "Context", "Validation Commands", "Implementation Steps", "Post-Completion"}, names)
+	assert.Contains(t, d.Section("implementation steps").Body, "### Task 1: Add cache")
+	assert.Nil(t, d.Section("Notes"))
+
+	require.Len(t, d.Tasks, 3)
+	t1, t2, t3 := d.Tasks[0], d.Tasks[1], d.Tasks[2]
+
+	assert.Equal(t, 1, t1.Number)
+	assert.Equal(t, "Add cache", t1.Title)
+	assert.Empty(t, t1.Depends)
+	require.Len(t, t1.Items, 2)
+	require.Len(t, t1.Items[1].Children, 2)
+	assert.Equal(t, &Item{Text: "hit path", State: StateOpen, Level: 1, Line: 19}, t1.Items[1].Children[0])
+	assert.Len(t, t1.Checkboxes(), 4)
+	assert.False(t, t1.Done())
+	assert.False(t, t1.Blocked())
+
+	assert.Equal(t, "Wire cache", t2.Title)
+	assert.Equal(t, "1", t2.Depends)
+	assert.True(t, t2.Done(), "blocked checkboxes are not open")
+	assert.True(t, t2.Blocked())
+	assert.Equal(t, "no redis in CI", t2.BlockedReason())
+	assert.Equal(t, []string{"blocked: no redis in CI"}, t2.Notes)
+	assert.Len(t, t2.Checkboxes(), 1, "checkboxes in code blocks ignored")
+
+	assert.Equal(t, 3, t3.Number)
+	assert.Equal(t, "Docs", t3.Title)
+	assert.Equal(t, "none", t3.Depends)
+	require.Len(t, d.Outside, 1)
+	assert.Equal(t, "manual check", d.Outside[0].Text)
+
+	assert.Equal(t, LineTitle, d.Kind(0))
+	assert.Equal(t, LineHeader, d.Kind(2))
+	assert.Equal(t, LineTask, d.Kind(16))
+	assert.Equal(t, LineItem, d.Kind(17))
+	assert.Equal(t, LineNote, d.Kind(24))
+	assert.Equal(t, LineCode, d.Kind(30))
+	assert.Equal(t, LineText, d.Kind(100))
+
+	assert.Same(t, t2, d.Task(2))
+	assert.Nil(t, d.Task(9))
+	assert.True(t, d.HasOpenItems())
+}
+
+func TestParse_Frontmatter(t *testing.T) {
+	content := "---\nmax_iterations: 20\n# plan settings\nbranch: feature/cache\n---\n# Add caching\n### Task 1: A\n- [ ] a\n"
+	d := Parse(content)
+	assert.Equal(t, content, d.String())
+	assert.Equal(t, "max_iterations: 20\n# plan settings\nbranch: feature/cache", d.Frontmatter)
+	assert.Equal(t, "Add caching", d.Title, "comments in frontmatter are not headers")
+	for i := range 5 {
+		assert.Equal(t, LineFrontmatter, d.Kind(i), "line %d", i)
+	}
+	assert.Equal(t, LineTitle, d.Kind(5))
+	require.Len(t, d.Tasks, 1)
+
+	d = Parse("---\n# Plan\n### Task 1: A\n- [ ] a\n")
+	assert.Empty(t, d.Frontmatter, "not closed, thematic break")
+	assert.Equal(t, "Plan", d.Title)
+	require.Len(t, d.Tasks, 1)
+
+	d = Parse("# Plan\n---\nkey: value\n---\n")
+	assert.Empty(t, d.Frontmatter, "only at the top of the plan")
+	assert.Equal(t, LineText, d.Kind(1))
+}
+
+func TestDocument_ValidationCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "backticked commands",
+			content: `# Plan
+
+## Validation Commands
+- ` + "`go test ./...`" + `
+- ` + "`golangci-lint run`" + `
+
+### Task 1: Something
+- [ ] ` + "`not a command`",
+			want: []string{"go test ./...", "golangci-lint run"},
+		},
+		{
+			name:    "plain items and text around backticks",
+			content: "## validation commands\n\n* make test\n- run `make lint` for style\n\nsome text\n",
+			want:    []string{"make test", "make lint"},
+		},
+		{
+			name:    "section ends at next header",
+			content: "## Validation Commands\n- `make test`\n## Notes\n- `not a command`\n",
+			want:    []string{"make test"},
+		},
+		{
+			name:    "commands in code block ignored",
+			content: "## Validation Commands\n- `make test`\n```\n- `not a command`\n```\n",
+			want:    []string{"make test"},
+		},
+		{
+			name:    "no section",
+			content: "# Plan\n### Task 1: A\n- [ ] item\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Parse(tc.content).ValidationCommands())
+		})
+	}
+}
+
+func TestParse_Ignored(t *testing.T) {
+	content := `# Plan
+
+### Task 1: Deploy
+- [x] write manifest
+<!-- ralphex:ignore -->
+- [ ] check dashboards in staging
+### Task 9: not a task inside an ignore block
+<!-- /ralphex:ignore -->
+- [ ] apply manifest
+
+## Manual Steps
+- [ ] announce release
+### Task 2: not a task inside manual steps
+- [ ] ignored
+
+## Notes
+- [ ] outside
+`
+	d := Parse(content)
+	assert.Equal(t, content, d.String())
+
+	require.Len(t, d.Tasks, 1)
+	var texts []string
+	for _, it := range d.Tasks[0].Checkboxes() {
+		texts = append(texts, it.Text)
+	}
+	assert.Equal(t, []string{"write manifest", "apply manifest"}, texts, "ignore block doesn't end the task section")
+	require.Len(t, d.Outside, 1)
+	assert.Equal(t, "outside", d.Outside[0].Text)
+
+	for _, i := range []int{4, 5, 6, 7, 11, 12, 13} {
+		assert.Equal(t, LineIgnored, d.Kind(i), "line %d", i)
+	}
+	assert.Equal(t, LineHeader, d.Kind(10), "manual steps header")
+	assert.Equal(t, LineItem, d.Kind(8))
+	assert.Equal(t, LineHeader, d.Kind(15), "section after manual steps")
+	assert.NotNil(t, d.Section("manual steps"))
+
+	require.NoError(t, d.MarkTaskBlocked(1, "no access"))
+	assert.Contains(t, d.String(), "- [ ] check dashboards in staging", "ignored checkboxes are not marked")
+	assert.Contains(t, d.String(), "- [~] apply manifest")
+}
+
+func TestDocument_HasOpenItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "all done", content: "# Plan\n### Task 1: A\n- [x] a\n- [~] b\n", want: false},
+		{name: "open in task", content: "### Task 1: A\n- [x] a\n  - [ ] nested\n", want: true},
+		{name: "open outside of tasks ignored", content: "### Task 1: A\n- [x] a\n## Success criteria\n- [ ] works\n", want: false},
+		{name: "checklist without tasks", content: "# Plan\n- [x] a\n- [ ] b\n", want: true},
+		{name: "open in ignore block", content: "### Task 1: A\n- [x] a\n<!-- ralphex:ignore -->\n- [ ] b\n<!-- /ralphex:ignore -->\n",
+			want: false},
+		{name: "open in manual steps of checklist", content: "- [x] a\n## Manual Steps\n- [ ] b\n", want: false},
+		{name: "open in html comment", content: "### Task 1: A\n- [x] a\n<!--\nexample:\n- [ ] b\n-->\n", want: false},
+		{name: "no checkboxes", content: "# Plan\ntext\n", want: false},
+		{name: "open in code block", content: "```\n- [ ] a\n```\n", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Parse(tc.content).HasOpenItems())
+		})
+	}
+}
+
+func TestDocument_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: "### Task 1: A\n### Task 2: B (depends: 1)\n### Task 3: C (depends: none)\n"},
+		{name: "no tasks", content: "# Plan\n"},
+		{name: "unknown dependency", content: "### Task 1: A\n### Task 2: B (depends: 5)", wantErr: "task 2 depends on unknown task 5"},
+		{name: "self dependency", content: "### Task 1: A (depends: 1)", wantErr: "task 1 depends on unknown task 1"},
+		{name: "invalid dependency", content: "### Task 1: A\n### Task 2: B (depends: first)",
+			wantErr: `task 2: invalid dependency "first"`},
+		{name: "duplicate task", content: "### Task 1: A\n### Task 1: B", wantErr: "duplicate task 1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Parse(tc.content).Validate()
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestTask_Dependencies(t *testing.T) {
+	deps, err := (&Task{Number: 3, Depends: "1, 2"}).Dependencies()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, deps)
+
+	deps, err = (&Task{Number: 3, Depends: "None"}).Dependencies()
+	require.NoError(t, err)
+	assert.Nil(t, deps)
+
+	_, err = (&Task{Number: 3, Depends: "1, x"}).Dependencies()
+	require.EqualError(t, err, `task 3: invalid dependency "x"`)
+}
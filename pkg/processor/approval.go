@@ -0,0 +1,163 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/runfile"
+)
+
+// ErrRunAborted is returned when the run is aborted at a phase approval gate.
+var ErrRunAborted = errors.New("run aborted at approval gate")
+
+// defaultApprovalPoll is how often a waiting approval gate checks the approval file for a decision.
+const defaultApprovalPoll = time.Second
+
+// approvalAnswer is the decision collected in terminal.
+type approvalAnswer struct {
+	decision runfile.ApprovalDecision
+	err      error
+}
+
+// confirmPhase runs the approval gate after the pipeline step at index, if the step has one.
+// the gate is skipped after the last step. the next step is checkpointed before waiting,
+// so an interrupted or aborted run resumes with it.
+func (r *Runner) confirmPhase(ctx context.Context, index int, since string) (runfile.ApprovalDecision, error) {
+	step := r.pipeline[index]
+	if index == len(r.pipeline)-1 || !slices.Contains(r.cfg.ConfirmPhases, step) {
+		return runfile.ApprovalApprove, nil
+	}
+
+	approval := runfile.Approval{Phase: step.String(), NextPhase: r.pipeline[index+1].String(), Requested: time.Now()}
+	r.collectChanges(&approval, since)
+
+	r.step = index + 1
+	r.checkpoint(1, "")
+
+	r.log.PrintSection(NewGenericSection(fmt.Sprintf("awaiting approval: %s phase completed, next %s", approval.Phase,
+		approval.NextPhase)))
+	r.log.Print("commits: %d, changed files: %d", len(approval.Commits), len(approval.Files))
+	for _, c := range approval.Commits {
+		r.log.PrintAligned("commit " + c)
+	}
+	for line := range strings.SplitSeq(approval.DiffStat, "\n") {
+		if line != "" {
+			r.log.PrintAligned(line)
+		}
+	}
+
+	decision, err := r.waitApproval(ctx, approval)
+	if err != nil {
+		return "", err
+	}
+	r.log.Print("approval gate decision: %s", decision)
+	return decision, nil
+}
+
+// collectChanges fills commits, changed files and diffstat of the phase started at commit since.
+// failures are logged, the gate still waits for a decision.
+func (r *Runner) collectChanges(a *runfile.Approval, since string) {
+	if r.git == nil || since == "" {
+		return
+	}
+	var err error
+	if a.Commits, err = r.git.CommitsSince(since); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+	if a.Files, err = r.git.ChangedFilesSince(since); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+	if a.DiffStat, err = r.git.DiffStatSince(since); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+}
+
+// waitApproval waits for the decision on a pending approval. the decision is asked in terminal via
+// the input collector and accepted from the approval file, whichever comes first. if terminal input is
+// not available (e.g. the run is detached), the gate keeps waiting for the decision from the dashboard.
+// with WebApproval set the terminal is not asked at all, so no pending prompt outlives the gate.
+func (r *Runner) waitApproval(ctx context.Context, approval runfile.Approval) (runfile.ApprovalDecision, error) {
+	path := r.cfg.ApprovalPath
+	if path != "" {
+		if err := approval.Save(path); err != nil {
+			return "", err
+		}
+		defer func() {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				r.log.Print("warning: failed to remove approval file: %v", err)
+			}
+		}()
+	}
+
+	// the terminal prompt is canceled on return, when the decision comes from the dashboard
+	askCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	answers := make(chan approvalAnswer, 1)
+	switch {
+	case r.inputCollector != nil && !r.cfg.WebApproval:
+		go func() { answers <- r.askApproval(askCtx, approval) }()
+	case path == "":
+		return "", errors.New("approval gate requires input collector or approval file")
+	default:
+		r.log.Print("waiting for the decision in dashboard")
+	}
+
+	ticker := time.NewTicker(r.approvalPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("approval gate: %w", ctx.Err())
+		case ans := <-answers:
+			if ans.err == nil {
+				return ans.decision, nil
+			}
+			if path == "" {
+				return "", ans.err
+			}
+			r.log.Print("warning: %v, waiting for the decision in dashboard", ans.err)
+		case <-ticker.C:
+			if path == "" {
+				continue
+			}
+			a, err := runfile.LoadApproval(path)
+			if err != nil {
+				r.log.Print("warning: %v", err)
+				continue
+			}
+			if a == nil || a.Decision == "" {
+				continue
+			}
+			decision, err := runfile.ParseApprovalDecision(string(a.Decision))
+			if err != nil {
+				r.log.Print("warning: %v", err)
+				continue
+			}
+			r.log.Print("approval decision received from dashboard")
+			return decision, nil
+		}
+	}
+}
+
+// askApproval asks for the approval decision in terminal.
+func (r *Runner) askApproval(ctx context.Context, approval runfile.Approval) approvalAnswer {
+	options := []string{
+		"approve, continue with " + approval.NextPhase,
+		"skip " + approval.NextPhase + " phase",
+		"abort the run",
+	}
+	answer, err := r.inputCollector.AskQuestion(ctx, fmt.Sprintf("%s phase completed, continue?", approval.Phase), options)
+	if err != nil {
+		return approvalAnswer{err: fmt.Errorf("terminal approval: %w", err)}
+	}
+	decisions := []runfile.ApprovalDecision{runfile.ApprovalApprove, runfile.ApprovalSkip, runfile.ApprovalAbort}
+	if idx := slices.Index(options, answer); idx >= 0 {
+		return approvalAnswer{decision: decisions[idx]}
+	}
+	return approvalAnswer{err: fmt.Errorf("terminal approval: unexpected answer %q", answer)}
+}
@@ -0,0 +1,184 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/runfile"
+)
+
+// stubGit implements change listing of GitRepo, other methods are not used by approval gates.
+type stubGit struct {
+	GitRepo
+	commits  []string
+	files    []string
+	diffStat string
+}
+
+func (g *stubGit) HeadHash() (string, error)                  { return "abc", nil }
+func (g *stubGit) CommitsSince(string) ([]string, error)      { return g.commits, nil }
+func (g *stubGit) ChangedFilesSince(string) ([]string, error) { return g.files, nil }
+func (g *stubGit) DiffStatSince(string) (string, error)       { return g.diffStat, nil }
+func (g *stubGit) FileHasChanges(string) (bool, error)        { return false, nil }
+func (g *stubGit) CurrentBranch() (string, error)             { return "feature", nil }
+func (g *stubGit) BaseRef(string) string                      { return "master" }
+
+func TestRunner_ConfirmPhases(t *testing.T) {
+	pipeline := []Step{{Kind: StepReviewFirst}, {Kind: StepReviewLoop}}
+	newGatedRunner := func(t *testing.T, calls *atomic.Int32, collector InputCollector) (*Runner, Config) {
+		t.Helper()
+		review := funcExecutor(func(context.Context, string) executor.Result {
+			calls.Add(1)
+			return executor.Result{Output: "clean", Signal: SignalReviewDone}
+		})
+		dir := t.TempDir()
+		cfg := Config{Mode: ModeReview, Pipeline: pipeline, ConfirmPhases: []Step{{Kind: StepReviewFirst}},
+			MaxIterations: 10, IterationDelayMs: 1, StatePath: filepath.Join(dir, "progress.state.json"),
+			ApprovalPath: filepath.Join(dir, "progress.approval.json"), AppConfig: testAppConfig(t)}
+		r := newRunner(cfg, newMockLogger(""), review, review)
+		r.SetGitRepo(&stubGit{commits: []string{"abc123 fix: review findings"}, files: []string{"main.go"},
+			diffStat: " main.go | 2 +-\n 1 file changed"})
+		r.approvalPoll = time.Millisecond
+		if collector != nil {
+			r.SetInputCollector(collector)
+		}
+		return r, cfg
+	}
+	answer := func(idx int) funcCollector {
+		return func(_ context.Context, _ string, options []string) (string, error) { return options[idx], nil }
+	}
+
+	t.Run("approve continues with next phase", func(t *testing.T) {
+		var calls atomic.Int32
+		var asked string
+		r, cfg := newGatedRunner(t, &calls, funcCollector(func(_ context.Context, q string, options []string) (string, error) {
+			asked = q
+			assert.Equal(t, []string{"approve, continue with review_loop", "skip review_loop phase", "abort the run"}, options)
+			return options[0], nil
+		}))
+		require.NoError(t, r.runPipeline(context.Background()))
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, "review_first phase completed, continue?", asked)
+		assert.NoFileExists(t, cfg.ApprovalPath, "approval file removed after decision")
+	})
+
+	t.Run("skip drops the next phase", func(t *testing.T) {
+		var calls atomic.Int32
+		r, _ := newGatedRunner(t, &calls, answer(1))
+		require.NoError(t, r.runPipeline(context.Background()))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("skip decision survives resume", func(t *testing.T) {
+		var calls atomic.Int32
+		r, cfg := newGatedRunner(t, &calls, answer(1))
+		require.NoError(t, r.runPipeline(context.Background()))
+		assert.Equal(t, int32(1), calls.Load())
+
+		// the run is interrupted before the skipped step is passed, the checkpoint points at it
+		st, err := LoadState(cfg.StatePath)
+		require.NoError(t, err)
+		require.NotNil(t, st)
+		assert.Equal(t, 1, st.Step)
+		assert.Equal(t, []int{1}, st.Skipped)
+
+		cfg.Resume = true
+		log := newMockLogger("")
+		resumed := newRunner(cfg, log, r.review, r.review)
+		require.NoError(t, resumed.loadResumeState())
+		require.NoError(t, resumed.runPipeline(context.Background()))
+		assert.Equal(t, int32(1), calls.Load(), "skipped step not run on resume")
+		var logged []string
+		for _, c := range log.PrintCalls() {
+			logged = append(logged, fmt.Sprintf(c.Format, c.Args...))
+		}
+		assert.Contains(t, logged, "skipping review_loop phase (step 2), skipped at approval gate in previous run")
+	})
+
+	t.Run("abort stops the run resumable at next phase", func(t *testing.T) {
+		var calls atomic.Int32
+		r, cfg := newGatedRunner(t, &calls, answer(2))
+		err := r.runPipeline(context.Background())
+		require.ErrorIs(t, err, ErrRunAborted)
+		assert.Equal(t, int32(1), calls.Load())
+
+		st, err := LoadState(cfg.StatePath)
+		require.NoError(t, err)
+		require.NotNil(t, st)
+		assert.Equal(t, 1, st.Step)
+		assert.Equal(t, 1, st.Iteration)
+	})
+
+	t.Run("decision from dashboard when terminal is not available", func(t *testing.T) {
+		var calls atomic.Int32
+		r, cfg := newGatedRunner(t, &calls, funcCollector(func(context.Context, string, []string) (string, error) {
+			return "", errors.New("EOF")
+		}))
+
+		go func() {
+			for {
+				a, err := runfile.LoadApproval(cfg.ApprovalPath)
+				if err == nil && a != nil {
+					assert.Equal(t, "review_first", a.Phase)
+					assert.Equal(t, []string{"main.go"}, a.Files)
+					assert.Equal(t, []string{"abc123 fix: review findings"}, a.Commits)
+					a.Decision = runfile.ApprovalSkip
+					assert.NoError(t, a.Save(cfg.ApprovalPath))
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+		require.NoError(t, r.runPipeline(context.Background()))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("terminal not asked with web approval", func(t *testing.T) {
+		var calls atomic.Int32
+		r, cfg := newGatedRunner(t, &calls, funcCollector(func(context.Context, string, []string) (string, error) {
+			t.Error("terminal asked for web approval")
+			return "", errors.New("unexpected")
+		}))
+		r.cfg.WebApproval = true
+
+		go func() {
+			for {
+				a, err := runfile.LoadApproval(cfg.ApprovalPath)
+				if err == nil && a != nil {
+					a.Decision = runfile.ApprovalApprove
+					assert.NoError(t, a.Save(cfg.ApprovalPath))
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+		require.NoError(t, r.runPipeline(context.Background()))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		var calls atomic.Int32
+		r, _ := newGatedRunner(t, &calls, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := r.runPipeline(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("no gate after the last phase", func(t *testing.T) {
+		var calls atomic.Int32
+		r, _ := newGatedRunner(t, &calls, nil)
+		r.cfg.ConfirmPhases = []Step{{Kind: StepReviewLoop}}
+		require.NoError(t, r.runPipeline(context.Background()))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+}
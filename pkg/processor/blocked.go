@@ -0,0 +1,70 @@
+package processor
+
+import "errors"
+
+// handleBlocked marks a task blocked in the plan file if output contains a TASK_BLOCKED signal.
+// task is the task the output belongs to, 0 takes the task from the signal or the current task.
+// returns true if the task was marked. malformed signals and unknown tasks only produce a warning,
+// the output is then handled as if there was no signal.
+func (r *Runner) handleBlocked(output, planFile string, task int) (bool, error) {
+	payload, err := ParseBlockedPayload(output)
+	if errors.Is(err, ErrNoBlockedSignal) {
+		return false, nil
+	}
+	if err != nil {
+		r.log.Print("warning: %v", err)
+		return false, nil
+	}
+
+	if task == 0 {
+		task = payload.Task
+	}
+	if task == 0 {
+		task = r.currentTask()
+	}
+
+	doc, err := r.planGrammar().Load(planFile)
+	if err != nil {
+		return false, err
+	}
+	if err := doc.MarkTaskBlocked(task, payload.Reason); err != nil {
+		r.log.Print("warning: can't mark task blocked: %v", err)
+		return false, nil
+	}
+	if err := doc.Save(planFile); err != nil {
+		return false, err
+	}
+
+	r.log.Print("task %d blocked: %s", task, payload.Reason)
+	return true, nil
+}
+
+// logBlockedSummary writes the tasks marked blocked in the plan to the progress log.
+func (r *Runner) logBlockedSummary() {
+	if r.cfg.PlanFile == "" {
+		return
+	}
+	tasks, err := r.readPlanTasks(r.cfg.PlanFile)
+	if err != nil {
+		return
+	}
+
+	var blocked []planTask
+	for _, t := range tasks {
+		if t.Blocked {
+			blocked = append(blocked, t)
+		}
+	}
+	if len(blocked) == 0 {
+		return
+	}
+
+	r.log.PrintSection(NewGenericSection("blocked tasks"))
+	for _, t := range blocked {
+		reason := t.BlockedReason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		r.log.Print("task %d: %s - %s", t.Number, t.Title, reason)
+	}
+}
@@ -0,0 +1,100 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+const blockedOutput = "no credentials\n<<<RALPHEX:TASK_BLOCKED>>>\n" +
+	`{"reason": "AWS credentials are not configured"}` + "\n<<<RALPHEX:END>>>"
+
+func TestRunner_RunTaskPhase_Blocked(t *testing.T) {
+	plan := "# Plan\n### Task 1: Deploy\n- [ ] apply manifest\n### Task 2: Docs\n- [ ] update readme\n"
+
+	t.Run("blocked task is marked and the next one runs", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(plan), 0o600))
+
+		calls := 0
+		task := funcExecutor(func(context.Context, string) executor.Result {
+			calls++
+			if calls == 1 {
+				return executor.Result{Output: blockedOutput}
+			}
+			content, err := os.ReadFile(planFile) //nolint:gosec // test file in temp dir
+			require.NoError(t, err)
+			updated := []byte(string(content[:len(content)-len("- [ ] update readme\n")]) + "- [x] update readme\n")
+			require.NoError(t, os.WriteFile(planFile, updated, 0o600))
+			return executor.Result{Output: "done", Signal: SignalCompleted}
+		})
+
+		log := newMockLogger("")
+		cfg := Config{Mode: ModeFull, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		r := newRunner(cfg, log, task, task)
+		require.NoError(t, r.runTaskPhase(context.Background(), 1))
+		assert.Equal(t, 2, calls)
+
+		content, err := os.ReadFile(planFile) //nolint:gosec // test file in temp dir
+		require.NoError(t, err)
+		assert.Equal(t, "# Plan\n### Task 1: Deploy\n\n> blocked: AWS credentials are not configured\n- [~] apply manifest\n"+
+			"### Task 2: Docs\n- [x] update readme\n", string(content))
+
+		r.logBlockedSummary()
+		prints := log.PrintCalls()
+		last := prints[len(prints)-1]
+		assert.Equal(t, "task %d: %s - %s", last.Format)
+		assert.Equal(t, []any{1, "Deploy", "AWS credentials are not configured"}, last.Args)
+	})
+
+	t.Run("last task blocked ends the phase", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: Deploy\n- [ ] apply manifest\n"), 0o600))
+		task := funcExecutor(func(context.Context, string) executor.Result { return executor.Result{Output: blockedOutput} })
+
+		cfg := Config{Mode: ModeFull, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		r := newRunner(cfg, newMockLogger(""), task, task)
+		require.NoError(t, r.runTaskPhase(context.Background(), 1))
+		assert.False(t, r.hasUncompletedTasks())
+	})
+}
+
+func TestRunner_HandleBlocked(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("### Task 1: Deploy\n- [x] done\n### Task 2: Docs\n- [ ] readme\n"), 0o600))
+	cfg := Config{Mode: ModeFull, PlanFile: planFile, AppConfig: testAppConfig(t)}
+
+	t.Run("no signal", func(t *testing.T) {
+		r := newRunner(cfg, newMockLogger(""), nil, nil)
+		blocked, err := r.handleBlocked("done", planFile, 0)
+		require.NoError(t, err)
+		assert.False(t, blocked)
+	})
+
+	t.Run("completed task is not marked", func(t *testing.T) {
+		log := newMockLogger("")
+		r := newRunner(cfg, log, nil, nil)
+		out := `<<<RALPHEX:TASK_BLOCKED>>>{"task": 1, "reason": "x"}<<<RALPHEX:END>>>`
+		blocked, err := r.handleBlocked(out, planFile, 0)
+		require.NoError(t, err)
+		assert.False(t, blocked)
+		require.Len(t, log.PrintCalls(), 1)
+		assert.Equal(t, "warning: can't mark task blocked: %v", log.PrintCalls()[0].Format)
+	})
+
+	t.Run("current task is marked without task number", func(t *testing.T) {
+		r := newRunner(cfg, newMockLogger(""), nil, nil)
+		blocked, err := r.handleBlocked(blockedOutput, planFile, 0)
+		require.NoError(t, err)
+		assert.True(t, blocked)
+		tasks, err := r.readPlanTasks(planFile)
+		require.NoError(t, err)
+		assert.True(t, tasks[1].Blocked)
+	})
+}
@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"fmt"
+	"slices"
+)
+
+// DryRunReport describes what a run would do without invoking claude or codex.
+type DryRunReport struct {
+	Tasks              []DryRunTask  // incomplete plan tasks in plan order
+	ValidationCommands []string      // commands from the plan's "## Validation Commands" section
+	Phases             []DryRunPhase // pipeline phases in execution order
+	Warnings           []string      // problems that would show up during the run, e.g. unknown agents
+}
+
+// DryRunTask is an incomplete plan task.
+type DryRunTask struct {
+	Number int
+	Title  string
+}
+
+// DryRunPhase is a pipeline phase with its iteration cap and expanded prompts.
+type DryRunPhase struct {
+	Name          string         // pipeline syntax of the phase, e.g. "review_loop" or "custom:security"
+	MaxIterations int            // iteration cap of the phase
+	Skip          string         // reason the phase would be skipped, empty if it runs
+	Confirm       bool           // run waits for approval after the phase
+	Prompts       []DryRunPrompt // prompts sent by the phase, empty for skipped phases
+}
+
+// DryRunPrompt is a fully expanded prompt of a phase.
+type DryRunPrompt struct {
+	Name string // executor and purpose of the prompt, e.g. "claude" or "codex (first iteration)"
+	Text string
+}
+
+// DryRun builds the execution plan of the run: remaining tasks, phases with iteration caps and
+// the prompts each phase sends. nothing is executed and neither git nor the plan file are modified.
+// references to unknown agents are reported as warnings.
+func (r *Runner) DryRun() (DryRunReport, error) {
+	var report DryRunReport
+
+	if r.cfg.PlanFile != "" {
+		doc, err := r.planGrammar().Load(r.cfg.PlanFile)
+		if err != nil {
+			return DryRunReport{}, err
+		}
+		tasks, err := parsePlanTasks(doc)
+		if err != nil {
+			return DryRunReport{}, fmt.Errorf("parse plan tasks: %w", err)
+		}
+		for _, t := range tasks {
+			if !t.Done {
+				report.Tasks = append(report.Tasks, DryRunTask{Number: t.Number, Title: t.Title})
+			}
+		}
+		report.ValidationCommands = doc.ValidationCommands()
+	}
+
+	if err := r.validatePipeline(); err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+	}
+
+	for i, step := range r.pipeline {
+		phase := r.dryRunPhase(step)
+		phase.Confirm = i < len(r.pipeline)-1 && slices.Contains(r.cfg.ConfirmPhases, step)
+		for _, p := range phase.Prompts {
+			for _, name := range unknownAgents(p.Text) {
+				warn := fmt.Sprintf("agent %q not found, reference in %s phase is left unexpanded", name, step)
+				if !slices.Contains(report.Warnings, warn) {
+					report.Warnings = append(report.Warnings, warn)
+				}
+			}
+		}
+		report.Phases = append(report.Phases, phase)
+	}
+	return report, nil
+}
+
+// dryRunPhase describes a single pipeline step as runStep would execute it.
+func (r *Runner) dryRunPhase(step Step) DryRunPhase {
+	phase := DryRunPhase{Name: step.String()}
+	switch step.Kind {
+	case StepTasks:
+		phase.MaxIterations = r.cfg.MaxIterations
+		phase.Prompts = []DryRunPrompt{{Name: "claude", Text: r.buildTaskPrompt()}}
+	case StepReviewFirst:
+		phase.MaxIterations = 1
+		phase.Prompts = []DryRunPrompt{{Name: "claude", Text: r.buildFirstReviewPrompt()}}
+	case StepReviewLoop:
+		phase.MaxIterations = r.maxReviewIterations()
+		phase.Prompts = []DryRunPrompt{{Name: "claude", Text: r.buildSecondReviewPrompt()}}
+	case StepCodex:
+		phase.MaxIterations = r.maxCodexIterations()
+		if !r.cfg.CodexEnabled {
+			phase.Skip = "codex review disabled"
+			return phase
+		}
+		phase.Prompts = []DryRunPrompt{
+			{Name: "codex (first iteration)", Text: r.buildCodexPrompt(true, "")},
+			// codex output is only known at run time, the placeholder is kept
+			{Name: "claude (codex evaluation)", Text: r.buildCodexEvaluationPrompt("{{CODEX_OUTPUT}}")},
+		}
+	case StepCustom:
+		phase.MaxIterations = r.maxReviewIterations()
+		prompt, ok := r.customPrompt(step.Name)
+		if !ok {
+			phase.Skip = fmt.Sprintf("prompt %s.txt not found", step.Name)
+			return phase
+		}
+		phase.Prompts = []DryRunPrompt{{Name: "claude", Text: r.replacePromptVariables(prompt)}}
+	default:
+		phase.Skip = fmt.Sprintf("unknown phase kind %q", step.Kind)
+	}
+	return phase
+}
+
+// unknownAgents returns names of agent references left unexpanded in a prompt.
+func unknownAgents(prompt string) []string {
+	var names []string
+	for _, m := range agentRefPattern.FindAllStringSubmatch(prompt, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
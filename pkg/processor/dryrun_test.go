@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunner_DryRun(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "feature.md")
+	plan := `# Feature
+
+## Validation Commands
+- ` + "`go test ./...`" + `
+
+### Task 1: Add model
+- [x] create struct
+
+### Task 2: Add API client
+- [ ] implement client
+
+### Task 3: Wire client
+- [ ] wire it
+`
+	require.NoError(t, os.WriteFile(planFile, []byte(plan), 0o600))
+
+	appCfg := testAppConfig(t)
+	appCfg.CustomPrompts = map[string]string{"security": "check {{PLAN_FILE}} with {{agent:pentester}}"}
+	pipeline, err := ParsePipeline("tasks, review_first, review_loop, codex, custom:security, custom:docs")
+	require.NoError(t, err)
+
+	log := newMockLogger("progress-feature.txt")
+	r := NewWithExecutors(Config{PlanFile: planFile, ProgressPath: "progress-feature.txt", Mode: ModeFull,
+		MaxIterations: 50, Pipeline: pipeline, AppConfig: appCfg}, log, nil, nil)
+
+	report, err := r.DryRun()
+	require.NoError(t, err)
+
+	assert.Equal(t, []DryRunTask{{Number: 2, Title: "Add API client"}, {Number: 3, Title: "Wire client"}}, report.Tasks)
+	assert.Equal(t, []string{"go test ./..."}, report.ValidationCommands)
+
+	require.Len(t, report.Phases, 6)
+	tests := []struct {
+		name    string
+		maxIter int
+		skip    string
+		prompts int
+	}{
+		{name: "tasks", maxIter: 50, prompts: 1},
+		{name: "review_first", maxIter: 1, prompts: 1},
+		{name: "review_loop", maxIter: 5, prompts: 1},
+		{name: "codex", maxIter: 10, skip: "codex review disabled"},
+		{name: "custom:security", maxIter: 5, prompts: 1},
+		{name: "custom:docs", maxIter: 5, skip: "prompt docs.txt not found"},
+	}
+	for i, tc := range tests {
+		phase := report.Phases[i]
+		assert.Equal(t, tc.name, phase.Name)
+		assert.Equal(t, tc.maxIter, phase.MaxIterations, tc.name)
+		assert.Equal(t, tc.skip, phase.Skip, tc.name)
+		assert.Len(t, phase.Prompts, tc.prompts, tc.name)
+	}
+
+	assert.Contains(t, report.Phases[0].Prompts[0].Text, planFile)
+	assert.Contains(t, report.Phases[0].Prompts[0].Text, "progress-feature.txt")
+	assert.NotContains(t, report.Phases[0].Prompts[0].Text, "{{PLAN_FILE}}")
+	assert.Equal(t, "check "+planFile+" with {{agent:pentester}}", report.Phases[4].Prompts[0].Text)
+
+	assert.Equal(t, []string{
+		`custom phase "docs": prompt docs.txt not found in prompts directory`,
+		`agent "pentester" not found, reference in custom:security phase is left unexpanded`,
+	}, report.Warnings)
+}
+
+func TestRunner_DryRun_Codex(t *testing.T) {
+	r := NewWithExecutors(Config{Mode: ModeCodexOnly, MaxIterations: 50, CodexEnabled: true,
+		ConfirmPhases: DefaultPipeline(ModeCodexOnly), AppConfig: testAppConfig(t)}, newMockLogger(""), nil, nil)
+
+	report, err := r.DryRun()
+	require.NoError(t, err)
+	assert.Empty(t, report.Tasks)
+	assert.Empty(t, report.Warnings)
+
+	require.Len(t, report.Phases, 2)
+	codex := report.Phases[0]
+	assert.Equal(t, "codex", codex.Name)
+	require.Len(t, codex.Prompts, 2)
+	assert.Equal(t, "codex (first iteration)", codex.Prompts[0].Name)
+	assert.Contains(t, codex.Prompts[0].Text, "git diff master...HEAD")
+	assert.Equal(t, "claude (codex evaluation)", codex.Prompts[1].Name)
+	assert.Contains(t, codex.Prompts[1].Text, "{{CODEX_OUTPUT}}")
+	assert.True(t, codex.Confirm)
+	assert.False(t, report.Phases[1].Confirm, "no approval gate after the last phase")
+}
+
+func TestRunner_DryRun_MissingPlan(t *testing.T) {
+	r := NewWithExecutors(Config{PlanFile: filepath.Join(t.TempDir(), "missing.md"), Mode: ModeFull,
+		AppConfig: testAppConfig(t)}, newMockLogger(""), nil, nil)
+	_, err := r.DryRun()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read plan file")
+}
@@ -5,15 +5,19 @@ import "time"
 // TestRunnerConfig provides test access to runner's internal configuration.
 // this file is only compiled during test builds (`go test`).
 type TestRunnerConfig struct {
-	IterationDelay time.Duration
-	TaskRetryCount int
+	IterationDelay    time.Duration
+	TaskRetryCount    int
+	ValidationTimeout time.Duration
+	ValidationRetries int
 }
 
 // TestConfig returns internal configuration values for testing.
 func (r *Runner) TestConfig() TestRunnerConfig {
 	return TestRunnerConfig{
-		IterationDelay: r.iterationDelay,
-		TaskRetryCount: r.taskRetryCount,
+		IterationDelay:    r.iterationDelay,
+		TaskRetryCount:    r.taskRetryCount,
+		ValidationTimeout: r.validationTimeout,
+		ValidationRetries: r.validationRetries,
 	}
 }
 
@@ -21,3 +25,8 @@ func (r *Runner) TestConfig() TestRunnerConfig {
 func (r *Runner) TestHasUncompletedTasks() bool {
 	return r.hasUncompletedTasks()
 }
+
+// TestSetRateLimitBackoff overrides the initial rate limit backoff for testing.
+func (r *Runner) TestSetRateLimitBackoff(d time.Duration) {
+	r.rateLimitBackoff = d
+}
@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/runfile"
+)
+
+// SignalFindings starts the block of structured review findings, the block ends with <<<RALPHEX:END>>>.
+const SignalFindings = "<<<RALPHEX:FINDINGS>>>"
+
+// findingsSignalRe matches the FINDINGS signal block with JSON array payload
+var findingsSignalRe = regexp.MustCompile(`<<<RALPHEX:FINDINGS>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
+
+// ParseFindings extracts findings from all FINDINGS blocks of the output.
+// returns no findings and no error if the output has no block. malformed blocks are skipped
+// and reported in the error, findings of valid blocks are returned anyway. severity and status
+// are normalized to lower case, unknown severity becomes info and unknown status deferred.
+func ParseFindings(output string) ([]runfile.Finding, error) {
+	if !strings.Contains(output, SignalFindings) {
+		return nil, nil
+	}
+
+	matches := findingsSignalRe.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil, errors.New("malformed findings signal: missing END marker")
+	}
+
+	var findings []runfile.Finding
+	var errs []error
+	for _, m := range matches {
+		var block []runfile.Finding
+		if err := json.Unmarshal([]byte(m[1]), &block); err != nil {
+			errs = append(errs, fmt.Errorf("malformed findings signal: invalid JSON: %w", err))
+			continue
+		}
+		for _, f := range block {
+			if strings.TrimSpace(f.Description) == "" {
+				continue
+			}
+			f.Severity = normalizeValue(f.Severity, runfile.SeverityInfo,
+				runfile.SeverityCritical, runfile.SeverityMajor, runfile.SeverityMinor, runfile.SeverityInfo)
+			f.Status = normalizeValue(f.Status, runfile.FindingDeferred, runfile.FindingFixed, runfile.FindingRejected, runfile.FindingDeferred)
+			findings = append(findings, f)
+		}
+	}
+	return findings, errors.Join(errs...)
+}
+
+// normalizeValue returns the lower-cased value if it is one of allowed, def otherwise.
+func normalizeValue(value, def string, allowed ...string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+	return def
+}
+
+// initReport prepares the review report of the run. a resumed run continues the saved report,
+// a new run removes the report left by a previous run.
+func (r *Runner) initReport() {
+	if r.cfg.ReportPath == "" {
+		return
+	}
+	if r.cfg.Resume {
+		report, err := runfile.LoadReport(r.cfg.ReportPath)
+		if err != nil {
+			r.log.Print("warning: %v", err)
+			return
+		}
+		r.report = report
+		return
+	}
+	if err := os.Remove(r.cfg.ReportPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		r.log.Print("warning: failed to remove review report: %v", err)
+	}
+}
+
+// recordFindings parses the FINDINGS block of a review output and adds the findings
+// to the review report of the run as an iteration of the current phase.
+// outputs without the block are ignored, failures are logged since the report is informational.
+func (r *Runner) recordFindings(iteration int, output string) {
+	if r.cfg.ReportPath == "" || !strings.Contains(output, SignalFindings) {
+		return
+	}
+	findings, err := ParseFindings(output)
+	if err != nil {
+		r.log.Print("warning: %v", err)
+		if len(findings) == 0 {
+			return
+		}
+	}
+	if findings == nil {
+		findings = []runfile.Finding{} // an iteration without findings is stored as an empty list
+	}
+
+	if r.report == nil {
+		r.report = &runfile.ReviewReport{}
+	}
+	r.report.Iterations = append(r.report.Iterations, runfile.ReviewIteration{
+		Phase:     r.currentPhase(),
+		Iteration: iteration,
+		Time:      time.Now(),
+		Findings:  findings,
+	})
+	if saveErr := r.report.Save(r.cfg.ReportPath); saveErr != nil {
+		r.log.Print("warning: failed to save review report: %v", saveErr)
+	}
+}
@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/runfile"
+)
+
+func TestParseFindings(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    []runfile.Finding
+		wantErr string
+	}{
+		{name: "no block", output: "fixed everything"},
+		{name: "findings",
+			output: "review done\n<<<RALPHEX:FINDINGS>>>\n" +
+				`[{"file":"main.go","line":10,"severity":"Major","category":"bug","description":"nil check","status":"fixed"},` +
+				`{"severity":"minor","description":"naming","status":"rejected"}]` + "\n<<<RALPHEX:END>>>",
+			want: []runfile.Finding{
+				{File: "main.go", Line: 10, Severity: runfile.SeverityMajor, Category: "bug", Description: "nil check", Status: runfile.FindingFixed},
+				{Severity: runfile.SeverityMinor, Description: "naming", Status: runfile.FindingRejected},
+			}},
+		{name: "unknown values normalized and empty description dropped",
+			output: `<<<RALPHEX:FINDINGS>>>[{"severity":"blocker","description":"x","status":"todo"},{"severity":"major"}]<<<RALPHEX:END>>>`,
+			want:   []runfile.Finding{{Severity: runfile.SeverityInfo, Description: "x", Status: runfile.FindingDeferred}}},
+		{name: "empty list", output: "<<<RALPHEX:FINDINGS>>>[]<<<RALPHEX:END>>>"},
+		{name: "several blocks, one malformed",
+			output: `<<<RALPHEX:FINDINGS>>>[{"severity":"critical","description":"a","status":"fixed"}]<<<RALPHEX:END>>>` +
+				"\n<<<RALPHEX:FINDINGS>>>[{oops}]<<<RALPHEX:END>>>",
+			want:    []runfile.Finding{{Severity: runfile.SeverityCritical, Description: "a", Status: runfile.FindingFixed}},
+			wantErr: "malformed findings signal: invalid JSON"},
+		{name: "missing end marker", output: "<<<RALPHEX:FINDINGS>>>[]", wantErr: "missing END marker"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseFindings(tc.output)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRunner_RecordFindings(t *testing.T) {
+	block := func(status string) string {
+		return "<<<RALPHEX:FINDINGS>>>\n[{\"file\":\"main.go\",\"line\":10,\"severity\":\"major\"," +
+			"\"description\":\"missing error check\",\"status\":\"" + status + "\"}]\n<<<RALPHEX:END>>>"
+	}
+
+	t.Run("codex evaluations are recorded per iteration", func(t *testing.T) {
+		reportPath := filepath.Join(t.TempDir(), "progress.review-report.json")
+		var codexCalls, evalCalls atomic.Int32
+		codex := outputs(&codexCalls, "- main.go:10 missing error check", "NO ISSUES FOUND")
+		claude := funcExecutor(func(context.Context, string) executor.Result {
+			if evalCalls.Add(1) == 1 {
+				return executor.Result{Output: "fixed\n" + block("fixed")}
+			}
+			return executor.Result{Output: "done\n<<<RALPHEX:FINDINGS>>>[]<<<RALPHEX:END>>>", Signal: SignalCodexDone}
+		})
+
+		cfg := Config{Mode: ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+			ReportPath: reportPath, AppConfig: testAppConfig(t)}
+		r := newRunner(cfg, newMockLogger(""), claude, claude, externalReviewer{name: "codex", exec: codex})
+		require.NoError(t, r.runCodexLoop(context.Background(), 1, ""))
+
+		report, err := runfile.LoadReport(reportPath)
+		require.NoError(t, err)
+		require.Len(t, report.Iterations, 2)
+		assert.Equal(t, "codex", report.Iterations[0].Phase)
+		assert.Equal(t, 1, report.Iterations[0].Iteration)
+		assert.Equal(t, []runfile.Finding{{File: "main.go", Line: 10, Severity: runfile.SeverityMajor, Description: "missing error check",
+			Status: runfile.FindingFixed}}, report.Iterations[0].Findings)
+		assert.Equal(t, 2, report.Iterations[1].Iteration)
+		assert.Empty(t, report.Iterations[1].Findings)
+	})
+
+	t.Run("new run replaces report, resumed run continues it", func(t *testing.T) {
+		reportPath := filepath.Join(t.TempDir(), "progress.review-report.json")
+		old := runfile.ReviewReport{Iterations: []runfile.ReviewIteration{{Phase: "review", Iteration: 1, Findings: []runfile.Finding{}}}}
+
+		cfg := Config{Mode: ModeCodexOnly, ReportPath: reportPath, AppConfig: testAppConfig(t)}
+		require.NoError(t, old.Save(reportPath))
+		r := newRunner(cfg, newMockLogger(""), nil, nil)
+		r.initReport()
+		assert.NoFileExists(t, reportPath)
+
+		cfg.Resume = true
+		require.NoError(t, old.Save(reportPath))
+		r = newRunner(cfg, newMockLogger(""), nil, nil)
+		r.initReport()
+		r.recordFindings(2, block("deferred"))
+		report, err := runfile.LoadReport(reportPath)
+		require.NoError(t, err)
+		require.Len(t, report.Iterations, 2)
+		assert.Equal(t, runfile.FindingDeferred, report.Iterations[1].Findings[0].Status)
+	})
+
+	t.Run("output without block is ignored", func(t *testing.T) {
+		reportPath := filepath.Join(t.TempDir(), "progress.review-report.json")
+		r := newRunner(Config{Mode: ModeReview, ReportPath: reportPath, AppConfig: testAppConfig(t)}, newMockLogger(""), nil, nil)
+		r.recordFindings(1, "fixed main.go")
+		assert.NoFileExists(t, reportPath)
+	})
+}
@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// hook names, the config keys are prefixed with "hook_"
+const (
+	hookPreTask   = "pre_task"
+	hookPostTask  = "post_task"
+	hookPrePhase  = "pre_phase"
+	hookPostRun   = "post_run"
+	hookOnFailure = "on_failure"
+)
+
+// hookEnv describes the point of the run a hook is executed at.
+type hookEnv struct {
+	phase     string
+	task      int // plan task number, 0 if not task related
+	iteration int
+	result    string
+	err       error
+}
+
+// hookCommand returns the configured command of the hook, empty if not configured.
+func (r *Runner) hookCommand(name string) string {
+	if r.cfg.AppConfig == nil {
+		return ""
+	}
+	switch name {
+	case hookPreTask:
+		return r.cfg.AppConfig.HookPreTask
+	case hookPostTask:
+		return r.cfg.AppConfig.HookPostTask
+	case hookPrePhase:
+		return r.cfg.AppConfig.HookPrePhase
+	case hookPostRun:
+		return r.cfg.AppConfig.HookPostRun
+	case hookOnFailure:
+		return r.cfg.AppConfig.HookOnFailure
+	}
+	return ""
+}
+
+// taskHookEnv returns the post-task hook environment for the claude result of a task iteration.
+func taskHookEnv(env hookEnv, result executor.Result) hookEnv {
+	env.result = "done"
+	if result.Signal == SignalFailed {
+		env.result = "failed"
+	}
+	return env
+}
+
+// runHook runs the hook command in dir with RALPHEX_* environment, output goes to the progress log.
+// returns an error if the hook exits non-zero, does nothing if the hook is not configured.
+func (r *Runner) runHook(ctx context.Context, name, dir string, env hookEnv) error {
+	command := r.hookCommand(name)
+	if command == "" {
+		return nil
+	}
+
+	r.log.Print("hook %s: %s", name, command)
+	output, err := executor.RunShell(ctx, dir, command, r.hookEnviron(name, env))
+	if output = strings.TrimRight(output, "\n"); output != "" {
+		r.log.PrintAligned(output)
+	}
+	if err != nil {
+		return fmt.Errorf("hook %s: %w", name, err)
+	}
+	return nil
+}
+
+// hookEnviron returns environment variables describing the run for a hook.
+func (r *Runner) hookEnviron(name string, env hookEnv) []string {
+	progressFile := ""
+	if r.log != nil {
+		progressFile = r.log.Path()
+	}
+	branch := ""
+	if r.git != nil {
+		branch, _ = r.git.CurrentBranch() // branch is informational, empty if unknown
+	}
+	errText := ""
+	if env.err != nil {
+		errText = env.err.Error()
+	}
+	return []string{
+		"RALPHEX_HOOK=" + name,
+		"RALPHEX_MODE=" + string(r.cfg.Mode),
+		"RALPHEX_PLAN_FILE=" + r.cfg.PlanFile,
+		"RALPHEX_PROGRESS_FILE=" + progressFile,
+		"RALPHEX_BRANCH=" + branch,
+		"RALPHEX_PHASE=" + env.phase,
+		"RALPHEX_TASK=" + strconv.Itoa(env.task),
+		"RALPHEX_ITERATION=" + strconv.Itoa(env.iteration),
+		"RALPHEX_RESULT=" + env.result,
+		"RALPHEX_ERROR=" + errText,
+	}
+}
+
+// runFinalHooks runs hook_on_failure when the run failed and hook_post_run in any case.
+// the run has already ended, hook failures are reported as warnings. interrupted runs skip the hooks.
+func (r *Runner) runFinalHooks(ctx context.Context, runErr error) {
+	if ctx.Err() != nil {
+		return
+	}
+	env := hookEnv{phase: r.currentPhase(), result: "success", err: runErr}
+	if runErr != nil {
+		env.result = "failure"
+		if err := r.runHook(ctx, hookOnFailure, "", env); err != nil {
+			r.log.Print("warning: %v", err)
+		}
+	}
+	if err := r.runHook(ctx, hookPostRun, "", env); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+}
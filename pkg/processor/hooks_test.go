@@ -0,0 +1,116 @@
+//go:build unix
+
+package processor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestRunner_Hooks(t *testing.T) {
+	pipeline, err := processor.ParsePipeline("tasks")
+	require.NoError(t, err)
+
+	// setup returns a plan file and a hook command appending its environment to a log file
+	setup := func(t *testing.T, plan string) (planFile, hookCmd, hookLog string) {
+		t.Helper()
+		dir := t.TempDir()
+		planFile = filepath.Join(dir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(plan), 0o600))
+		hookLog = filepath.Join(dir, "hooks.log")
+		hookCmd = `echo "$RALPHEX_HOOK phase=$RALPHEX_PHASE task=$RALPHEX_TASK iter=$RALPHEX_ITERATION ` +
+			`result=$RALPHEX_RESULT branch=$RALPHEX_BRANCH error=$RALPHEX_ERROR" >> ` + hookLog
+		return planFile, hookCmd, hookLog
+	}
+	readLines := func(t *testing.T, path string) []string {
+		t.Helper()
+		data, err := os.ReadFile(path) //nolint:gosec // test file
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+
+	t.Run("hooks run with run environment", func(t *testing.T) {
+		planFile, hookCmd, hookLog := setup(t, "# Plan\n### Task 1: first\n- [x] done")
+		appCfg := testAppConfig(t)
+		appCfg.HookPrePhase, appCfg.HookPreTask, appCfg.HookPostTask = hookCmd, hookCmd, hookCmd
+		appCfg.HookPostRun, appCfg.HookOnFailure = hookCmd, hookCmd
+
+		claude := newMockExecutor([]executor.Result{{Output: "done", Signal: processor.SignalCompleted}})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(&mocks.GitRepoMock{
+			CurrentBranchFunc: func() (string, error) { return "feature", nil },
+			HeadHashFunc:      func() (string, error) { return "abc", nil },
+			BaseRefFunc:       func(string) string { return "master" },
+		})
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Equal(t, []string{
+			"pre_phase phase=tasks task=0 iter=1 result= branch=feature error=",
+			"pre_task phase=tasks task=0 iter=1 result= branch=feature error=",
+			"post_task phase=tasks task=0 iter=1 result=done branch=feature error=",
+			"post_run phase=tasks task=0 iter=0 result=success branch=feature error=",
+		}, readLines(t, hookLog))
+	})
+
+	t.Run("task number of the current task", func(t *testing.T) {
+		planFile, hookCmd, hookLog := setup(t, "# Plan\n### Task 1: first\n- [x] done\n### Task 2: second\n- [ ] todo")
+		appCfg := testAppConfig(t)
+		appCfg.HookPreTask = hookCmd
+
+		claude := newMockExecutor([]executor.Result{{Output: "working"}})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 1, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		require.Error(t, r.Run(context.Background()))
+
+		assert.Equal(t, []string{"pre_task phase=tasks task=2 iter=1 result= branch= error="}, readLines(t, hookLog))
+	})
+
+	t.Run("failing hook aborts the run", func(t *testing.T) {
+		planFile, hookCmd, hookLog := setup(t, "# Plan\n- [ ] todo")
+		appCfg := testAppConfig(t)
+		appCfg.HookPreTask = "echo db is down; exit 2"
+		appCfg.HookOnFailure, appCfg.HookPostRun = hookCmd, hookCmd
+
+		claude := newMockExecutor(nil)
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "hook pre_task: command wait: exit status 2")
+		assert.Empty(t, claude.RunCalls())
+
+		require.NotEmpty(t, log.PrintAlignedCalls())
+		assert.Equal(t, "db is down", log.PrintAlignedCalls()[0].Text)
+		assert.Equal(t, []string{
+			"on_failure phase=tasks task=0 iter=0 result=failure branch= error=tasks phase: hook pre_task: command wait: exit status 2",
+			"post_run phase=tasks task=0 iter=0 result=failure branch= error=tasks phase: hook pre_task: command wait: exit status 2",
+		}, readLines(t, hookLog))
+	})
+
+	t.Run("failing post-run hook is a warning", func(t *testing.T) {
+		planFile, _, _ := setup(t, "# Plan\n- [x] done")
+		appCfg := testAppConfig(t)
+		appCfg.HookPostRun = "exit 1"
+
+		claude := newMockExecutor([]executor.Result{{Output: "done", Signal: processor.SignalCompleted}})
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 3, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		assert.NoError(t, r.Run(context.Background()))
+	})
+}
@@ -0,0 +1,401 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// taskRun is a task being executed in its own git worktree.
+type taskRun struct {
+	task      planTask
+	iteration int    // iteration the run was started in
+	tmpDir    string // temp directory holding the worktree
+	worktree  string // worktree path
+	branch    string // temporary branch of the worktree
+}
+
+// taskRunResult is the outcome of claude execution for a task run.
+type taskRunResult struct {
+	run    *taskRun
+	result executor.Result
+	err    error // validation failure or error outside of claude execution
+}
+
+// runParallelTaskPhase executes independent plan tasks concurrently, each in its own git worktree
+// on a temporary branch. finished tasks are merged back into the current branch one at a time,
+// a merge conflict stops the phase and keeps the task branch for manual resolution.
+// plan items outside of task sections are completed by the sequential task loop afterwards.
+func (r *Runner) runParallelTaskPhase(ctx context.Context, start int) error {
+	if r.git == nil {
+		r.log.Print("warning: parallel task execution requires a git repository, running tasks sequentially")
+		return r.runTaskPhase(ctx, start)
+	}
+
+	planRel, err := r.planPathInRepo()
+	if err != nil {
+		return err
+	}
+
+	// worktrees are created from HEAD, so they only see the committed plan
+	planChanged, err := r.git.FileHasChanges(r.cfg.PlanFile)
+	if err != nil {
+		return fmt.Errorf("check plan file status: %w", err)
+	}
+	if planChanged {
+		return fmt.Errorf("plan file %s has uncommitted changes, commit it before running tasks in parallel", r.cfg.PlanFile)
+	}
+
+	r.checkpoint(start, "")
+	if err := r.runTasksInWorktrees(ctx, planRel, start); err != nil {
+		return err
+	}
+
+	if r.hasUncompletedTasks() {
+		r.log.Print("all task sections merged, plan still has [ ] items, continuing sequentially...")
+		return r.runTaskPhase(ctx, start)
+	}
+	r.log.PrintRaw("\nall tasks completed, starting code review...\n")
+	return nil
+}
+
+// runTasksInWorktrees schedules ready tasks up to the parallel limit until all task sections are done.
+// the start iteration offsets the iteration counter used for section headers and max iterations.
+func (r *Runner) runTasksInWorktrees(ctx context.Context, planRel string, start int) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	running := make(map[int]*taskRun)
+	attempts := make(map[int]int)
+	results := make(chan taskRunResult)
+	var wg sync.WaitGroup
+
+	// on exit stop remaining workers and remove their worktrees.
+	// every worker sends exactly one result, so draining until close never blocks a worker
+	defer func() {
+		cancel()
+		go func() { wg.Wait(); close(results) }()
+		for res := range results {
+			r.removeTaskWorktree(res.run)
+		}
+	}()
+
+	iteration := start - 1
+	for {
+		tasks, err := r.readPlanTasks(r.cfg.PlanFile)
+		if err != nil {
+			return err
+		}
+
+		exclude := make(map[int]bool, len(running))
+		for n := range running {
+			exclude[n] = true
+		}
+		for _, task := range readyTasks(tasks, exclude) {
+			if len(running) >= r.cfg.ParallelTasks {
+				break
+			}
+			if iteration >= r.cfg.MaxIterations {
+				if len(running) == 0 {
+					return fmt.Errorf("%w: %d iterations without completion", ErrMaxIterations, r.cfg.MaxIterations)
+				}
+				break
+			}
+			iteration++
+
+			run, err := r.startTaskRun(task, iteration)
+			if err != nil {
+				return err
+			}
+			running[task.Number] = run
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results <- r.executeTaskRun(ctx, run, planRel)
+			}()
+		}
+
+		if len(running) == 0 {
+			if allTasksDone(tasks) {
+				return nil
+			}
+			return errors.New("no runnable tasks left, check task dependencies for cycles")
+		}
+
+		var res taskRunResult
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("task phase: %w", ctx.Err())
+		case res = <-results:
+		}
+		delete(running, res.run.task.Number)
+
+		if err := r.finishTaskRun(res, planRel); err != nil {
+			var retryErr *taskRetryError
+			if !errors.As(err, &retryErr) || attempts[res.run.task.Number] >= r.taskRetryCount {
+				return err
+			}
+			attempts[res.run.task.Number]++
+			r.log.Print("task %d failed (%v), retrying...", res.run.task.Number, retryErr.err)
+			time.Sleep(r.iterationDelay)
+		}
+	}
+}
+
+// taskRetryError marks a task run failure that can be retried.
+type taskRetryError struct {
+	err error
+}
+
+func (e *taskRetryError) Error() string { return e.err.Error() }
+func (e *taskRetryError) Unwrap() error { return e.err }
+
+// startTaskRun creates a worktree on a temporary branch for the task.
+func (r *Runner) startTaskRun(task planTask, iteration int) (*taskRun, error) {
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("ralphex-task-%d-", task.Number))
+	if err != nil {
+		return nil, fmt.Errorf("create worktree directory: %w", err)
+	}
+	run := &taskRun{
+		task:      task,
+		iteration: iteration,
+		tmpDir:    tmpDir,
+		worktree:  filepath.Join(tmpDir, "worktree"),
+		branch:    fmt.Sprintf("ralphex-task-%d-%d", task.Number, time.Now().UnixNano()),
+	}
+	if err := r.git.AddWorktree(run.worktree, run.branch); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("task %d: %w", task.Number, err)
+	}
+
+	r.log.PrintSection(NewTaskIterationSection(iteration))
+	r.log.Print("task %d: %s (worktree %s, branch %s)", task.Number, task.Title, run.worktree, run.branch)
+	return run, nil
+}
+
+// executeTaskRun runs claude for the task in its worktree and checks the result with validation commands.
+// failed validation re-runs claude with the failure report until it passes or retries are exhausted.
+// task hooks run in the worktree around each claude run. a question is answered and the task
+// re-run with the answer, questions of tasks running side by side are asked one at a time.
+func (r *Runner) executeTaskRun(ctx context.Context, run *taskRun, planRel string) taskRunResult {
+	ctx = withUsageTask(executor.WithWorkDir(ctx, run.worktree), run.task.Number)
+	planFile := filepath.Join(run.worktree, planRel)
+	prompt := r.buildParallelTaskPrompt(run.task, planFile)
+
+	iterPrompt := r.withNotes(prompt)
+	resumeID := "" // session of the run failing validation, continued by the re-run if resume_session is enabled
+	env := hookEnv{phase: r.currentPhase(), task: run.task.Number, iteration: run.iteration}
+	for failures, questions := 0, 0; ; {
+		if err := r.runHook(ctx, hookPreTask, run.worktree, env); err != nil {
+			return taskRunResult{run: run, err: err}
+		}
+		result := r.task.Run(r.resumeSession(ctx, resumeID), iterPrompt)
+		if result.Error != nil {
+			return taskRunResult{run: run, result: result}
+		}
+		if err := r.runHook(ctx, hookPostTask, run.worktree, taskHookEnv(env, result)); err != nil {
+			return taskRunResult{run: run, result: result, err: err}
+		}
+
+		asked, err := r.handleQuestion(ctx, run.iteration, result.Output)
+		if err != nil {
+			return taskRunResult{run: run, result: result, err: err}
+		}
+		if asked {
+			if questions++; questions >= r.cfg.MaxIterations {
+				return taskRunResult{run: run, result: result, err: fmt.Errorf("no result after %d questions", questions)}
+			}
+			r.log.Print("task %d: re-running with the answer...", run.task.Number)
+			iterPrompt = r.withNotes(prompt)
+			resumeID = result.SessionID
+			continue
+		}
+		if result.Signal == SignalFailed || strings.Contains(result.Output, SignalBlocked) {
+			return taskRunResult{run: run, result: result}
+		}
+
+		report, err := r.runValidation(ctx, run.worktree, planFile)
+		if err != nil {
+			return taskRunResult{run: run, result: result, err: err}
+		}
+		if report == "" {
+			return taskRunResult{run: run, result: result}
+		}
+		failures++
+		if failures > r.validationRetries {
+			return taskRunResult{run: run, result: result, err: fmt.Errorf("validation failed after %d attempts", failures)}
+		}
+		r.log.Print("task %d: re-running to fix validation failure (%d/%d)...", run.task.Number, failures, r.validationRetries)
+		iterPrompt = r.buildValidationFailurePrompt(r.withNotes(prompt), report)
+		resumeID = result.SessionID
+	}
+}
+
+// finishTaskRun verifies the task result and merges the task branch into the current branch.
+// returns *taskRetryError for failures that allow another attempt.
+func (r *Runner) finishTaskRun(res taskRunResult, planRel string) error {
+	run, result := res.run, res.result
+	if res.err != nil {
+		r.removeTaskWorktree(run)
+		return fmt.Errorf("task %d: %w", run.task.Number, res.err)
+	}
+	if result.Error != nil {
+		r.removeTaskWorktree(run)
+		return fmt.Errorf("task %d: claude execution: %w", run.task.Number, result.Error)
+	}
+	if result.Signal == SignalFailed {
+		r.removeTaskWorktree(run)
+		return &taskRetryError{err: fmt.Errorf("task %d execution failed (FAILED signal received)", run.task.Number)}
+	}
+
+	// a blocked task is marked in the worktree plan and merged like a completed one
+	msg := fmt.Sprintf("feat: %s", run.task.Title)
+	blocked, blockErr := r.handleBlocked(result.Output, filepath.Join(run.worktree, planRel), run.task.Number)
+	if blockErr != nil {
+		r.removeTaskWorktree(run)
+		return fmt.Errorf("task %d: %w", run.task.Number, blockErr)
+	}
+	if blocked {
+		msg = fmt.Sprintf("chore: mark task %d blocked", run.task.Number)
+	}
+
+	// claude is expected to commit its work, pick up anything left uncommitted
+	if committed, err := r.git.CommitAll(run.worktree, msg); err != nil {
+		r.removeTaskWorktree(run)
+		return fmt.Errorf("task %d: commit worktree changes: %w", run.task.Number, err)
+	} else if committed {
+		r.log.Print("task %d: committed uncommitted changes left in worktree", run.task.Number)
+	}
+
+	tasks, err := r.readPlanTasks(filepath.Join(run.worktree, planRel))
+	if err != nil {
+		r.removeTaskWorktree(run)
+		return err
+	}
+	if task, ok := findTask(tasks, run.task.Number); !ok || !task.Done {
+		r.removeTaskWorktree(run)
+		return &taskRetryError{err: fmt.Errorf("task %d still has uncompleted items", run.task.Number)}
+	}
+
+	if err := r.git.MergeBranch(run.branch, fmt.Sprintf("merge task %d: %s", run.task.Number, run.task.Title)); err != nil {
+		r.removeWorktreeOnly(run)
+		return fmt.Errorf("task %d: %w (branch %s kept for manual resolution)", run.task.Number, err, run.branch)
+	}
+	r.log.Print("task %d merged", run.task.Number)
+	r.removeTaskWorktree(run)
+	return nil
+}
+
+// removeTaskWorktree removes the worktree and the temporary branch of a task run.
+func (r *Runner) removeTaskWorktree(run *taskRun) {
+	r.removeWorktreeOnly(run)
+	if err := r.git.DeleteBranch(run.branch); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+}
+
+// removeWorktreeOnly removes the worktree of a task run, keeping its branch.
+func (r *Runner) removeWorktreeOnly(run *taskRun) {
+	if err := r.git.RemoveWorktree(run.worktree); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+	if err := os.RemoveAll(run.tmpDir); err != nil {
+		r.log.Print("warning: remove worktree directory: %v", err)
+	}
+}
+
+// readPlanTasks reads and parses task sections of the plan file at path.
+func (r *Runner) readPlanTasks(path string) ([]planTask, error) {
+	doc, err := r.planGrammar().Load(path)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := parsePlanTasks(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parse plan tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// planPathInRepo returns the plan file path relative to the repository root,
+// used to locate the plan copy inside task worktrees.
+func (r *Runner) planPathInRepo() (string, error) {
+	abs, err := filepath.Abs(r.cfg.PlanFile)
+	if err != nil {
+		return "", fmt.Errorf("resolve plan path: %w", err)
+	}
+	root := r.git.Root()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("plan file %s is outside of repository %s", r.cfg.PlanFile, root)
+	}
+	return rel, nil
+}
+
+// syncLogger serializes calls to a Logger shared by concurrently running tasks.
+type syncLogger struct {
+	mu    sync.Mutex
+	inner Logger
+}
+
+func (l *syncLogger) SetPhase(phase Phase) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inner.SetPhase(phase)
+}
+
+func (l *syncLogger) Print(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inner.Print(format, args...)
+}
+
+func (l *syncLogger) PrintRaw(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inner.PrintRaw(format, args...)
+}
+
+func (l *syncLogger) PrintSection(section Section) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inner.PrintSection(section)
+}
+
+func (l *syncLogger) PrintAligned(text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inner.PrintAligned(text)
+}
+
+func (l *syncLogger) LogQuestion(question string, options []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inner.LogQuestion(question, options)
+}
+
+func (l *syncLogger) LogAnswer(answer string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inner.LogAnswer(answer)
+}
+
+func (l *syncLogger) Path() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inner.Path()
+}
@@ -0,0 +1,393 @@
+package processor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+const parallelTestPlan = `# Plan
+
+### Task 1: Base
+- [ ] create base.txt
+
+### Task 2: Feature A
+- [ ] create a.txt
+
+### Task 3: Feature B (depends: 1)
+- [ ] create b.txt
+`
+
+// parallelTaskRe extracts the task number from the parallel task prompt.
+var parallelTaskRe = regexp.MustCompile(`work ONLY on "### Task (\d+):`)
+
+// setupParallelRepo creates a git repo with the plan committed and returns the repo and plan path.
+func setupParallelRepo(t *testing.T, plan string) (*git.Repo, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git CLI not available")
+	}
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@example.com")
+	}
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	planFile := filepath.Join(dir, "docs", "plan.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(planFile), 0o750))
+	require.NoError(t, os.WriteFile(planFile, []byte(plan), 0o600))
+
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	repo, err := git.Open(dir)
+	require.NoError(t, err)
+	return repo, planFile
+}
+
+// worktreeExecutor simulates claude working on a task inside its worktree.
+// work is called with the task number and worktree dir and returns the result to report.
+func worktreeExecutor(t *testing.T, work func(task int, dir string) executor.Result) *mocks.ExecutorMock {
+	t.Helper()
+	return &mocks.ExecutorMock{
+		RunFunc: func(ctx context.Context, prompt string) executor.Result {
+			m := parallelTaskRe.FindStringSubmatch(prompt)
+			if m == nil {
+				return executor.Result{Error: errors.New("not a parallel task prompt")}
+			}
+			var task int
+			_, _ = fmt.Sscanf(m[1], "%d", &task)
+			return work(task, executor.WorkDir(ctx))
+		},
+	}
+}
+
+// completeTask creates the task file and checks the task item in the worktree plan copy.
+func completeTask(t *testing.T, dir, file, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600))
+	planPath := filepath.Join(dir, "docs", "plan.md")
+	data, err := os.ReadFile(planPath) //nolint:gosec // test
+	require.NoError(t, err)
+	updated := strings.Replace(string(data), "- [ ] create "+file, "- [x] create "+file, 1)
+	require.NoError(t, os.WriteFile(planPath, []byte(updated), 0o600))
+}
+
+func TestRunner_ParallelTasks(t *testing.T) {
+	files := map[int]string{1: "base.txt", 2: "a.txt", 3: "b.txt"}
+
+	t.Run("runs independent tasks concurrently and merges them", func(t *testing.T) {
+		repo, planFile := setupParallelRepo(t, parallelTestPlan)
+
+		var inFlight, maxInFlight atomic.Int32
+		var mu sync.Mutex
+		var order []int
+		claude := worktreeExecutor(t, func(task int, dir string) executor.Result {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				cur := maxInFlight.Load()
+				if n <= cur || maxInFlight.CompareAndSwap(cur, n) {
+					break
+				}
+			}
+			mu.Lock()
+			order = append(order, task)
+			mu.Unlock()
+
+			time.Sleep(100 * time.Millisecond)
+			completeTask(t, dir, files[task], fmt.Sprintf("task %d\n", task))
+			return executor.Result{Output: "done"}
+		})
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Equal(t, 1, order[0], "task 1 runs first, others depend on it")
+		assert.ElementsMatch(t, []int{1, 2, 3}, order)
+		assert.Equal(t, int32(2), maxInFlight.Load(), "tasks 2 and 3 should run concurrently")
+
+		// all task files merged into the main worktree and plan fully checked
+		for _, f := range files {
+			_, err := os.Stat(filepath.Join(repo.Root(), f))
+			require.NoError(t, err, f)
+		}
+		data, err := os.ReadFile(planFile) //nolint:gosec // test
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "- [ ]")
+
+		// temporary branches cleaned up
+		out, err := exec.Command("git", "-C", repo.Root(), "branch", "--list", "ralphex-task-*").Output()
+		require.NoError(t, err)
+		assert.Empty(t, strings.TrimSpace(string(out)))
+	})
+
+	t.Run("merge conflict fails loudly and keeps branch", func(t *testing.T) {
+		repo, planFile := setupParallelRepo(t, parallelTestPlan)
+
+		claude := worktreeExecutor(t, func(task int, dir string) executor.Result {
+			// tasks 2 and 3 write the same file with different content
+			if task > 1 {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "shared.txt"), fmt.Appendf(nil, "task %d\n", task), 0o600))
+			}
+			completeTask(t, dir, files[task], "x\n")
+			return executor.Result{Output: "done"}
+		})
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 3, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+
+		err = r.Run(context.Background())
+		require.Error(t, err)
+		var conflict *git.MergeConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, []string{"shared.txt"}, conflict.Files)
+		assert.Contains(t, err.Error(), "kept for manual resolution")
+		assert.True(t, repo.BranchExists(conflict.Branch), "conflicting branch kept")
+
+		dirty, err := repo.IsDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty, "merge aborted, main worktree clean")
+	})
+
+	t.Run("failed task is retried", func(t *testing.T) {
+		repo, planFile := setupParallelRepo(t, parallelTestPlan)
+
+		var calls sync.Map
+		claude := worktreeExecutor(t, func(task int, dir string) executor.Result {
+			if _, seen := calls.LoadOrStore(task, true); !seen && task == 2 {
+				return executor.Result{Output: "broken", Signal: processor.SignalFailed}
+			}
+			completeTask(t, dir, files[task], "x\n")
+			return executor.Result{Output: "done"}
+		})
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, TaskRetryCount: 1, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+		require.NoError(t, r.Run(context.Background()))
+		assert.Len(t, claude.RunCalls(), 4)
+	})
+
+	t.Run("question is answered and task re-run with the answer", func(t *testing.T) {
+		repo, planFile := setupParallelRepo(t, parallelTestPlan)
+
+		var asked atomic.Bool
+		var mu sync.Mutex
+		var task2Prompts []string
+		claude := &mocks.ExecutorMock{RunFunc: func(ctx context.Context, prompt string) executor.Result {
+			m := parallelTaskRe.FindStringSubmatch(prompt)
+			require.NotNil(t, m)
+			var task int
+			_, _ = fmt.Sscanf(m[1], "%d", &task)
+			if task == 2 {
+				mu.Lock()
+				task2Prompts = append(task2Prompts, prompt)
+				mu.Unlock()
+				if !asked.Swap(true) {
+					return executor.Result{Output: "need a decision\n<<<RALPHEX:QUESTION>>>\n" +
+						`{"question": "Which cache?", "options": ["Redis", "In-memory"]}` + "\n<<<RALPHEX:END>>>"}
+				}
+			}
+			completeTask(t, executor.WorkDir(ctx), files[task], "x\n")
+			return executor.Result{Output: "done"}
+		}}
+		collector := &mocks.InputCollectorMock{AskQuestionFunc: func(context.Context, string, []string) (string, error) {
+			return "In-memory", nil
+		}}
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+		r.SetInputCollector(collector)
+		require.NoError(t, r.Run(context.Background()), "question is not treated as an incomplete run")
+
+		require.Len(t, collector.AskQuestionCalls(), 1)
+		assert.Equal(t, "Which cache?", collector.AskQuestionCalls()[0].Question)
+		assert.Len(t, claude.RunCalls(), 4, "only the task with the question is re-run")
+		require.Len(t, task2Prompts, 2)
+		assert.NotContains(t, task2Prompts[0], "ANSWERS TO EARLIER QUESTIONS")
+		assert.Contains(t, task2Prompts[1], "Answer: In-memory")
+		_, err = os.Stat(filepath.Join(repo.Root(), "a.txt"))
+		require.NoError(t, err)
+	})
+
+	t.Run("blocked task is marked and others continue", func(t *testing.T) {
+		repo, planFile := setupParallelRepo(t, parallelTestPlan)
+
+		claude := worktreeExecutor(t, func(task int, dir string) executor.Result {
+			if task == 2 {
+				return executor.Result{Output: "<<<RALPHEX:TASK_BLOCKED>>>\n" +
+					`{"reason": "feature flag service not available"}` + "\n<<<RALPHEX:END>>>"}
+			}
+			completeTask(t, dir, files[task], "x\n")
+			return executor.Result{Output: "done"}
+		})
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+		require.NoError(t, r.Run(context.Background()))
+		assert.Len(t, claude.RunCalls(), 3, "blocked task is not retried")
+
+		data, err := os.ReadFile(planFile) //nolint:gosec // test
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "> blocked: feature flag service not available\n- [~] create a.txt")
+		assert.NotContains(t, string(data), "- [ ]")
+		_, err = os.Stat(filepath.Join(repo.Root(), "b.txt"))
+		require.NoError(t, err)
+	})
+
+	t.Run("incomplete task after retries fails", func(t *testing.T) {
+		repo, planFile := setupParallelRepo(t, parallelTestPlan)
+
+		claude := worktreeExecutor(t, func(int, string) executor.Result {
+			return executor.Result{Output: "did nothing"}
+		})
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, TaskRetryCount: 1, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+
+		err = r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "task 1 still has uncompleted items")
+		assert.Len(t, claude.RunCalls(), 2)
+	})
+
+	t.Run("validation failure re-runs task in its worktree", func(t *testing.T) {
+		plan := "## Validation Commands\n- `test -f a.txt`\n\n### Task 1: A\n- [ ] create a.txt\n"
+		repo, planFile := setupParallelRepo(t, plan)
+
+		var attempts atomic.Int32
+		claude := worktreeExecutor(t, func(_ int, dir string) executor.Result {
+			if attempts.Add(1) == 1 {
+				// mark the item without creating the file, validation fails
+				completeTask(t, dir, "a.txt", "")
+				require.NoError(t, os.Remove(filepath.Join(dir, "a.txt")))
+				return executor.Result{Output: "done"}
+			}
+			completeTask(t, dir, "a.txt", "a\n")
+			return executor.Result{Output: "done"}
+		})
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+		require.NoError(t, r.Run(context.Background()))
+
+		calls := claude.RunCalls()
+		require.Len(t, calls, 2)
+		assert.NotContains(t, calls[0].Prompt, "VALIDATION FAILED")
+		assert.Contains(t, calls[1].Prompt, "VALIDATION FAILED")
+		assert.Contains(t, calls[1].Prompt, "test -f a.txt")
+		assert.FileExists(t, filepath.Join(repo.Root(), "a.txt"))
+	})
+
+	t.Run("dependency cycle fails", func(t *testing.T) {
+		plan := "### Task 1: A (depends: 2)\n- [ ] a\n### Task 2: B (depends: 1)\n- [ ] b\n"
+		repo, planFile := setupParallelRepo(t, plan)
+
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		claude := newMockExecutor(nil)
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		r.SetGitRepo(repo)
+
+		err = r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "check task dependencies for cycles")
+		assert.Empty(t, claude.RunCalls())
+	})
+
+	t.Run("without git repo falls back to sequential", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("### Task 1: A\n- [x] a\n"), 0o600))
+
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{{Output: "done", Signal: processor.SignalCompleted}})
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			ParallelTasks: 2, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		require.NoError(t, r.Run(context.Background()))
+
+		var warned bool
+		for _, c := range log.PrintCalls() {
+			if strings.Contains(c.Format, "running tasks sequentially") {
+				warned = true
+			}
+		}
+		assert.True(t, warned)
+	})
+}
+
+func TestRunner_ParallelTasks_UncommittedPlan(t *testing.T) {
+	repo, planFile := setupParallelRepo(t, parallelTestPlan)
+	require.NoError(t, os.WriteFile(planFile, []byte(parallelTestPlan+"\n### Task 4: New\n- [ ] new\n"), 0o600))
+
+	claude := newMockExecutor(nil)
+	pipeline, err := processor.ParsePipeline("tasks")
+	require.NoError(t, err)
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+		ParallelTasks: 2, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+	r.SetGitRepo(repo)
+
+	err = r.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has uncommitted changes")
+	assert.Empty(t, claude.RunCalls())
+}
@@ -0,0 +1,260 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/runfile"
+)
+
+// StepKind identifies the kind of phase unit in an execution pipeline.
+type StepKind string
+
+// StepKind constants for pipeline phases.
+const (
+	StepTasks       StepKind = "tasks"        // task execution loop
+	StepReviewFirst StepKind = "review_first" // single claude review pass addressing all findings
+	StepReviewLoop  StepKind = "review_loop"  // claude review loop (critical/major) until REVIEW_DONE
+	StepCodex       StepKind = "codex"        // codex external review loop
+	StepCustom      StepKind = "custom"       // claude review loop with a user-provided prompt
+)
+
+// customStepPrefix is the pipeline syntax for custom phases, e.g. "custom:security".
+const customStepPrefix = "custom:"
+
+// customNamePattern restricts custom phase names to safe prompt file names.
+var customNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Step is a single phase of an execution pipeline.
+type Step struct {
+	Kind StepKind
+	Name string // prompt name for custom steps, empty otherwise
+}
+
+// String returns the pipeline syntax for the step.
+func (s Step) String() string {
+	if s.Kind == StepCustom {
+		return customStepPrefix + s.Name
+	}
+	return string(s.Kind)
+}
+
+// ParsePipeline parses a comma-separated pipeline definition,
+// e.g. "tasks, review_first, review_loop, codex, review_loop, custom:security".
+func ParsePipeline(s string) ([]Step, error) {
+	var steps []Step
+	for part := range strings.SplitSeq(s, ",") {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+
+		if custom, ok := strings.CutPrefix(name, customStepPrefix); ok {
+			if !customNamePattern.MatchString(custom) {
+				return nil, fmt.Errorf("invalid custom phase name %q", custom)
+			}
+			steps = append(steps, Step{Kind: StepCustom, Name: custom})
+			continue
+		}
+
+		switch kind := StepKind(name); kind {
+		case StepTasks, StepReviewFirst, StepReviewLoop, StepCodex:
+			steps = append(steps, Step{Kind: kind})
+		default:
+			return nil, fmt.Errorf("unknown phase %q (valid: tasks, review_first, review_loop, codex, custom:<name>)", name)
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, errors.New("empty pipeline")
+	}
+	return steps, nil
+}
+
+// FormatPipeline returns the pipeline definition string for the steps.
+func FormatPipeline(steps []Step) string {
+	names := make([]string, 0, len(steps))
+	for _, s := range steps {
+		names = append(names, s.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// DefaultPipeline returns the built-in pipeline for the given mode.
+func DefaultPipeline(mode Mode) []Step {
+	switch mode {
+	case ModeReview:
+		return []Step{{Kind: StepReviewFirst}, {Kind: StepReviewLoop}, {Kind: StepCodex}, {Kind: StepReviewLoop}}
+	case ModeCodexOnly:
+		return []Step{{Kind: StepCodex}, {Kind: StepReviewLoop}}
+	default:
+		return []Step{{Kind: StepTasks}, {Kind: StepReviewFirst}, {Kind: StepReviewLoop}, {Kind: StepCodex}, {Kind: StepReviewLoop}}
+	}
+}
+
+// validatePipeline checks pipeline requirements before anything runs.
+func (r *Runner) validatePipeline() error {
+	for _, step := range r.pipeline {
+		switch step.Kind {
+		case StepTasks:
+			if r.cfg.PlanFile == "" {
+				return errors.New("plan file required for tasks phase")
+			}
+		case StepCustom:
+			if _, ok := r.customPrompt(step.Name); !ok {
+				return fmt.Errorf("custom phase %q: prompt %s.txt not found in prompts directory", step.Name, step.Name)
+			}
+		default:
+		}
+	}
+	return nil
+}
+
+// runPipeline executes pipeline steps in order. steps completed in a resumed run are skipped.
+func (r *Runner) runPipeline(ctx context.Context) error {
+	if err := r.validatePipeline(); err != nil {
+		return err
+	}
+
+	for i, step := range r.pipeline {
+		start, claudeResponse := r.resumePoint(i, step)
+		if start == 0 {
+			continue
+		}
+		if slices.Contains(r.skipped, i) {
+			r.log.Print("skipping %s phase (step %d), skipped at approval gate", step, i+1)
+			continue
+		}
+
+		r.step = i
+		phaseStart := r.headCommit()
+		if err := r.runHook(ctx, hookPrePhase, "", hookEnv{phase: step.String(), iteration: start}); err != nil {
+			return fmt.Errorf("%s phase: %w", step, err)
+		}
+		if err := r.runStep(ctx, step, start, claudeResponse); err != nil {
+			return fmt.Errorf("%s phase: %w", step, err)
+		}
+
+		decision, err := r.confirmPhase(ctx, i, phaseStart)
+		if err != nil {
+			return fmt.Errorf("%s phase: %w", step, err)
+		}
+		switch decision {
+		case runfile.ApprovalSkip:
+			// checkpointed right away, a resumed run must not start the skipped step
+			r.skipped = append(r.skipped, i+1)
+			r.checkpoint(1, "")
+		case runfile.ApprovalAbort:
+			return fmt.Errorf("%w after %s phase", ErrRunAborted, step)
+		default:
+		}
+	}
+
+	switch r.cfg.Mode {
+	case ModeReview:
+		r.log.Print("review phases completed successfully")
+	case ModeCodexOnly:
+		r.log.Print("codex phases completed successfully")
+	default:
+		r.log.Print("all phases completed successfully")
+	}
+	return nil
+}
+
+// runStep executes a single pipeline step starting from the given iteration.
+func (r *Runner) runStep(ctx context.Context, step Step, start int, claudeResponse string) error {
+	switch step.Kind {
+	case StepTasks:
+		r.log.SetPhase(PhaseTask)
+		r.log.PrintRaw("starting task execution phase\n")
+		if r.cfg.ParallelTasks > 1 {
+			return r.runParallelTaskPhase(ctx, start)
+		}
+		return r.runTaskPhase(ctx, start)
+
+	case StepReviewFirst:
+		r.log.SetPhase(PhaseReview)
+		r.log.PrintSection(NewGenericSection("claude review 0: all findings"))
+		r.checkpoint(1, "")
+		return r.runClaudeReview(ctx, r.buildFirstReviewPrompt())
+
+	case StepReviewLoop:
+		r.log.SetPhase(PhaseReview)
+		return r.runClaudeReviewLoop(ctx, start)
+
+	case StepCodex:
+		r.log.SetPhase(PhaseCodex)
+		r.log.PrintSection(NewGenericSection("codex external review"))
+		return r.runCodexLoop(ctx, start, claudeResponse)
+
+	case StepCustom:
+		r.log.SetPhase(PhaseReview)
+		return r.runCustomLoop(ctx, step.Name, start)
+
+	default:
+		return fmt.Errorf("unknown phase kind %q", step.Kind)
+	}
+}
+
+// customPrompt returns the user-provided prompt for a custom phase.
+func (r *Runner) customPrompt(name string) (string, bool) {
+	if r.cfg.AppConfig == nil {
+		return "", false
+	}
+	prompt, ok := r.cfg.AppConfig.CustomPrompts[name]
+	return prompt, ok && prompt != ""
+}
+
+// runCustomLoop runs claude iterations with a custom prompt until REVIEW_DONE.
+// uses the same iteration cap as the claude review loop.
+func (r *Runner) runCustomLoop(ctx context.Context, name string, start int) error {
+	prompt, _ := r.customPrompt(name)
+	sessionID := "" // session of the previous iteration
+	for i := start; i <= r.maxReviewIterations(); i++ {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("custom phase %s: %w", name, ctx.Err())
+		default:
+		}
+
+		r.log.PrintSection(NewClaudeReviewSection(i, ": "+name))
+		r.checkpoint(i, "")
+
+		result := r.review.Run(r.resumeSession(ctx, sessionID), r.withNotes(r.replacePromptVariables(prompt)))
+		if result.Error != nil {
+			return fmt.Errorf("claude execution: %w", result.Error)
+		}
+		r.recordFindings(i, result.Output)
+		sessionID = result.SessionID
+
+		asked, err := r.handleQuestion(ctx, i, result.Output)
+		if err != nil {
+			return err
+		}
+		if asked {
+			r.log.Print("re-running %s with the answer...", name)
+			time.Sleep(r.iterationDelay)
+			continue
+		}
+
+		if result.Signal == SignalFailed {
+			return fmt.Errorf("custom phase %s failed (FAILED signal received)", name)
+		}
+
+		if IsReviewDone(result.Signal) {
+			r.log.Print("custom phase %s complete - no more findings", name)
+			return nil
+		}
+
+		r.log.Print("issues fixed, running another %s iteration...", name)
+		time.Sleep(r.iterationDelay)
+	}
+
+	r.log.Print("max %s iterations reached, continuing...", name)
+	return nil
+}
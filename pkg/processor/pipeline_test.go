@@ -0,0 +1,171 @@
+package processor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []processor.Step
+		wantErr string
+	}{
+		{name: "default full", input: "tasks, review_first, review_loop, codex, review_loop",
+			want: []processor.Step{{Kind: processor.StepTasks}, {Kind: processor.StepReviewFirst},
+				{Kind: processor.StepReviewLoop}, {Kind: processor.StepCodex}, {Kind: processor.StepReviewLoop}}},
+		{name: "no spaces", input: "tasks,codex", want: []processor.Step{{Kind: processor.StepTasks}, {Kind: processor.StepCodex}}},
+		{name: "custom phase", input: "tasks, custom:security-audit",
+			want: []processor.Step{{Kind: processor.StepTasks}, {Kind: processor.StepCustom, Name: "security-audit"}}},
+		{name: "empty items skipped", input: "tasks,,codex,", want: []processor.Step{{Kind: processor.StepTasks}, {Kind: processor.StepCodex}}},
+		{name: "empty", input: " ", wantErr: "empty pipeline"},
+		{name: "unknown phase", input: "tasks, deploy", wantErr: `unknown phase "deploy"`},
+		{name: "invalid custom name", input: "custom:../etc", wantErr: "invalid custom phase name"},
+		{name: "empty custom name", input: "custom:", wantErr: "invalid custom phase name"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := processor.ParsePipeline(tc.input)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFormatPipeline(t *testing.T) {
+	steps := []processor.Step{{Kind: processor.StepTasks}, {Kind: processor.StepCustom, Name: "docs"}, {Kind: processor.StepCodex}}
+	assert.Equal(t, "tasks, custom:docs, codex", processor.FormatPipeline(steps))
+
+	// round trip
+	parsed, err := processor.ParsePipeline(processor.FormatPipeline(steps))
+	require.NoError(t, err)
+	assert.Equal(t, steps, parsed)
+}
+
+func TestDefaultPipeline(t *testing.T) {
+	tests := []struct {
+		mode processor.Mode
+		want string
+	}{
+		{mode: processor.ModeFull, want: "tasks, review_first, review_loop, codex, review_loop"},
+		{mode: processor.ModeReview, want: "review_first, review_loop, codex, review_loop"},
+		{mode: processor.ModeCodexOnly, want: "codex, review_loop"},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			assert.Equal(t, tc.want, processor.FormatPipeline(processor.DefaultPipeline(tc.mode)))
+		})
+	}
+}
+
+func TestRunner_Pipeline(t *testing.T) {
+	t.Run("runs custom pipeline in order", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+		appCfg := testAppConfig(t)
+		appCfg.CustomPrompts = map[string]string{"security": "check security of {{PLAN_FILE}}"}
+
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "task done", Signal: processor.SignalCompleted},
+			{Output: "fixed something"},
+			{Output: "all good", Signal: processor.SignalReviewDone},
+		})
+		codex := newMockExecutor(nil)
+
+		pipeline, err := processor.ParsePipeline("tasks, custom:security")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, codex)
+		require.NoError(t, r.Run(context.Background()))
+
+		calls := claude.RunCalls()
+		require.Len(t, calls, 3)
+		assert.Contains(t, calls[1].Prompt, "check security of "+planFile)
+		assert.Contains(t, calls[2].Prompt, "check security of")
+		assert.Empty(t, codex.RunCalls(), "codex not in pipeline")
+
+		var customIter []int
+		for _, s := range log.PrintSectionCalls() {
+			if s.Section.Type == processor.SectionClaudeReview {
+				customIter = append(customIter, s.Section.Iteration)
+			}
+		}
+		assert.Equal(t, []int{1, 2}, customIter)
+	})
+
+	t.Run("repeated phases run each time", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "review done", Signal: processor.SignalReviewDone},
+			{Output: "review done", Signal: processor.SignalReviewDone},
+		})
+
+		pipeline, err := processor.ParsePipeline("review_loop, review_loop")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		require.NoError(t, r.Run(context.Background()))
+		assert.Len(t, claude.RunCalls(), 2)
+	})
+
+	t.Run("missing custom prompt fails before running", func(t *testing.T) {
+		claude := newMockExecutor(nil)
+		pipeline, err := processor.ParsePipeline("review_loop, custom:nope")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), claude, newMockExecutor(nil))
+
+		err = r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "prompt nope.txt not found")
+		assert.Empty(t, claude.RunCalls())
+	})
+
+	t.Run("tasks phase requires plan file", func(t *testing.T) {
+		pipeline, err := processor.ParsePipeline("tasks")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), newMockExecutor(nil), newMockExecutor(nil))
+
+		err = r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "plan file required for tasks phase")
+	})
+
+	t.Run("failed signal in custom phase", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.CustomPrompts = map[string]string{"docs": "update docs"}
+		claude := newMockExecutor([]executor.Result{{Output: "cannot", Signal: processor.SignalFailed}})
+
+		pipeline, err := processor.ParsePipeline("custom:docs")
+		require.NoError(t, err)
+		cfg := processor.Config{Mode: processor.ModeReview, MaxIterations: 50, Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), claude, newMockExecutor(nil))
+
+		err = r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "custom:docs phase")
+		assert.Contains(t, err.Error(), "FAILED signal")
+	})
+}
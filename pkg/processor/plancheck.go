@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/lint"
+)
+
+// FindRecentPlan finds the most recently modified .md file in plansDir modified after startTime,
+// i.e. the plan created by plan mode. files in subdirectories (e.g. completed/) are ignored.
+// returns empty string if there is no such file.
+func FindRecentPlan(plansDir string, startTime time.Time) string {
+	plans, err := filepath.Glob(filepath.Join(plansDir, "*.md"))
+	if err != nil || len(plans) == 0 {
+		return ""
+	}
+
+	var recentPlan string
+	var recentTime time.Time
+	for _, plan := range plans {
+		info, statErr := os.Stat(plan)
+		if statErr != nil {
+			continue
+		}
+		// file must be modified after startTime
+		if info.ModTime().Before(startTime) {
+			continue
+		}
+		// find the most recent one
+		if recentPlan == "" || info.ModTime().After(recentTime) {
+			recentPlan = plan
+			recentTime = info.ModTime()
+		}
+	}
+	return recentPlan
+}
+
+// lintCreatedPlan checks the plan created since startTime with the plan linter. warnings are logged,
+// errors are returned as a report for claude to fix. returns empty string if the plan has no errors
+// or no plan was created, e.g. claude found an existing plan for the request.
+func (r *Runner) lintCreatedPlan(startTime time.Time) string {
+	if r.cfg.AppConfig == nil {
+		return ""
+	}
+	planFile := FindRecentPlan(r.cfg.AppConfig.PlansDir, startTime)
+	if planFile == "" {
+		return ""
+	}
+	issues, err := lint.CheckFile(planFile, r.cfg.AppConfig.PlanGrammar)
+	if err != nil {
+		r.log.Print("warning: %v", err)
+		return ""
+	}
+
+	var report strings.Builder
+	for _, issue := range issues {
+		if issue.Severity != lint.SeverityError {
+			r.log.Print("plan lint: %s", issue)
+			continue
+		}
+		if report.Len() == 0 {
+			fmt.Fprintf(&report, "%s:\n", planFile)
+		}
+		if issue.Line > 0 {
+			fmt.Fprintf(&report, "- line %d: %s\n", issue.Line, issue.Message)
+			continue
+		}
+		fmt.Fprintf(&report, "- %s\n", issue.Message)
+	}
+	return report.String()
+}
@@ -0,0 +1,153 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+func TestFindRecentPlan(t *testing.T) {
+	t.Run("finds_recently_modified_file", func(t *testing.T) {
+		dir := t.TempDir()
+		startTime := time.Now()
+
+		// create a plan file
+		planFile := filepath.Join(dir, "new-plan.md")
+		err := os.WriteFile(planFile, []byte("# New Plan"), 0o600)
+		require.NoError(t, err)
+
+		// explicitly set mod time to be after startTime
+		futureTime := startTime.Add(time.Second)
+		err = os.Chtimes(planFile, futureTime, futureTime)
+		require.NoError(t, err)
+
+		result := FindRecentPlan(dir, startTime)
+		assert.Equal(t, planFile, result)
+	})
+
+	t.Run("returns_empty_for_old_files", func(t *testing.T) {
+		dir := t.TempDir()
+		startTime := time.Now()
+
+		// create a plan file
+		planFile := filepath.Join(dir, "old-plan.md")
+		err := os.WriteFile(planFile, []byte("# Old Plan"), 0o600)
+		require.NoError(t, err)
+
+		// set mod time to be before startTime
+		pastTime := startTime.Add(-time.Hour)
+		err = os.Chtimes(planFile, pastTime, pastTime)
+		require.NoError(t, err)
+
+		result := FindRecentPlan(dir, startTime)
+		assert.Empty(t, result)
+	})
+
+	t.Run("returns_most_recent_of_multiple_files", func(t *testing.T) {
+		dir := t.TempDir()
+		startTime := time.Now()
+
+		// create first file with earlier mod time
+		plan1 := filepath.Join(dir, "plan1.md")
+		err := os.WriteFile(plan1, []byte("# Plan 1"), 0o600)
+		require.NoError(t, err)
+		time1 := startTime.Add(time.Second)
+		err = os.Chtimes(plan1, time1, time1)
+		require.NoError(t, err)
+
+		// create second file with later mod time
+		plan2 := filepath.Join(dir, "plan2.md")
+		err = os.WriteFile(plan2, []byte("# Plan 2"), 0o600)
+		require.NoError(t, err)
+		time2 := startTime.Add(2 * time.Second)
+		err = os.Chtimes(plan2, time2, time2)
+		require.NoError(t, err)
+
+		result := FindRecentPlan(dir, startTime)
+		assert.Equal(t, plan2, result)
+	})
+
+	t.Run("returns_empty_for_nonexistent_directory", func(t *testing.T) {
+		result := FindRecentPlan("/nonexistent/directory", time.Now())
+		assert.Empty(t, result)
+	})
+
+	t.Run("returns_empty_for_empty_directory", func(t *testing.T) {
+		dir := t.TempDir()
+		result := FindRecentPlan(dir, time.Now())
+		assert.Empty(t, result)
+	})
+
+	t.Run("ignores_non_md_files", func(t *testing.T) {
+		dir := t.TempDir()
+		startTime := time.Now()
+
+		// create non-md file with future mod time
+		txtFile := filepath.Join(dir, "notes.txt")
+		err := os.WriteFile(txtFile, []byte("notes"), 0o600)
+		require.NoError(t, err)
+		futureTime := startTime.Add(time.Second)
+		err = os.Chtimes(txtFile, futureTime, futureTime)
+		require.NoError(t, err)
+
+		result := FindRecentPlan(dir, startTime)
+		assert.Empty(t, result)
+	})
+}
+
+func TestRunner_RunPlanCreation_Lint(t *testing.T) {
+	plansDir := t.TempDir()
+	planFile := filepath.Join(plansDir, "2026-10-16-cache.md")
+	const goodPlan = "# Cache\n\n## Validation Commands\n- `go test ./...`\n\n### Task 1: Add cache\n- [ ] add cache\n"
+
+	var prompts []string
+	task := funcExecutor(func(_ context.Context, prompt string) executor.Result {
+		prompts = append(prompts, prompt)
+		content := strings.Replace(goodPlan, "### Task 1:", "## Task 1", 1) // first attempt has a broken header
+		if len(prompts) > 1 {
+			content = goodPlan
+		}
+		require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+		modTime := time.Now().Add(time.Second) // file system timestamps may be coarser than the clock
+		require.NoError(t, os.Chtimes(planFile, modTime, modTime))
+		return executor.Result{Output: "plan created", Signal: SignalPlanReady}
+	})
+
+	appCfg := testAppConfig(t)
+	appCfg.PlansDir = plansDir
+	cfg := Config{Mode: ModePlan, PlanDescription: "add cache", MaxIterations: 50, IterationDelayMs: 1, AppConfig: appCfg}
+	r := newRunner(cfg, newMockLogger(""), task, task)
+	r.SetInputCollector(funcCollector(func(context.Context, string, []string) (string, error) { return "", nil }))
+	require.NoError(t, r.runPlanCreation(context.Background()))
+
+	require.Len(t, prompts, 2, "PLAN_READY rejected once")
+	assert.NotContains(t, prompts[0], "PLAN FORMAT ERRORS")
+	assert.Contains(t, prompts[1], "PLAN FORMAT ERRORS")
+	assert.Contains(t, prompts[1], planFile+":\n- plan has no \"### Task N: title\" sections\n")
+	assert.Contains(t, prompts[1], "- line 6: task header \"## Task 1 Add cache\" is not recognized")
+}
+
+func TestRunner_LintCreatedPlan(t *testing.T) {
+	plansDir := t.TempDir()
+	appCfg := testAppConfig(t)
+	appCfg.PlansDir = plansDir
+	log := newMockLogger("")
+	r := newRunner(Config{Mode: ModePlan, AppConfig: appCfg}, log, nil, nil)
+
+	start := time.Now().Add(-time.Second)
+	assert.Empty(t, r.lintCreatedPlan(start), "no plan created")
+
+	plan := "### Task 1: Add cache\n- [ ] add cache\n"
+	require.NoError(t, os.WriteFile(filepath.Join(plansDir, "plan.md"), []byte(plan), 0o600))
+	assert.Empty(t, r.lintCreatedPlan(start), "warnings only")
+	require.Len(t, log.PrintCalls(), 2)
+	assert.Equal(t, "plan lint: %s", log.PrintCalls()[0].Format)
+}
@@ -3,6 +3,7 @@ package processor
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -15,10 +16,60 @@ const agentExpansionTemplate = `Use the Task tool to launch a general-purpose ag
 
 Report findings only - no positive observations.`
 
+// parallelTaskTemplate is appended to the task prompt when a task runs in its own git worktree.
+const parallelTaskTemplate = `
+
+PARALLEL EXECUTION OVERRIDE:
+Other plan tasks are running concurrently in separate git worktrees. Ignore the instruction to pick the first
+incomplete task - work ONLY on "### Task %d: %s" in {{PLAN_FILE}}.
+Do not modify code or checkboxes that belong to other tasks. Mark only this task's checkboxes as [x] and commit.
+Do not output <<<RALPHEX:ALL_TASKS_DONE>>>, ralphex tracks completion of the whole plan.
+If you ask a QUESTION, this task is re-run with the answer once the user answered it.
+If the task is blocked, the TASK_BLOCKED signal applies to this task, the "task" field can be omitted.`
+
+// validationFailureTemplate is appended to the task prompt after validation commands failed.
+const validationFailureTemplate = `
+
+VALIDATION FAILED:
+ralphex ran the plan's validation commands after your last iteration and they failed. Fix the cause before
+working on anything else and before marking the task complete. Do not weaken, skip or delete checks to make them pass.
+
+%s`
+
+// planLintTemplate is appended to the plan creation prompt when the created plan failed the plan linter.
+const planLintTemplate = `
+
+PLAN FORMAT ERRORS:
+ralphex checked the plan file you created and found format errors that break plan execution. Fix them in the
+plan file (%s), then output <<<RALPHEX:PLAN_READY>>> again.
+
+%s`
+
+// planTemplateTemplate is injected into the plan creation prompt when the user selected a plan template.
+const planTemplateTemplate = `PLAN TEMPLATE:
+The user selected a plan template for this plan. Use its sections and tasks instead of the generic structure
+above. Fill in placeholders like {{PLAN_NAME}} and <...>, drop tasks that don't apply, add tasks the request
+needs, and keep the template's frontmatter and Manual Steps.
+
+%s`
+
+// baseRef returns the ref the reviewed changes are diffed against, resolved by the git repository:
+// the base branch or the default branch, as origin/<branch> if the branch exists only on origin.
+// without a git repository the base branch is used as is, "master" if not set.
+func (r *Runner) baseRef() string {
+	if r.git != nil {
+		return r.git.BaseRef(r.cfg.BaseRef)
+	}
+	if r.cfg.BaseRef == "" {
+		return "master"
+	}
+	return r.cfg.BaseRef
+}
+
 // getGoal returns the goal string based on whether a plan file is configured.
 func (r *Runner) getGoal() string {
 	if r.cfg.PlanFile == "" {
-		return "current branch vs master"
+		return "current branch vs " + r.baseRef()
 	}
 	return "implementation of plan at " + r.cfg.PlanFile
 }
@@ -31,6 +82,15 @@ func (r *Runner) getPlanFileRef() string {
 	return r.cfg.PlanFile
 }
 
+// planFormatHint describes the expected task header and checkbox format for prompts.
+func (r *Runner) planFormatHint() string {
+	g := r.planGrammar()
+	if g.IsDefault() {
+		return `task headers must be "### Task N: title", checkboxes "- [ ] item"`
+	}
+	return fmt.Sprintf("task headers must match the regular expression %s, checkboxes %s", g.TaskHeaderPattern(), g.CheckboxPattern())
+}
+
 // getProgressFileRef returns progress file reference or fallback text for prompts.
 func (r *Runner) getProgressFileRef() string {
 	if r.cfg.ProgressPath == "" {
@@ -42,6 +102,7 @@ func (r *Runner) getProgressFileRef() string {
 // expandAgentReferences replaces {{agent:name}} patterns with Task tool instructions.
 // returns prompt unchanged if AppConfig is nil or no agents are configured.
 // missing agents log a warning and leave the reference as-is for visibility.
+// with the agents setting, references to agents not listed are removed from the prompt.
 func (r *Runner) expandAgentReferences(prompt string) string {
 	if r.cfg.AppConfig == nil {
 		return prompt
@@ -50,6 +111,7 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 	if len(agents) == 0 {
 		return prompt
 	}
+	allowed := r.cfg.AppConfig.Agents
 
 	// build agent lookup map
 	agentMap := make(map[string]string, len(agents))
@@ -61,6 +123,11 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 		// extract name directly from match: {{agent:NAME}} -> NAME
 		name := match[8 : len(match)-2] // skip "{{agent:" and "}}"
 
+		if len(allowed) > 0 && !slices.Contains(allowed, name) {
+			r.log.Print("agent %q is not in the agents list, skipped", name)
+			return ""
+		}
+
 		agentPrompt, ok := agentMap[name]
 		if !ok {
 			r.log.Print("[WARN] agent %q not found, leaving reference unexpanded", name)
@@ -72,13 +139,16 @@ func (r *Runner) expandAgentReferences(prompt string) string {
 }
 
 // replacePromptVariables replaces template variables in custom prompts.
-// supported variables: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{agent:name}}
+// supported variables: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{BASE_REF}}, {{TASK_HEADER_PATTERN}},
+// {{CHECKBOX_PATTERN}}, {{agent:name}}
 // note: {{CODEX_OUTPUT}} is handled separately in buildCodexEvaluationPrompt
 func (r *Runner) replacePromptVariables(prompt string) string {
 	result := prompt
 	result = strings.ReplaceAll(result, "{{PLAN_FILE}}", r.getPlanFileRef())
 	result = strings.ReplaceAll(result, "{{PROGRESS_FILE}}", r.getProgressFileRef())
 	result = strings.ReplaceAll(result, "{{GOAL}}", r.getGoal())
+	result = strings.ReplaceAll(result, "{{BASE_REF}}", r.baseRef())
+	result = r.replacePlanFormat(result)
 
 	// expand agent references
 	result = r.expandAgentReferences(result)
@@ -93,6 +163,19 @@ func (r *Runner) buildTaskPrompt() string {
 	return r.replacePromptVariables(r.cfg.AppConfig.TaskPrompt)
 }
 
+// buildParallelTaskPrompt creates the prompt for executing the given task inside a git worktree.
+// planFile is the plan path inside the worktree, so claude edits the worktree copy of the plan.
+func (r *Runner) buildParallelTaskPrompt(task planTask, planFile string) string {
+	prompt := r.cfg.AppConfig.TaskPrompt + fmt.Sprintf(parallelTaskTemplate, task.Number, task.Title)
+	prompt = strings.ReplaceAll(prompt, "{{PLAN_FILE}}", planFile)
+	return r.replacePromptVariables(prompt)
+}
+
+// buildValidationFailurePrompt appends the validation failure report to a task prompt.
+func (r *Runner) buildValidationFailurePrompt(prompt, report string) string {
+	return prompt + fmt.Sprintf(validationFailureTemplate, report)
+}
+
 // buildFirstReviewPrompt creates the prompt for first review pass - address all findings.
 // uses the loaded prompt template (user-provided or embedded default).
 // agent references ({{agent:name}}) are expanded via replacePromptVariables.
@@ -117,10 +200,31 @@ func (r *Runner) buildCodexEvaluationPrompt(codexOutput string) string {
 
 // buildPlanPrompt creates the prompt for interactive plan creation.
 // uses the make_plan prompt loaded from config (either user-provided or embedded default).
-// replaces {{PLAN_DESCRIPTION}} and {{PROGRESS_FILE}} variables.
+// replaces {{PLAN_DESCRIPTION}}, {{PLAN_TEMPLATE}}, {{PROGRESS_FILE}} and plan format variables.
+// a template is appended to a custom prompt without the {{PLAN_TEMPLATE}} variable.
 func (r *Runner) buildPlanPrompt() string {
 	prompt := r.cfg.AppConfig.MakePlanPrompt
 	prompt = strings.ReplaceAll(prompt, "{{PLAN_DESCRIPTION}}", r.cfg.PlanDescription)
+	tmpl := ""
+	if r.cfg.PlanTemplate != "" {
+		tmpl = fmt.Sprintf(planTemplateTemplate, strings.TrimSpace(r.cfg.PlanTemplate))
+	}
+	switch {
+	case strings.Contains(prompt, "{{PLAN_TEMPLATE}}"):
+		if tmpl == "" {
+			prompt = strings.ReplaceAll(prompt, "{{PLAN_TEMPLATE}}\n\n", "") // drop the empty paragraph
+		}
+		prompt = strings.ReplaceAll(prompt, "{{PLAN_TEMPLATE}}", tmpl)
+	case tmpl != "":
+		prompt = strings.TrimRight(prompt, "\n") + "\n\n" + tmpl
+	}
 	prompt = strings.ReplaceAll(prompt, "{{PROGRESS_FILE}}", r.getProgressFileRef())
-	return prompt
+	return r.replacePlanFormat(prompt)
+}
+
+// replacePlanFormat replaces {{TASK_HEADER_PATTERN}} and {{CHECKBOX_PATTERN}} with patterns of the plan grammar.
+func (r *Runner) replacePlanFormat(prompt string) string {
+	g := r.planGrammar()
+	prompt = strings.ReplaceAll(prompt, "{{TASK_HEADER_PATTERN}}", g.TaskHeaderPattern())
+	return strings.ReplaceAll(prompt, "{{CHECKBOX_PATTERN}}", g.CheckboxPattern())
 }
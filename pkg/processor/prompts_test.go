@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"cmp"
 	"strings"
 	"testing"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 func TestRunner_buildTaskPrompt(t *testing.T) {
@@ -171,6 +173,51 @@ func TestRunner_replacePromptVariables_NoGoal(t *testing.T) {
 	assert.Equal(t, "Goal: current branch vs master", result)
 }
 
+func TestRunner_replacePromptVariables_BaseRef(t *testing.T) {
+	r := &Runner{cfg: Config{BaseRef: "develop"}}
+	result := r.replacePromptVariables("Goal: {{GOAL}}, run git diff {{BASE_REF}}...HEAD")
+	assert.Equal(t, "Goal: current branch vs develop, run git diff develop...HEAD", result)
+
+	r = &Runner{cfg: Config{}}
+	assert.Equal(t, "master", r.replacePromptVariables("{{BASE_REF}}"), "master if not set without git repository")
+
+	// git repository resolves the default branch and the remote ref of a branch existing only on origin
+	gitRepo := &remoteGit{}
+	r = &Runner{cfg: Config{}, git: gitRepo}
+	assert.Equal(t, "origin/trunk", r.replacePromptVariables("{{BASE_REF}}"))
+	r = &Runner{cfg: Config{BaseRef: "develop"}, git: gitRepo}
+	assert.Equal(t, "origin/develop", r.replacePromptVariables("{{BASE_REF}}"))
+}
+
+// remoteGit resolves base refs of a clone with all branches existing only on origin, "trunk" is the default branch.
+type remoteGit struct{ GitRepo }
+
+func (g *remoteGit) BaseRef(branch string) string {
+	return "origin/" + cmp.Or(branch, "trunk")
+}
+
+func TestRunner_replacePromptVariables_PlanFormat(t *testing.T) {
+	r := &Runner{cfg: Config{}}
+	assert.Equal(t, plan.DefaultTaskHeaderPattern+" "+plan.DefaultCheckboxPattern,
+		r.replacePromptVariables("{{TASK_HEADER_PATTERN}} {{CHECKBOX_PATTERN}}"), "default grammar without app config")
+	assert.Equal(t, `task headers must be "### Task N: title", checkboxes "- [ ] item"`, r.planFormatHint())
+
+	appCfg := testAppConfig(t)
+	grammar, err := plan.NewGrammar(`^## Step (?P<num>\d+): (?P<title>.*)$`, "")
+	require.NoError(t, err)
+	appCfg.PlanGrammar = grammar
+	appCfg.MakePlanPrompt = "create plan for {{PLAN_DESCRIPTION}}, headers {{TASK_HEADER_PATTERN}}"
+	r = &Runner{cfg: Config{AppConfig: appCfg, PlanDescription: "caching"}, log: newMockLogger("")}
+	assert.Equal(t, `^## Step (?P<num>\d+): (?P<title>.*)$`, r.replacePromptVariables("{{TASK_HEADER_PATTERN}}"))
+	assert.Equal(t, "create plan for caching, headers ^## Step (?P<num>\\d+): (?P<title>.*)$", r.buildPlanPrompt())
+	assert.Equal(t, "task headers must match the regular expression ^## Step (?P<num>\\d+): (?P<title>.*)$, checkboxes "+
+		plan.DefaultCheckboxPattern, r.planFormatHint())
+
+	prompt := r.buildTaskPrompt()
+	assert.Contains(t, prompt, "by the regular expression ^## Step (?P<num>\\d+): (?P<title>.*)$")
+	assert.NotContains(t, prompt, "{{CHECKBOX_PATTERN}}")
+}
+
 func TestRunner_getPlanFileRef(t *testing.T) {
 	t.Run("with plan file", func(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md"}}
@@ -269,6 +316,28 @@ func TestRunner_expandAgentReferences_MissingAgent(t *testing.T) {
 	assert.Contains(t, calls[0].Format, "not found")
 }
 
+func TestRunner_expandAgentReferences_AgentsList(t *testing.T) {
+	appCfg := &config.Config{
+		CustomAgents: []config.CustomAgent{
+			{Name: "agent-a", Prompt: "first agent prompt"},
+			{Name: "agent-b", Prompt: "second agent prompt"},
+		},
+		Agents: []string{"agent-b"},
+	}
+	log := newMockLogger("")
+	r := &Runner{cfg: Config{AppConfig: appCfg}, log: log}
+
+	result := r.expandAgentReferences("Run {{agent:agent-a}} then {{agent:agent-b}}.")
+	assert.NotContains(t, result, "first agent prompt")
+	assert.NotContains(t, result, "{{agent:agent-a}}")
+	assert.Contains(t, result, "second agent prompt")
+	assert.True(t, strings.HasPrefix(result, "Run  then Use the Task tool"), "agent not in the list removed")
+
+	calls := log.PrintCalls()
+	require.Len(t, calls, 1)
+	assert.Contains(t, calls[0].Format, "not in the agents list")
+}
+
 func TestRunner_expandAgentReferences_NilAppConfig(t *testing.T) {
 	r := &Runner{cfg: Config{AppConfig: nil}}
 	prompt := "Run {{agent:test}} now."
@@ -466,4 +535,41 @@ func TestRunner_buildPlanPrompt(t *testing.T) {
 
 		assert.Equal(t, "Create plan for: custom feature\nLog: custom-progress.txt", prompt)
 	})
+
+	t.Run("plan template", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		r := &Runner{cfg: Config{
+			PlanDescription: "add orders endpoint",
+			PlanTemplate:    "# Plan: {{PLAN_NAME}}\n\n### Task 1: Add handler\n- [ ] handler\n",
+			AppConfig:       appCfg,
+		}, log: newMockLogger("")}
+
+		prompt := r.buildPlanPrompt()
+
+		assert.Contains(t, prompt, "PLAN TEMPLATE:")
+		assert.Contains(t, prompt, "# Plan: {{PLAN_NAME}}\n\n### Task 1: Add handler\n- [ ] handler\n\nralphex recognizes")
+		assert.NotContains(t, prompt, "{{PLAN_TEMPLATE}}")
+	})
+
+	t.Run("no plan template", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		r := &Runner{cfg: Config{PlanDescription: "add feature", AppConfig: appCfg}, log: newMockLogger("")}
+
+		prompt := r.buildPlanPrompt()
+
+		assert.NotContains(t, prompt, "PLAN TEMPLATE:")
+		assert.NotContains(t, prompt, "{{PLAN_TEMPLATE}}")
+		assert.Contains(t, prompt, "---\n\nralphex recognizes", "no empty paragraph")
+	})
+
+	t.Run("plan template appended to custom prompt", func(t *testing.T) {
+		appCfg := &config.Config{MakePlanPrompt: "Create plan for: {{PLAN_DESCRIPTION}}\n"}
+		r := &Runner{cfg: Config{PlanDescription: "cli", PlanTemplate: "### Task 1: Add command\n",
+			AppConfig: appCfg}, log: newMockLogger("")}
+
+		prompt := r.buildPlanPrompt()
+
+		assert.True(t, strings.HasPrefix(prompt, "Create plan for: cli\n\nPLAN TEMPLATE:\n"))
+		assert.True(t, strings.HasSuffix(prompt, "\n\n### Task 1: Add command"))
+	})
 }
@@ -0,0 +1,123 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// notesTemplate is appended to task and review prompts once questions were answered.
+const notesTemplate = `
+
+ANSWERS TO EARLIER QUESTIONS:
+The user answered these questions asked earlier in this run. Follow the answers,
+do not ask the same questions again.
+
+%s`
+
+// NotesPath returns the notes file path for the given progress file path, questions asked during
+// task and review phases and their answers are kept there.
+// e.g. "progress-feature.txt" -> "progress-feature.notes.md".
+func NotesPath(progressPath string) string {
+	if progressPath == "" {
+		return ""
+	}
+	return strings.TrimSuffix(progressPath, filepath.Ext(progressPath)) + ".notes.md"
+}
+
+// handleQuestion checks the output for a QUESTION signal and collects the answer.
+// the answer is picked by the input collector, or is the first option if auto_answer_questions
+// is set or no collector is configured. the question and answer are logged and appended to the
+// notes file, so prompts of next iterations include them. returns true if a question was answered,
+// the caller re-runs the iteration then. malformed question signals are logged and ignored.
+// questions of parallel tasks are asked one at a time.
+func (r *Runner) handleQuestion(ctx context.Context, iteration int, output string) (bool, error) {
+	question, err := ParseQuestionPayload(output)
+	if err != nil {
+		if !errors.Is(err, ErrNoQuestionSignal) {
+			r.log.Print("warning: %v", err)
+		}
+		return false, nil
+	}
+
+	r.notesMu.Lock()
+	defer r.notesMu.Unlock()
+	r.log.LogQuestion(question.Question, question.Options)
+	var answer string
+	switch {
+	case r.inputCollector == nil || (r.cfg.AppConfig != nil && r.cfg.AppConfig.AutoAnswer):
+		answer = question.Options[0]
+		r.log.Print("auto-answering with the first option")
+	default:
+		answer, err = r.inputCollector.AskQuestion(ctx, question.Question, question.Options)
+		if err != nil {
+			return false, fmt.Errorf("collect answer: %w", err)
+		}
+	}
+	r.log.LogAnswer(answer)
+
+	if noteErr := r.appendNote(iteration, question.Question, answer); noteErr != nil {
+		r.log.Print("warning: %v", noteErr)
+	}
+	return true, nil
+}
+
+// appendNote adds a question and its answer to the notes of the run and appends them to the notes file.
+// notes are used by prompts of the run even if the notes file is disabled.
+func (r *Runner) appendNote(iteration int, question, answer string) error {
+	note := fmt.Sprintf("## %s, iteration %d\n\nQuestion: %s\nAnswer: %s\n", r.currentPhase(), iteration, question, answer)
+	r.notes = append(r.notes, note)
+	if r.cfg.NotesPath == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(r.cfg.NotesPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open notes file: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s\n", note); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write notes file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close notes file: %w", err)
+	}
+	return nil
+}
+
+// initNotes prepares the notes file of the run. a resumed run continues with the answers
+// saved by the interrupted run, a new run removes the notes left by a previous run.
+func (r *Runner) initNotes() {
+	if r.cfg.NotesPath == "" {
+		return
+	}
+	if !r.cfg.Resume {
+		if err := os.Remove(r.cfg.NotesPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			r.log.Print("warning: failed to remove notes file: %v", err)
+		}
+		return
+	}
+	data, err := os.ReadFile(r.cfg.NotesPath) //nolint:gosec // path derived from progress filename
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			r.log.Print("warning: read notes file: %v", err)
+		}
+		return
+	}
+	if notes := strings.TrimSpace(string(data)); notes != "" {
+		r.notes = []string{notes + "\n"}
+	}
+}
+
+// withNotes appends answered questions to the prompt.
+func (r *Runner) withNotes(prompt string) string {
+	r.notesMu.Lock()
+	defer r.notesMu.Unlock()
+	if len(r.notes) == 0 {
+		return prompt
+	}
+	return prompt + fmt.Sprintf(notesTemplate, strings.TrimSpace(strings.Join(r.notes, "\n")))
+}
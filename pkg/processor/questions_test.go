@@ -0,0 +1,163 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// funcCollector is an InputCollector calling a function.
+type funcCollector func(ctx context.Context, question string, options []string) (string, error)
+
+func (f funcCollector) AskQuestion(ctx context.Context, question string, options []string) (string, error) {
+	return f(ctx, question, options)
+}
+
+const questionOutput = "need a decision\n<<<RALPHEX:QUESTION>>>\n" +
+	`{"question": "Which cache?", "options": ["Redis", "In-memory"]}` + "\n<<<RALPHEX:END>>>"
+
+func TestNotesPath(t *testing.T) {
+	assert.Equal(t, "progress-feature.notes.md", NotesPath("progress-feature.txt"))
+	assert.Equal(t, "/tmp/x/progress.notes.md", NotesPath("/tmp/x/progress.txt"))
+	assert.Empty(t, NotesPath(""))
+}
+
+func TestRunner_RunTaskPhase_Question(t *testing.T) {
+	dir := t.TempDir()
+	planFile := filepath.Join(dir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: cache\n- [x] add cache\n"), 0o600))
+	notesPath := filepath.Join(dir, "progress.notes.md")
+
+	var prompts []string
+	task := funcExecutor(func(_ context.Context, prompt string) executor.Result {
+		prompts = append(prompts, prompt)
+		if len(prompts) == 1 {
+			return executor.Result{Output: questionOutput}
+		}
+		return executor.Result{Output: "done", Signal: SignalCompleted}
+	})
+
+	t.Run("answer collected and task re-run with it", func(t *testing.T) {
+		prompts = nil
+		var asked []string
+		cfg := Config{Mode: ModeFull, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1, NotesPath: notesPath,
+			AppConfig: testAppConfig(t)}
+		r := newRunner(cfg, newMockLogger(""), task, task)
+		r.SetInputCollector(funcCollector(func(_ context.Context, question string, options []string) (string, error) {
+			asked = append(asked, question)
+			assert.Equal(t, []string{"Redis", "In-memory"}, options)
+			return "In-memory", nil
+		}))
+		require.NoError(t, r.runTaskPhase(context.Background(), 1))
+
+		assert.Equal(t, []string{"Which cache?"}, asked)
+		require.Len(t, prompts, 2)
+		assert.NotContains(t, prompts[0], "ANSWERS TO EARLIER QUESTIONS")
+		assert.Contains(t, prompts[1], "ANSWERS TO EARLIER QUESTIONS")
+		assert.Contains(t, prompts[1], "Question: Which cache?\nAnswer: In-memory")
+
+		notes, err := os.ReadFile(notesPath) //nolint:gosec // test file in temp dir
+		require.NoError(t, err)
+		assert.Equal(t, "## tasks, iteration 1\n\nQuestion: Which cache?\nAnswer: In-memory\n\n", string(notes))
+	})
+
+	t.Run("auto answer picks the first option", func(t *testing.T) {
+		prompts = nil
+		appCfg := testAppConfig(t)
+		appCfg.AutoAnswer = true
+		cfg := Config{Mode: ModeFull, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1, AppConfig: appCfg}
+		r := newRunner(cfg, newMockLogger(""), task, task)
+		r.SetInputCollector(funcCollector(func(context.Context, string, []string) (string, error) {
+			t.Fatal("collector must not be called")
+			return "", nil
+		}))
+		require.NoError(t, r.runTaskPhase(context.Background(), 1))
+		require.Len(t, prompts, 2)
+		assert.Contains(t, prompts[1], "Answer: Redis")
+	})
+
+	t.Run("collector error stops the task phase", func(t *testing.T) {
+		prompts = nil
+		cfg := Config{Mode: ModeFull, PlanFile: planFile, MaxIterations: 5, IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+		r := newRunner(cfg, newMockLogger(""), task, task)
+		r.SetInputCollector(funcCollector(func(context.Context, string, []string) (string, error) {
+			return "", errors.New("stdin closed")
+		}))
+		err := r.runTaskPhase(context.Background(), 1)
+		require.EqualError(t, err, "collect answer: stdin closed")
+	})
+}
+
+func TestRunner_HandleQuestion(t *testing.T) {
+	cfg := Config{Mode: ModeReview, AppConfig: testAppConfig(t)}
+
+	t.Run("no question", func(t *testing.T) {
+		r := newRunner(cfg, newMockLogger(""), nil, nil)
+		asked, err := r.handleQuestion(context.Background(), 1, "fixed everything")
+		require.NoError(t, err)
+		assert.False(t, asked)
+	})
+
+	t.Run("malformed question is ignored", func(t *testing.T) {
+		log := newMockLogger("")
+		r := newRunner(cfg, log, nil, nil)
+		asked, err := r.handleQuestion(context.Background(), 1, `<<<RALPHEX:QUESTION>>>{"question": "x?"}<<<RALPHEX:END>>>`)
+		require.NoError(t, err)
+		assert.False(t, asked)
+		require.Len(t, log.PrintCalls(), 1)
+		assert.Equal(t, "warning: %v", log.PrintCalls()[0].Format)
+	})
+
+	t.Run("without collector the first option is used", func(t *testing.T) {
+		r := newRunner(cfg, newMockLogger(""), nil, nil)
+		asked, err := r.handleQuestion(context.Background(), 2, questionOutput)
+		require.NoError(t, err)
+		assert.True(t, asked)
+		assert.Equal(t, []string{"## review_first, iteration 2\n\nQuestion: Which cache?\nAnswer: Redis\n"}, r.notes)
+	})
+}
+
+func TestRunner_RunClaudeReviewLoop_Question(t *testing.T) {
+	var calls atomic.Int32
+	var lastPrompt string
+	review := funcExecutor(func(_ context.Context, prompt string) executor.Result {
+		lastPrompt = prompt
+		if calls.Add(1) == 1 {
+			return executor.Result{Output: questionOutput}
+		}
+		return executor.Result{Output: "clean", Signal: SignalReviewDone}
+	})
+
+	cfg := Config{Mode: ModeReview, MaxIterations: 50, IterationDelayMs: 1, AppConfig: testAppConfig(t)}
+	r := newRunner(cfg, newMockLogger(""), review, review)
+	r.SetInputCollector(funcCollector(func(context.Context, string, []string) (string, error) { return "In-memory", nil }))
+	require.NoError(t, r.runClaudeReviewLoop(context.Background(), 1))
+
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Contains(t, lastPrompt, "Answer: In-memory")
+}
+
+func TestRunner_InitNotes(t *testing.T) {
+	notesPath := filepath.Join(t.TempDir(), "progress.notes.md")
+	saved := "## tasks, iteration 1\n\nQuestion: Which cache?\nAnswer: Redis\n\n"
+
+	cfg := Config{Mode: ModeFull, NotesPath: notesPath, Resume: true, AppConfig: testAppConfig(t)}
+	require.NoError(t, os.WriteFile(notesPath, []byte(saved), 0o600))
+	r := newRunner(cfg, newMockLogger(""), nil, nil)
+	r.initNotes()
+	assert.Contains(t, r.withNotes("prompt"), "Answer: Redis", "resumed run keeps answers")
+
+	cfg.Resume = false
+	r = newRunner(cfg, newMockLogger(""), nil, nil)
+	r.initNotes()
+	assert.NoFileExists(t, notesPath, "new run removes stale notes")
+	assert.Equal(t, "prompt", r.withNotes("prompt"))
+}
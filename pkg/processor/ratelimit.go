@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+const (
+	// DefaultRateLimitBackoff is the first wait after a rate limit without a reported reset time.
+	DefaultRateLimitBackoff = time.Minute
+	// maxRateLimitBackoff caps the exponential backoff between rate limited attempts.
+	maxRateLimitBackoff = 30 * time.Minute
+	// maxRateLimitWaits limits consecutive rate limited attempts of a single call.
+	maxRateLimitWaits = 10
+	// rateLimitCountdownInterval is how often the remaining wait time is reported.
+	rateLimitCountdownInterval = time.Minute
+)
+
+// rateLimitExecutor waits out usage limits and API overloads reported by the executor and runs it again.
+// waits don't count as iterations, the wait time is the reported reset time or exponential backoff.
+type rateLimitExecutor struct {
+	inner Executor
+	r     *Runner
+}
+
+// Run executes the wrapped executor, waiting and retrying while it is rate limited.
+func (e *rateLimitExecutor) Run(ctx context.Context, prompt string) executor.Result {
+	for attempt := 0; ; attempt++ {
+		result := e.inner.Run(ctx, prompt)
+		var rl *executor.RateLimitError
+		if !errors.As(result.Error, &rl) || ctx.Err() != nil {
+			return result
+		}
+		if attempt >= maxRateLimitWaits {
+			result.Error = fmt.Errorf("still rate limited after %d waits: %w", attempt, result.Error)
+			return result
+		}
+		if err := e.wait(ctx, rl, attempt); err != nil {
+			result.Error = fmt.Errorf("rate limit wait interrupted: %w", err)
+			return result
+		}
+	}
+}
+
+// wait sleeps until the limit reset or for the backoff of the given attempt, reporting the countdown.
+func (e *rateLimitExecutor) wait(ctx context.Context, rl *executor.RateLimitError, attempt int) error {
+	wait := time.Until(rl.ResetAt)
+	if rl.ResetAt.IsZero() || wait <= 0 {
+		wait = e.backoff(attempt)
+	}
+	until := time.Now().Add(wait)
+	e.r.log.Print("rate limited (%s), waiting %s until %s...", rl.Message, wait.Round(time.Second), until.Format(time.TimeOnly))
+
+	for {
+		remaining := time.Until(until)
+		if remaining <= 0 {
+			e.r.log.Print("rate limit wait is over, resuming")
+			return nil
+		}
+		tick := min(remaining, rateLimitCountdownInterval)
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(tick):
+		}
+		if remaining = time.Until(until); remaining > 0 {
+			e.r.log.Print("rate limited, resuming in %s...", remaining.Round(time.Second))
+		}
+	}
+}
+
+// backoff returns the exponential backoff for the given attempt, capped at maxRateLimitBackoff.
+func (e *rateLimitExecutor) backoff(attempt int) time.Duration {
+	d := e.r.rateLimitBackoff
+	for range attempt {
+		if d >= maxRateLimitBackoff {
+			break
+		}
+		d *= 2
+	}
+	return min(d, maxRateLimitBackoff)
+}
@@ -0,0 +1,95 @@
+package processor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+func TestRunner_RateLimit(t *testing.T) {
+	pipeline, err := processor.ParsePipeline("tasks")
+	require.NoError(t, err)
+
+	newPlan := func(t *testing.T, content string) string {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte(content), 0o600))
+		return planFile
+	}
+	// printed collects formatted Print calls of the logger
+	printed := func(calls []struct {
+		Format string
+		Args   []any
+	}) string {
+		var lines []string
+		for _, c := range calls {
+			lines = append(lines, fmt.Sprintf(c.Format, c.Args...))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	t.Run("waits until reset without counting an iteration", func(t *testing.T) {
+		limited := executor.Result{Output: "Claude AI usage limit reached",
+			Error: &executor.RateLimitError{Message: "Claude AI usage limit reached", ResetAt: time.Now().Add(200 * time.Millisecond)}}
+		claude := newMockExecutor([]executor.Result{limited, {Output: "done", Signal: processor.SignalCompleted}})
+		log := newMockLogger("progress.txt")
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: newPlan(t, "# Plan\n- [x] done"), MaxIterations: 1,
+			IterationDelayMs: 1, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+
+		start := time.Now()
+		require.NoError(t, r.Run(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+		assert.Len(t, claude.RunCalls(), 2)
+		assert.Len(t, log.PrintSectionCalls(), 1, "wait stays in the same iteration")
+		out := printed(log.PrintCalls())
+		assert.Contains(t, out, "rate limited (Claude AI usage limit reached), waiting")
+		assert.Contains(t, out, "rate limit wait is over, resuming")
+	})
+
+	t.Run("exponential backoff without reset time", func(t *testing.T) {
+		overloaded := executor.Result{Error: &executor.RateLimitError{Message: "overloaded_error"}}
+		claude := newMockExecutor([]executor.Result{overloaded, overloaded, {Output: "done", Signal: processor.SignalCompleted}})
+		log := newMockLogger("progress.txt")
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: newPlan(t, "# Plan\n- [x] done"), MaxIterations: 1,
+			IterationDelayMs: 1, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		r.TestSetRateLimitBackoff(20 * time.Millisecond)
+
+		start := time.Now()
+		require.NoError(t, r.Run(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond, "waits 20ms then 40ms")
+		assert.Len(t, claude.RunCalls(), 3)
+		out := printed(log.PrintCalls())
+		assert.Contains(t, out, "waiting 0s until")
+	})
+
+	t.Run("canceled context stops waiting", func(t *testing.T) {
+		limited := executor.Result{Error: &executor.RateLimitError{Message: "usage limit reached", ResetAt: time.Now().Add(time.Hour)}}
+		claude := newMockExecutor([]executor.Result{limited})
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: newPlan(t, "# Plan\n- [ ] todo"), MaxIterations: 10,
+			IterationDelayMs: 1, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := r.Run(ctx)
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Len(t, claude.RunCalls(), 1)
+	})
+}
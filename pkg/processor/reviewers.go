@@ -0,0 +1,181 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// externalReviewer is an executor of the external review loop. several reviewers form a review panel.
+type externalReviewer struct {
+	name string
+	exec Executor
+}
+
+// reviewerOutput is the output of an external reviewer in a review iteration.
+type reviewerOutput struct {
+	name   string
+	output string
+}
+
+// mergedFinding is a finding reported by one or more reviewers.
+type mergedFinding struct {
+	text      string
+	reviewers []string
+}
+
+var (
+	// findingStartPattern matches the start of a list item, each item is a separate finding.
+	findingStartPattern = regexp.MustCompile(`^\s{0,3}(?:[-*•]|\d+[.)])\s+`)
+
+	// findingLocationPattern matches file:line references used to recognize the same finding from different reviewers.
+	findingLocationPattern = regexp.MustCompile(`[\w./-]+\.\w+:\d+`)
+
+	// noIssuesPattern matches a reviewer's report of no issues.
+	noIssuesPattern = regexp.MustCompile(`(?i)^no (actionable )?issues( were)? found\.?$`)
+)
+
+// runExternalReview runs the external reviewers on the prompt and returns their findings.
+// a single reviewer streams its output and returns it as-is. reviewers of a panel run in parallel,
+// their outputs are printed per reviewer once all finished and merged into one deduplicated list
+// with attribution. a failed reviewer of a panel is logged and skipped, the review fails only if
+// every reviewer failed. an empty result means no reviewer produced output.
+func (r *Runner) runExternalReview(ctx context.Context, prompt string) (string, error) {
+	if len(r.external) == 1 {
+		result := r.external[0].exec.Run(ctx, prompt)
+		if result.Error != nil {
+			return "", fmt.Errorf("codex execution: %w", result.Error)
+		}
+		return result.Output, nil
+	}
+
+	results := make([]executor.Result, len(r.external))
+	var wg sync.WaitGroup
+	for i, rv := range r.external {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = rv.exec.Run(ctx, prompt)
+		}()
+	}
+	wg.Wait()
+
+	outputs := make([]reviewerOutput, 0, len(r.external))
+	var errs []error
+	for i, rv := range r.external {
+		r.log.PrintSection(NewGenericSection("external review: " + rv.name))
+		if err := results[i].Error; err != nil {
+			r.log.Print("%s failed, its review is skipped: %v", rv.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", rv.name, err))
+			continue
+		}
+		output := strings.TrimSpace(results[i].Output)
+		if output == "" {
+			r.log.Print("%s returned no output", rv.name)
+			continue
+		}
+		for line := range strings.SplitSeq(output, "\n") {
+			r.log.PrintAligned(line)
+		}
+		outputs = append(outputs, reviewerOutput{name: rv.name, output: output})
+	}
+	if len(errs) == len(r.external) || (len(errs) > 0 && ctx.Err() != nil) {
+		return "", fmt.Errorf("codex execution: %w", errors.Join(errs...))
+	}
+	if len(outputs) == 0 {
+		return "", nil
+	}
+	return mergeFindings(outputs), nil
+}
+
+// mergeFindings combines outputs of a review panel into one list of findings attributed to reviewers.
+// findings with the same file:line references, or the same text if they have none, are listed once.
+// reviewers without findings are listed at the end, if no reviewer has findings the result is "NO ISSUES FOUND".
+func mergeFindings(outputs []reviewerOutput) string {
+	var findings []*mergedFinding
+	byKey := make(map[string]*mergedFinding)
+	var clean []string // reviewers without findings
+	for _, out := range outputs {
+		items := splitFindings(out.output)
+		if len(items) == 0 {
+			clean = append(clean, out.name)
+			continue
+		}
+		for _, item := range items {
+			key := findingKey(item)
+			if f, ok := byKey[key]; ok {
+				if !slices.Contains(f.reviewers, out.name) {
+					f.reviewers = append(f.reviewers, out.name)
+				}
+				continue
+			}
+			f := &mergedFinding{text: item, reviewers: []string{out.name}}
+			byKey[key] = f
+			findings = append(findings, f)
+		}
+	}
+
+	if len(findings) == 0 {
+		return fmt.Sprintf("NO ISSUES FOUND (%s)", strings.Join(clean, ", "))
+	}
+	var sb strings.Builder
+	for _, f := range findings {
+		text := strings.ReplaceAll(f.text, "\n", "\n  ")
+		fmt.Fprintf(&sb, "- [%s] %s\n", strings.Join(f.reviewers, ", "), text)
+	}
+	if len(clean) > 0 {
+		fmt.Fprintf(&sb, "\nno issues found by: %s\n", strings.Join(clean, ", "))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// splitFindings splits reviewer output into findings, one per list item or per paragraph with a file:line
+// reference. other paragraphs, like intros, headings and summaries, are not findings and are dropped.
+func splitFindings(output string) []string {
+	var res []string
+	var cur []string
+	item := false // current text is a list item
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(cur, "\n"))
+		isItem := item
+		cur, item = nil, false
+		if text == "" || noIssuesPattern.MatchString(text) {
+			return
+		}
+		if !isItem && !findingLocationPattern.MatchString(text) {
+			return
+		}
+		res = append(res, text)
+	}
+
+	for line := range strings.SplitSeq(output, "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case findingStartPattern.MatchString(line):
+			flush()
+			cur, item = append(cur, findingStartPattern.ReplaceAllString(line, "")), true
+		default:
+			cur = append(cur, strings.TrimSpace(line))
+		}
+	}
+	flush()
+	return res
+}
+
+// findingKey returns the deduplication key of a finding, its sorted file:line references
+// or normalized text if it has none.
+func findingKey(text string) string {
+	locations := findingLocationPattern.FindAllString(text, -1)
+	if len(locations) > 0 {
+		slices.Sort(locations)
+		return strings.Join(slices.Compact(locations), " ")
+	}
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
@@ -0,0 +1,141 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// funcExecutor is an Executor calling a function.
+type funcExecutor func(ctx context.Context, prompt string) executor.Result
+
+func (f funcExecutor) Run(ctx context.Context, prompt string) executor.Result { return f(ctx, prompt) }
+
+// outputs returns an executor returning the outputs one by one, the last one is repeated.
+func outputs(calls *atomic.Int32, outs ...string) funcExecutor {
+	return func(context.Context, string) executor.Result {
+		idx := int(calls.Add(1)) - 1
+		return executor.Result{Output: outs[min(idx, len(outs)-1)]}
+	}
+}
+
+func TestRunner_RunCodexLoop_Panel(t *testing.T) {
+	var codexCalls, geminiCalls, claudeCalls atomic.Int32
+	var evalPrompts []string
+	codex := outputs(&codexCalls, "- main.go:10 missing error check\n- util.go:5 unused variable", "NO ISSUES FOUND")
+	gemini := outputs(&geminiCalls, "1. main.go:10 error from Close is ignored", "No issues found.")
+	claude := funcExecutor(func(_ context.Context, prompt string) executor.Result {
+		claudeCalls.Add(1)
+		evalPrompts = append(evalPrompts, prompt)
+		if len(evalPrompts) == 1 {
+			return executor.Result{Output: "fixed main.go"}
+		}
+		return executor.Result{Output: "done", Signal: SignalCodexDone}
+	})
+
+	cfg := Config{Mode: ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true, AppConfig: testAppConfig(t)}
+	r := newRunner(cfg, newMockLogger(""), claude, claude,
+		externalReviewer{name: "codex", exec: codex}, externalReviewer{name: "gemini", exec: gemini})
+	require.NoError(t, r.runCodexLoop(context.Background(), 1, ""))
+
+	assert.Equal(t, int32(2), codexCalls.Load())
+	assert.Equal(t, int32(2), geminiCalls.Load())
+	require.Len(t, evalPrompts, 2)
+	assert.Contains(t, evalPrompts[0], "- [codex, gemini] main.go:10 missing error check\n- [codex] util.go:5 unused variable")
+	assert.Contains(t, evalPrompts[1], "NO ISSUES FOUND (codex, gemini)", "loop ends when all reviewers report no issues")
+}
+
+func TestRunner_RunExternalReview(t *testing.T) {
+	cfg := Config{Mode: ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}
+
+	t.Run("single reviewer output is kept as-is", func(t *testing.T) {
+		var calls atomic.Int32
+		r := newRunner(cfg, newMockLogger(""), nil, nil, externalReviewer{name: "codex", exec: outputs(&calls, "- a.go:1 bug")})
+		out, err := r.runExternalReview(context.Background(), "review")
+		require.NoError(t, err)
+		assert.Equal(t, "- a.go:1 bug", out)
+	})
+
+	t.Run("panel without output", func(t *testing.T) {
+		var calls atomic.Int32
+		r := newRunner(cfg, newMockLogger(""), nil, nil,
+			externalReviewer{name: "codex", exec: outputs(&calls, "")}, externalReviewer{name: "gemini", exec: outputs(&calls, "\n")})
+		out, err := r.runExternalReview(context.Background(), "review")
+		require.NoError(t, err)
+		assert.Empty(t, out)
+	})
+
+	t.Run("panel reviewer error", func(t *testing.T) {
+		var calls atomic.Int32
+		failing := funcExecutor(func(context.Context, string) executor.Result {
+			return executor.Result{Error: errors.New("boom")}
+		})
+		log := newMockLogger("")
+		r := newRunner(cfg, log, nil, nil, externalReviewer{name: "codex", exec: outputs(&calls, "- a.go:1 bug")},
+			externalReviewer{name: "gemini", exec: failing}, externalReviewer{name: "local", exec: outputs(&calls, "- b.go:2 leak")})
+		out, err := r.runExternalReview(context.Background(), "review")
+		require.NoError(t, err, "other reviewers' results are kept")
+		assert.Equal(t, "- [codex] a.go:1 bug\n- [local] b.go:2 leak", out)
+
+		var logged []string
+		for _, c := range log.PrintCalls() {
+			logged = append(logged, fmt.Sprintf(c.Format, c.Args...))
+		}
+		assert.Contains(t, logged, "gemini failed, its review is skipped: boom")
+	})
+
+	t.Run("all panel reviewers fail", func(t *testing.T) {
+		failing := funcExecutor(func(context.Context, string) executor.Result {
+			return executor.Result{Error: errors.New("boom")}
+		})
+		r := newRunner(cfg, newMockLogger(""), nil, nil,
+			externalReviewer{name: "codex", exec: failing}, externalReviewer{name: "gemini", exec: failing})
+		_, err := r.runExternalReview(context.Background(), "review")
+		require.EqualError(t, err, "codex execution: codex: boom\ngemini: boom")
+	})
+}
+
+func TestMergeFindings(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []reviewerOutput
+		want    string
+	}{
+		{name: "deduplicated by location",
+			outputs: []reviewerOutput{
+				{name: "codex", output: "Findings:\n- main.go:10 nil check missing\n  in handler\n- docs are outdated"},
+				{name: "gemini", output: "1) main.go:10: possible nil dereference\n2) Docs are  outdated"},
+			},
+			want: "- [codex, gemini] main.go:10 nil check missing\n  in handler\n- [codex, gemini] docs are outdated"},
+		{name: "reviewer without findings",
+			outputs: []reviewerOutput{
+				{name: "codex", output: "NO ISSUES FOUND"},
+				{name: "local", output: "* store.go:42 race on cache map"},
+			},
+			want: "- [local] store.go:42 race on cache map\n\nno issues found by: codex"},
+		{name: "no findings",
+			outputs: []reviewerOutput{{name: "codex", output: "NO ISSUES FOUND"}, {name: "gemini", output: "no issues found."}},
+			want:    "NO ISSUES FOUND (codex, gemini)"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, mergeFindings(tc.outputs))
+		})
+	}
+}
+
+func TestSplitFindings(t *testing.T) {
+	out := "Review summary:\n\n- a.go:1 first\ncontinued\n* b.go:2 second\n\nSome general remark\nover two lines\n\n" +
+		"3. c.go:3 third\n\nd.go:4: unchecked error\n\nOverall the code looks good."
+	assert.Equal(t, []string{"a.go:1 first\ncontinued", "b.go:2 second", "c.go:3 third", "d.go:4: unchecked error"},
+		splitFindings(out))
+	assert.Empty(t, splitFindings("NO ISSUES FOUND"))
+	assert.Empty(t, splitFindings("I reviewed the changes.\n\nOverall the code looks good."), "prose is not a finding")
+}
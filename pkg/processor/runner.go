@@ -8,11 +8,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/runfile"
 )
 
 // DefaultIterationDelay is the pause between iterations to allow system to settle.
@@ -32,20 +37,33 @@ const (
 type Config struct {
 	PlanFile         string         // path to plan file (required for full mode)
 	PlanDescription  string         // plan description for interactive plan creation mode
+	PlanTemplate     string         // plan template content for interactive plan creation mode, optional
 	ProgressPath     string         // path to progress file
+	BaseRef          string         // branch the reviewed changes are based on, default branch of the repository if empty
 	Mode             Mode           // execution mode
 	MaxIterations    int            // maximum iterations for task phase
 	Debug            bool           // enable debug output
 	NoColor          bool           // disable color output
 	IterationDelayMs int            // delay between iterations in milliseconds
 	TaskRetryCount   int            // number of times to retry failed tasks
+	ParallelTasks    int            // max tasks executed concurrently in git worktrees, 0 or 1 is sequential
 	CodexEnabled     bool           // whether codex review is enabled
+	Pipeline         []Step         // phases to execute, nil uses the default pipeline of the mode
+	StatePath        string         // path to run state checkpoint file, empty disables checkpoints
+	ReportPath       string         // path to review findings report file, empty disables the report
+	NotesPath        string         // path to notes file with answered questions, empty keeps answers in memory only
+	ApprovalPath     string         // path to pending phase approval file answered by the dashboard, empty disables it
+	ConfirmPhases    []Step         // phases followed by an approval gate, empty disables gates
+	WebApproval      bool           // approval gates are decided in the web dashboard only, terminal is not asked
+	Resume           bool           // continue from the stage and iteration saved in StatePath
+	Deadline         time.Time      // stop the run at a resumable point at this time, zero is no deadline
 	AppConfig        *config.Config // full application config (for executors and prompts)
 }
 
 //go:generate moq -out mocks/executor.go -pkg mocks -skip-ensure -fmt goimports . Executor
 //go:generate moq -out mocks/logger.go -pkg mocks -skip-ensure -fmt goimports . Logger
 //go:generate moq -out mocks/input_collector.go -pkg mocks -skip-ensure -fmt goimports . InputCollector
+//go:generate moq -out mocks/git_repo.go -pkg mocks -skip-ensure -fmt goimports . GitRepo
 
 // Executor runs CLI commands and returns results.
 type Executor interface {
@@ -64,43 +82,141 @@ type Logger interface {
 	Path() string
 }
 
-// InputCollector provides interactive input collection for plan creation.
+// InputCollector provides interactive input collection for plan creation and questions asked during tasks and reviews.
+// AskQuestion must return once ctx is canceled, approval gates cancel it when the decision comes from the dashboard.
 type InputCollector interface {
 	AskQuestion(ctx context.Context, question string, options []string) (string, error)
 }
 
+// GitRepo provides git operations used for run state checkpoints, phase approval gates and parallel task execution.
+type GitRepo interface {
+	Root() string
+	CurrentBranch() (string, error)
+	BaseRef(branch string) string
+	HeadHash() (string, error)
+	FileHasChanges(filePath string) (bool, error)
+	CommitsSince(hash string) ([]string, error)
+	ChangedFilesSince(hash string) ([]string, error)
+	DiffStatSince(hash string) (string, error)
+	AddWorktree(path, branch string) error
+	RemoveWorktree(path string) error
+	DeleteBranch(name string) error
+	CommitAll(dir, msg string) (bool, error)
+	MergeBranch(branch, msg string) error
+}
+
+// ErrMaxIterations is returned when a loop runs out of iterations before its work is completed.
+var ErrMaxIterations = errors.New("max iterations reached")
+
 // Runner orchestrates the execution loop.
 type Runner struct {
 	cfg            Config
 	log            Logger
-	claude         Executor
-	codex          Executor
+	task           Executor           // runs the task phase and plan creation
+	review         Executor           // runs claude review phases and evaluates external review output
+	external       []externalReviewer // run the external (codex) review loop in parallel
 	inputCollector InputCollector
+	git            GitRepo
+	pipeline       []Step                // phases to execute in order
+	step           int                   // index of the pipeline step being executed
+	resume         *RunState             // saved checkpoint to continue from, cleared once its step is reached
+	skipped        []int                 // indexes of pipeline steps skipped at approval gates, kept in checkpoints
+	report         *runfile.ReviewReport // structured findings of review iterations
+	notes          []string              // questions answered during task and review phases
+	notesMu        sync.Mutex            // serializes questions and notes of parallel tasks
 	iterationDelay time.Duration
 	taskRetryCount int
+	approvalPoll   time.Duration // how often a waiting approval gate checks the approval file
+
+	validationTimeout time.Duration // timeout for a single validation command, 0 means no timeout
+	validationRetries int           // task re-runs allowed to fix failed validation
+
+	usage *usageTracker // token usage and cost of executor calls
+
+	rateLimitBackoff time.Duration // first wait after a rate limit without reported reset time
 }
 
 // New creates a new Runner with the given configuration.
-// If codex is enabled but the binary is not found in PATH, it is automatically disabled with a warning.
+// Executors of the task phase, reviews and the external review loop are assigned in config,
+// claude and codex are used by default. Several external reviewers run in parallel as a review panel.
+// If an external reviewer is not found in PATH, it is dropped with a warning, and the codex phase
+// is automatically disabled if no reviewer is left.
 func New(cfg Config, log Logger) *Runner {
+	externalNames := []string{config.ExecutorCodex}
+	if cfg.AppConfig != nil && len(cfg.AppConfig.ExternalReviewExecutors) > 0 {
+		externalNames = cfg.AppConfig.ExternalReviewExecutors
+	}
+
+	// parallel tasks and review panels share the logger, wrap it before executors capture it in output handlers
+	if cfg.ParallelTasks > 1 || len(externalNames) > 1 {
+		log = &syncLogger{inner: log}
+	}
+
+	// executors assigned to roles, claude runs tasks and reviews and codex runs the external review by default
+	executors, commands := buildExecutors(cfg, log.PrintAligned)
+	taskName, reviewName := config.ExecutorClaude, config.ExecutorClaude
+	if cfg.AppConfig != nil {
+		taskName = assignedExecutor(executors, cfg.AppConfig.TaskExecutor, taskName)
+		reviewName = assignedExecutor(executors, cfg.AppConfig.ReviewExecutor, reviewName)
+	}
+
+	// reviewers of a panel get own executors without streaming, their output is printed once all finished
+	panelExecutors := executors
+	if len(externalNames) > 1 {
+		panelExecutors, _ = buildExecutors(cfg, nil)
+	}
+	reviewers := make([]externalReviewer, 0, len(externalNames))
+	for _, name := range externalNames {
+		e, ok := panelExecutors[name]
+		if !ok {
+			continue
+		}
+		// drop external reviewers not installed, http executors have no binary
+		if externalCmd, hasCmd := commands[name]; hasCmd && cfg.CodexEnabled {
+			if externalCmd == "" {
+				externalCmd = name
+			}
+			if _, err := exec.LookPath(externalCmd); err != nil {
+				action := "disabling codex review phase"
+				if len(externalNames) > 1 {
+					action = "removing it from the review panel"
+				}
+				if name == config.ExecutorCodex {
+					log.Print("warning: codex not found (%s: %v), "+action, externalCmd, err)
+				} else {
+					log.Print("warning: executor %s not found (%s: %v), "+action, name, externalCmd, err)
+				}
+				continue
+			}
+		}
+		reviewers = append(reviewers, externalReviewer{name: name, exec: e})
+	}
+	if len(reviewers) == 0 {
+		cfg.CodexEnabled = false
+	}
+
+	return newRunner(cfg, log, executors[taskName], executors[reviewName], reviewers...)
+}
+
+// buildExecutors creates built-in and config-defined executors by name, streaming their output to the handler.
+// returns commands of executors running a binary, http executors have none.
+func buildExecutors(cfg Config, output func(text string)) (executors map[string]Executor, commands map[string]string) {
 	// build claude executor with config values
 	claudeExec := &executor.ClaudeExecutor{
-		OutputHandler: func(text string) {
-			log.PrintAligned(text)
-		},
-		Debug: cfg.Debug,
+		OutputHandler: output,
+		Debug:         cfg.Debug,
 	}
 	if cfg.AppConfig != nil {
 		claudeExec.Command = cfg.AppConfig.ClaudeCommand
 		claudeExec.Args = cfg.AppConfig.ClaudeArgs
+		claudeExec.Timeout = cfg.AppConfig.IterationTimeout
+		claudeExec.IdleTimeout = cfg.AppConfig.IdleOutputTimeout
 	}
 
 	// build codex executor with config values
 	codexExec := &executor.CodexExecutor{
-		OutputHandler: func(text string) {
-			log.PrintAligned(text)
-		},
-		Debug: cfg.Debug,
+		OutputHandler: output,
+		Debug:         cfg.Debug,
 	}
 	if cfg.AppConfig != nil {
 		codexExec.Command = cfg.AppConfig.CodexCommand
@@ -110,23 +226,88 @@ func New(cfg Config, log Logger) *Runner {
 		codexExec.Sandbox = cfg.AppConfig.CodexSandbox
 	}
 
-	// auto-disable codex if the binary is not installed
-	if cfg.CodexEnabled {
-		codexCmd := codexExec.Command
-		if codexCmd == "" {
-			codexCmd = "codex"
+	executors = map[string]Executor{config.ExecutorClaude: claudeExec, config.ExecutorCodex: codexExec}
+	commands = map[string]string{config.ExecutorClaude: claudeExec.Command, config.ExecutorCodex: codexExec.Command}
+	if cfg.AppConfig == nil {
+		return executors, commands
+	}
+	for name, ec := range cfg.AppConfig.Executors {
+		if ec.Type == config.ExecutorTypeOpenAI {
+			executors[name] = newOpenAIExecutor(ec, output)
+			continue
+		}
+		executors[name] = newCommandExecutor(ec, output)
+		commands[name] = ec.Command
+	}
+	return executors, commands
+}
+
+// assignedExecutor returns the executor name configured for a role, def if the role is not assigned.
+func assignedExecutor(executors map[string]Executor, name, def string) string {
+	if _, ok := executors[name]; ok {
+		return name
+	}
+	return def
+}
+
+// newCommandExecutor creates an executor for a custom [executor:NAME] config section.
+func newCommandExecutor(ec config.ExecutorConfig, output func(text string)) *executor.CommandExecutor {
+	return &executor.CommandExecutor{
+		Name:          ec.Name,
+		Command:       ec.Command,
+		Args:          ec.Args,
+		JSONL:         ec.Output == config.ExecutorOutputJSONL,
+		TextPath:      ec.TextPath,
+		TextFilter:    ec.TextFilter,
+		Signals:       signalPatterns(ec),
+		Timeout:       ec.Timeout,
+		OutputHandler: output,
+	}
+}
+
+// signalPatterns compiles custom signal patterns of an executor, patterns are validated when config is loaded.
+func signalPatterns(ec config.ExecutorConfig) executor.SignalPatterns {
+	compile := func(pattern string) *regexp.Regexp {
+		if pattern == "" {
+			return nil
 		}
-		if _, err := exec.LookPath(codexCmd); err != nil {
-			log.Print("warning: codex not found (%s: %v), disabling codex review phase", codexCmd, err)
-			cfg.CodexEnabled = false
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil
 		}
+		return re
+	}
+	return executor.SignalPatterns{
+		Completed: compile(ec.SignalCompleted),
+		Failed:    compile(ec.SignalFailed),
+		Question:  compile(ec.SignalQuestion),
 	}
+}
 
-	return NewWithExecutors(cfg, log, claudeExec, codexExec)
+// newOpenAIExecutor creates an executor for an [executor:NAME] config section with type openai.
+func newOpenAIExecutor(ec config.ExecutorConfig, output func(text string)) *executor.OpenAIExecutor {
+	res := &executor.OpenAIExecutor{
+		Name:          ec.Name,
+		URL:           ec.URL,
+		Model:         ec.Model,
+		ContextSize:   ec.ContextSize,
+		Timeout:       ec.Timeout,
+		OutputHandler: output,
+	}
+	if ec.APIKeyEnv != "" {
+		res.APIKey = os.Getenv(ec.APIKeyEnv)
+	}
+	return res
 }
 
 // NewWithExecutors creates a new Runner with custom executors (for testing).
+// claude runs tasks and reviews, codex runs the external review loop.
 func NewWithExecutors(cfg Config, log Logger, claude, codex Executor) *Runner {
+	return newRunner(cfg, log, claude, claude, externalReviewer{name: config.ExecutorCodex, exec: codex})
+}
+
+// newRunner creates a new Runner with executors for the task phase, claude reviews and the external review loop.
+func newRunner(cfg Config, log Logger, task, review Executor, external ...externalReviewer) *Runner {
 	// determine iteration delay from config or default
 	iterDelay := DefaultIterationDelay
 	if cfg.IterationDelayMs > 0 {
@@ -142,145 +323,231 @@ func NewWithExecutors(cfg Config, log Logger, claude, codex Executor) *Runner {
 		retryCount = cfg.TaskRetryCount
 	}
 
-	return &Runner{
+	// determine validation settings from config or defaults
+	validationTimeout := DefaultValidationTimeout
+	if cfg.AppConfig != nil && cfg.AppConfig.ValidationTimeoutSet {
+		validationTimeout = time.Duration(cfg.AppConfig.ValidationTimeoutMs) * time.Millisecond
+	}
+	validationRetries := DefaultValidationRetries
+	if cfg.AppConfig != nil && cfg.AppConfig.ValidationRetriesSet {
+		validationRetries = cfg.AppConfig.ValidationRetries
+	}
+
+	if _, ok := log.(*syncLogger); !ok && (cfg.ParallelTasks > 1 || len(external) > 1) {
+		log = &syncLogger{inner: log}
+	}
+
+	pipeline := cfg.Pipeline
+	if len(pipeline) == 0 {
+		pipeline = DefaultPipeline(cfg.Mode)
+	}
+
+	usage := &usageTracker{}
+	if cfg.AppConfig != nil {
+		usage.maxCostUSD = cfg.AppConfig.MaxCostUSD
+		usage.maxTokens = cfg.AppConfig.MaxTokens
+	}
+
+	r := &Runner{
 		cfg:            cfg,
 		log:            log,
-		claude:         claude,
-		codex:          codex,
+		pipeline:       pipeline,
 		iterationDelay: iterDelay,
 		taskRetryCount: retryCount,
+		approvalPoll:   defaultApprovalPoll,
+
+		validationTimeout: validationTimeout,
+		validationRetries: validationRetries,
+
+		usage: usage,
+
+		rateLimitBackoff: DefaultRateLimitBackoff,
+	}
+	// executors are metered to account usage per phase and task and to enforce budget limits,
+	// rate limited runs are retried after the limit resets, runs killed by iteration or idle timeout are retried
+	r.task = r.wrapExecutor(task)
+	r.review = r.wrapExecutor(review)
+	for _, rv := range external {
+		r.external = append(r.external, externalReviewer{name: rv.name, exec: r.wrapExecutor(rv.exec)})
 	}
+	return r
 }
 
-// SetInputCollector sets the input collector for plan creation mode.
+// resumeSession returns a context continuing the claude session with the given id if resume_session is enabled.
+// the context is returned as-is for an empty id, i.e. for the first run.
+func (r *Runner) resumeSession(ctx context.Context, sessionID string) context.Context {
+	if sessionID == "" || r.cfg.AppConfig == nil || !r.cfg.AppConfig.ResumeSession {
+		return ctx
+	}
+	r.log.Print("resuming session %s", sessionID)
+	return executor.WithResumeSession(ctx, sessionID)
+}
+
+// wrapExecutor adds usage metering, rate limit waits and timeout retries to an executor.
+func (r *Runner) wrapExecutor(e Executor) Executor {
+	return &stallRetryExecutor{inner: &rateLimitExecutor{inner: &meteredExecutor{inner: e, r: r}, r: r}, r: r}
+}
+
+// SetInputCollector sets the input collector for plan creation mode and questions asked during tasks and reviews.
 func (r *Runner) SetInputCollector(c InputCollector) {
 	r.inputCollector = c
 }
 
-// Run executes the main loop based on configured mode.
+// SetGitRepo sets the git repository used for run state checkpoints and parallel task worktrees.
+func (r *Runner) SetGitRepo(g GitRepo) {
+	r.git = g
+}
+
+// Run executes the main loop based on configured mode and runs the final hooks.
 func (r *Runner) Run(ctx context.Context) error {
+	err := r.run(ctx)
+	r.runFinalHooks(ctx, err)
+	return err
+}
+
+// run executes the main loop based on configured mode.
+func (r *Runner) run(ctx context.Context) error {
+	var err error
 	switch r.cfg.Mode {
-	case ModeFull:
-		return r.runFull(ctx)
-	case ModeReview:
-		return r.runReviewOnly(ctx)
-	case ModeCodexOnly:
-		return r.runCodexOnly(ctx)
+	case ModeFull, ModeReview, ModeCodexOnly:
+		if err = r.loadResumeState(); err != nil {
+			return err
+		}
+		r.initReport()
+		r.initNotes()
+		err = r.runPipelineUntilDeadline(ctx)
+		r.logBlockedSummary()
+		r.logUsageSummary()
 	case ModePlan:
-		return r.runPlanCreation(ctx)
+		err = r.runPlanCreation(ctx)
+		r.logUsageSummary()
+		return err
 	default:
 		return fmt.Errorf("unknown mode: %s", r.cfg.Mode)
 	}
-}
 
-// runFull executes the complete pipeline: tasks → review → codex → review.
-func (r *Runner) runFull(ctx context.Context) error {
-	if r.cfg.PlanFile == "" {
-		return errors.New("plan file required for full mode")
+	if err != nil {
+		return err
 	}
 
-	// phase 1: task execution
-	r.log.SetPhase(PhaseTask)
-	r.log.PrintRaw("starting task execution phase\n")
-
-	if err := r.runTaskPhase(ctx); err != nil {
-		return fmt.Errorf("task phase: %w", err)
+	// run completed, checkpoint is no longer needed
+	if r.cfg.StatePath != "" {
+		if rmErr := RemoveState(r.cfg.StatePath); rmErr != nil {
+			r.log.Print("warning: %v", rmErr)
+		}
 	}
+	return nil
+}
 
-	// phase 2: first review pass - address ALL findings
-	r.log.SetPhase(PhaseReview)
-	r.log.PrintSection(NewGenericSection("claude review 0: all findings"))
-
-	if err := r.runClaudeReview(ctx, r.buildFirstReviewPrompt()); err != nil {
-		return fmt.Errorf("first review: %w", err)
+// loadResumeState loads the saved checkpoint when resume is requested.
+// a missing state file is not an error, the run simply starts from the beginning.
+func (r *Runner) loadResumeState() error {
+	if !r.cfg.Resume || r.cfg.StatePath == "" {
+		return nil
 	}
 
-	// phase 2.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	st, err := LoadState(r.cfg.StatePath)
+	if err != nil {
+		return fmt.Errorf("load run state: %w", err)
 	}
-
-	// phase 2.5: codex external review loop
-	r.log.SetPhase(PhaseCodex)
-	r.log.PrintSection(NewGenericSection("codex external review"))
-
-	if err := r.runCodexLoop(ctx); err != nil {
-		return fmt.Errorf("codex loop: %w", err)
+	if st == nil {
+		r.log.Print("no saved run state found at %s, starting from the beginning", r.cfg.StatePath)
+		return nil
+	}
+	if pipeline := FormatPipeline(r.pipeline); st.Pipeline != pipeline {
+		return fmt.Errorf("saved run state is for pipeline %q, current pipeline is %q", st.Pipeline, pipeline)
+	}
+	if st.Step >= len(r.pipeline) {
+		return fmt.Errorf("saved run state step %d is out of pipeline range", st.Step)
 	}
 
-	// phase 3: claude review loop (critical/major) after codex
-	r.log.SetPhase(PhaseReview)
-
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("post-codex review loop: %w", err)
+	if head := r.headCommit(); st.ReviewedCommit != "" && head != "" && head != st.ReviewedCommit {
+		r.log.Print("warning: HEAD moved since checkpoint (%s -> %s)", shortHash(st.ReviewedCommit), shortHash(head))
 	}
 
-	r.log.Print("all phases completed successfully")
+	r.log.Print("resuming from %s phase (step %d), iteration %d", r.pipeline[st.Step], st.Step+1, st.Iteration)
+	r.resume = st
+	r.skipped = st.Skipped
 	return nil
 }
 
-// runReviewOnly executes only the review pipeline: review → codex → review.
-func (r *Runner) runReviewOnly(ctx context.Context) error {
-	// phase 1: first review
-	r.log.SetPhase(PhaseReview)
-	r.log.PrintSection(NewGenericSection("claude review 0: all findings"))
-
-	if err := r.runClaudeReview(ctx, r.buildFirstReviewPrompt()); err != nil {
-		return fmt.Errorf("first review: %w", err)
+// resumePoint returns the iteration a pipeline step should start from and the claude response
+// to continue the codex loop with. iteration 0 means the step was completed or skipped at approval gate
+// in the resumed run and must be skipped. once the saved step is reached, resume state is cleared.
+func (r *Runner) resumePoint(index int, step Step) (iteration int, claudeResponse string) {
+	if r.resume == nil {
+		return 1, ""
 	}
 
-	// phase 1.1: claude review loop (critical/major) before codex
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("pre-codex review loop: %w", err)
+	if index < r.resume.Step {
+		r.log.Print("skipping %s phase (step %d), completed in previous run", step, index+1)
+		return 0, ""
 	}
-
-	// phase 2: codex external review loop
-	r.log.SetPhase(PhaseCodex)
-	r.log.PrintSection(NewGenericSection("codex external review"))
-
-	if err := r.runCodexLoop(ctx); err != nil {
-		return fmt.Errorf("codex loop: %w", err)
+	if slices.Contains(r.resume.Skipped, index) {
+		r.log.Print("skipping %s phase (step %d), skipped at approval gate in previous run", step, index+1)
+		return 0, ""
 	}
 
-	// phase 3: claude review loop (critical/major) after codex
-	r.log.SetPhase(PhaseReview)
-
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("post-codex review loop: %w", err)
+	iteration, claudeResponse = 1, ""
+	if index == r.resume.Step {
+		iteration, claudeResponse = r.resume.Iteration, r.resume.ClaudeResponse
 	}
-
-	r.log.Print("review phases completed successfully")
-	return nil
+	r.resume = nil
+	return iteration, claudeResponse
 }
 
-// runCodexOnly executes only the codex pipeline: codex → review.
-func (r *Runner) runCodexOnly(ctx context.Context) error {
-	// phase 1: codex external review loop
-	r.log.SetPhase(PhaseCodex)
-	r.log.PrintSection(NewGenericSection("codex external review"))
-
-	if err := r.runCodexLoop(ctx); err != nil {
-		return fmt.Errorf("codex loop: %w", err)
+// checkpoint saves run state for the iteration of the current step about to start.
+// failures are logged but don't stop execution since the checkpoint is a recovery aid.
+func (r *Runner) checkpoint(iteration int, claudeResponse string) {
+	if r.cfg.StatePath == "" {
+		return
 	}
+	st := RunState{
+		PlanFile:       r.cfg.PlanFile,
+		Mode:           r.cfg.Mode,
+		Pipeline:       FormatPipeline(r.pipeline),
+		Step:           r.step,
+		Phase:          r.pipeline[r.step].String(),
+		Iteration:      iteration,
+		ClaudeResponse: claudeResponse,
+		ReviewedCommit: r.headCommit(),
+		Skipped:        r.skipped,
+	}
+	if err := st.Save(r.cfg.StatePath); err != nil {
+		r.log.Print("warning: failed to save run state: %v", err)
+	}
+}
 
-	// phase 2: claude review loop (critical/major) after codex
-	r.log.SetPhase(PhaseReview)
-
-	if err := r.runClaudeReviewLoop(ctx); err != nil {
-		return fmt.Errorf("post-codex review loop: %w", err)
+// headCommit returns the current HEAD hash or empty string if unavailable.
+func (r *Runner) headCommit() string {
+	if r.git == nil {
+		return ""
+	}
+	hash, err := r.git.HeadHash()
+	if err != nil {
+		return ""
 	}
+	return hash
+}
 
-	r.log.Print("codex phases completed successfully")
-	return nil
+// shortHash returns the first 7 characters of a commit hash.
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
 }
 
 // runTaskPhase executes tasks until completion or max iterations.
-// executes ONE Task section per iteration.
-func (r *Runner) runTaskPhase(ctx context.Context) error {
+// executes ONE Task section per iteration, starting from the given iteration.
+func (r *Runner) runTaskPhase(ctx context.Context, start int) error {
 	prompt := r.buildTaskPrompt()
 	retryCount := 0
+	validationFailures := 0
+	validationReport := "" // failure report of the last validation run, injected into the next prompt
+	resumeID := ""         // session of the failed run, continued by the retry if resume_session is enabled
 
-	for i := 1; i <= r.cfg.MaxIterations; i++ {
+	for i := start; i <= r.cfg.MaxIterations; i++ {
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("task phase: %w", ctx.Err())
@@ -288,64 +555,137 @@ func (r *Runner) runTaskPhase(ctx context.Context) error {
 		}
 
 		r.log.PrintSection(NewTaskIterationSection(i))
+		r.checkpoint(i, "")
 
-		result := r.claude.Run(ctx, prompt)
+		iterPrompt := r.withNotes(prompt)
+		if validationReport != "" {
+			iterPrompt = r.buildValidationFailurePrompt(iterPrompt, validationReport)
+		}
+		env := hookEnv{phase: r.currentPhase(), task: r.currentTask(), iteration: i}
+		if err := r.runHook(ctx, hookPreTask, "", env); err != nil {
+			return err
+		}
+		result := r.task.Run(r.resumeSession(withUsageTask(ctx, env.task), resumeID), iterPrompt)
+		resumeID = ""
 		if result.Error != nil {
 			return fmt.Errorf("claude execution: %w", result.Error)
 		}
+		if err := r.runHook(ctx, hookPostTask, "", taskHookEnv(env, result)); err != nil {
+			return err
+		}
 
-		if result.Signal == SignalCompleted {
-			// verify plan actually has no uncompleted checkboxes
-			if r.hasUncompletedTasks() {
-				r.log.Print("warning: completion signal received but plan still has [ ] items, continuing...")
-				continue
+		// question asked, re-run the task with the answer
+		asked, err := r.handleQuestion(ctx, i, result.Output)
+		if err != nil {
+			return err
+		}
+		if asked {
+			r.log.Print("re-running task with the answer...")
+			resumeID = result.SessionID
+			time.Sleep(r.iterationDelay)
+			continue
+		}
+
+		// task blocked, continue with the next one
+		blocked, err := r.handleBlocked(result.Output, r.cfg.PlanFile, 0)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			retryCount, validationFailures, validationReport = 0, 0, ""
+			if !r.hasUncompletedTasks() {
+				r.log.PrintRaw("\nno tasks left to execute, starting code review...\n")
+				return nil
 			}
-			r.log.PrintRaw("\nall tasks completed, starting code review...\n")
-			return nil
+			r.log.Print("continuing with the next task...")
+			time.Sleep(r.iterationDelay)
+			continue
 		}
 
 		if result.Signal == SignalFailed {
 			if retryCount < r.taskRetryCount {
 				r.log.Print("task failed, retrying...")
 				retryCount++
+				resumeID = result.SessionID
 				time.Sleep(r.iterationDelay)
 				continue
 			}
 			return errors.New("task execution failed after retry (FAILED signal received)")
 		}
 
+		// validation gate, failed validation re-runs the task even if completion was signaled
+		report, err := r.runValidation(ctx, "", r.cfg.PlanFile)
+		if err != nil {
+			return err
+		}
+		if report != "" {
+			validationFailures++
+			if validationFailures > r.validationRetries {
+				return fmt.Errorf("validation failed after %d attempts", validationFailures)
+			}
+			r.log.Print("re-running task to fix validation failure (%d/%d)...", validationFailures, r.validationRetries)
+			validationReport = report
+			resumeID = result.SessionID
+			time.Sleep(r.iterationDelay)
+			continue
+		}
+		validationFailures, validationReport = 0, ""
+
+		if result.Signal == SignalCompleted {
+			// verify plan actually has no uncompleted checkboxes
+			if r.hasUncompletedTasks() {
+				r.log.Print("warning: completion signal received but plan still has [ ] items, continuing...")
+				continue
+			}
+			r.log.PrintRaw("\nall tasks completed, starting code review...\n")
+			return nil
+		}
+
 		retryCount = 0
 		// continue with same prompt - it reads from plan file each time
 		time.Sleep(r.iterationDelay)
 	}
 
-	return fmt.Errorf("max iterations (%d) reached without completion", r.cfg.MaxIterations)
+	return fmt.Errorf("%w: %d iterations without completion", ErrMaxIterations, r.cfg.MaxIterations)
 }
 
 // runClaudeReview runs Claude review with the given prompt until REVIEW_DONE.
+// the review re-runs with the answer if it asks a question, up to the review iteration cap.
 func (r *Runner) runClaudeReview(ctx context.Context, prompt string) error {
-	result := r.claude.Run(ctx, prompt)
-	if result.Error != nil {
-		return fmt.Errorf("claude execution: %w", result.Error)
-	}
+	for i := 1; ; i++ {
+		result := r.review.Run(ctx, r.withNotes(prompt))
+		if result.Error != nil {
+			return fmt.Errorf("claude execution: %w", result.Error)
+		}
+		r.recordFindings(i, result.Output)
 
-	if result.Signal == SignalFailed {
-		return errors.New("review failed (FAILED signal received)")
-	}
+		asked, err := r.handleQuestion(ctx, i, result.Output)
+		if err != nil {
+			return err
+		}
+		if asked && i < r.maxReviewIterations() {
+			r.log.Print("re-running review with the answer...")
+			time.Sleep(r.iterationDelay)
+			continue
+		}
 
-	if !IsReviewDone(result.Signal) {
-		r.log.Print("warning: first review pass did not complete cleanly, continuing...")
-	}
+		if result.Signal == SignalFailed {
+			return errors.New("review failed (FAILED signal received)")
+		}
 
-	return nil
+		if !IsReviewDone(result.Signal) {
+			r.log.Print("warning: first review pass did not complete cleanly, continuing...")
+		}
+
+		return nil
+	}
 }
 
 // runClaudeReviewLoop runs claude review iterations using second review prompt.
-func (r *Runner) runClaudeReviewLoop(ctx context.Context) error {
-	// review iterations = 10% of max_iterations (min 3)
-	maxReviewIterations := max(3, r.cfg.MaxIterations/10)
-
-	for i := 1; i <= maxReviewIterations; i++ {
+// start is the first iteration to run.
+func (r *Runner) runClaudeReviewLoop(ctx context.Context, start int) error {
+	sessionID := "" // session of the previous iteration
+	for i := start; i <= r.maxReviewIterations(); i++ {
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("review: %w", ctx.Err())
@@ -353,11 +693,24 @@ func (r *Runner) runClaudeReviewLoop(ctx context.Context) error {
 		}
 
 		r.log.PrintSection(NewClaudeReviewSection(i, ": critical/major"))
+		r.checkpoint(i, "")
 
-		result := r.claude.Run(ctx, r.buildSecondReviewPrompt())
+		result := r.review.Run(r.resumeSession(ctx, sessionID), r.withNotes(r.buildSecondReviewPrompt()))
 		if result.Error != nil {
 			return fmt.Errorf("claude execution: %w", result.Error)
 		}
+		r.recordFindings(i, result.Output)
+		sessionID = result.SessionID
+
+		asked, err := r.handleQuestion(ctx, i, result.Output)
+		if err != nil {
+			return err
+		}
+		if asked {
+			r.log.Print("re-running review with the answer...")
+			time.Sleep(r.iterationDelay)
+			continue
+		}
 
 		if result.Signal == SignalFailed {
 			return errors.New("review failed (FAILED signal received)")
@@ -377,19 +730,17 @@ func (r *Runner) runClaudeReviewLoop(ctx context.Context) error {
 }
 
 // runCodexLoop runs the codex-claude review loop until no findings.
-func (r *Runner) runCodexLoop(ctx context.Context) error {
+// start is the first iteration to run, claudeResponse is the response from the previous
+// iteration (empty when starting fresh).
+func (r *Runner) runCodexLoop(ctx context.Context, start int, claudeResponse string) error {
 	// skip codex phase if disabled
 	if !r.cfg.CodexEnabled {
 		r.log.Print("codex review disabled, skipping...")
 		return nil
 	}
 
-	// codex iterations = 20% of max_iterations (min 3)
-	maxCodexIterations := max(3, r.cfg.MaxIterations/5)
-
-	var claudeResponse string // first iteration has no prior response
-
-	for i := 1; i <= maxCodexIterations; i++ {
+	evalSessionID := "" // session of the previous claude evaluation
+	for i := start; i <= r.maxCodexIterations(); i++ {
 		select {
 		case <-ctx.Done():
 			return fmt.Errorf("codex loop: %w", ctx.Err())
@@ -397,33 +748,47 @@ func (r *Runner) runCodexLoop(ctx context.Context) error {
 		}
 
 		r.log.PrintSection(NewCodexIterationSection(i))
+		r.checkpoint(i, claudeResponse)
 
-		// run codex analysis
-		codexResult := r.codex.Run(ctx, r.buildCodexPrompt(i == 1, claudeResponse))
-		if codexResult.Error != nil {
-			return fmt.Errorf("codex execution: %w", codexResult.Error)
+		// run codex analysis, diff args let executors without tools gather the reviewed diff
+		codexCtx := executor.WithDiffArgs(ctx, r.codexDiffArgs(i == 1)...)
+		codexOutput, err := r.runExternalReview(codexCtx, r.buildCodexPrompt(i == 1, claudeResponse))
+		if err != nil {
+			return err
 		}
 
-		if codexResult.Output == "" {
+		if codexOutput == "" {
 			r.log.Print("codex review returned no output, skipping...")
 			break
 		}
 
 		// show codex findings summary before Claude evaluation
-		r.showCodexSummary(codexResult.Output)
+		r.showCodexSummary(codexOutput)
 
 		// pass codex output to claude for evaluation and fixing
 		r.log.SetPhase(PhaseClaudeEval)
 		r.log.PrintSection(NewClaudeEvalSection())
-		claudeResult := r.claude.Run(ctx, r.buildCodexEvaluationPrompt(codexResult.Output))
+		claudeResult := r.review.Run(r.resumeSession(ctx, evalSessionID), r.withNotes(r.buildCodexEvaluationPrompt(codexOutput)))
 
 		// restore codex phase for next iteration
 		r.log.SetPhase(PhaseCodex)
 		if claudeResult.Error != nil {
 			return fmt.Errorf("claude execution: %w", claudeResult.Error)
 		}
+		r.recordFindings(i, claudeResult.Output)
 
 		claudeResponse = claudeResult.Output
+		evalSessionID = claudeResult.SessionID
+
+		// question asked, the next iteration reviews again with the answer in the evaluation prompt
+		asked, err := r.handleQuestion(ctx, i, claudeResult.Output)
+		if err != nil {
+			return err
+		}
+		if asked {
+			time.Sleep(r.iterationDelay)
+			continue
+		}
 
 		// exit only when claude sees "no findings" from codex
 		if IsCodexDone(claudeResult.Signal) {
@@ -438,6 +803,15 @@ func (r *Runner) runCodexLoop(ctx context.Context) error {
 	return nil
 }
 
+// codexDiffArgs returns git diff arguments of the changes codex reviews.
+// the first iteration reviews the whole branch, later ones the uncommitted fixes of the previous iteration.
+func (r *Runner) codexDiffArgs(isFirst bool) []string {
+	if isFirst {
+		return []string{r.baseRef() + "...HEAD"}
+	}
+	return nil
+}
+
 // buildCodexPrompt creates the prompt for codex review.
 func (r *Runner) buildCodexPrompt(isFirst bool, claudeResponse string) string {
 	// build plan context if available
@@ -452,13 +826,10 @@ The code implements the plan at: %s
 	}
 
 	// different diff command based on iteration
-	var diffInstruction, diffDescription string
+	diffInstruction := "Run: " + strings.TrimSpace("git diff "+strings.Join(r.codexDiffArgs(isFirst), " "))
+	diffDescription := "uncommitted changes (Claude's fixes from previous iteration)"
 	if isFirst {
-		diffInstruction = "Run: git diff master...HEAD"
-		diffDescription = "code changes between master and HEAD branch"
-	} else {
-		diffInstruction = "Run: git diff"
-		diffDescription = "uncommitted changes (Claude's fixes from previous iteration)"
+		diffDescription = "code changes between " + r.baseRef() + " and HEAD branch"
 	}
 
 	basePrompt := fmt.Sprintf(`%sReview the %s.
@@ -490,28 +861,45 @@ If Claude's arguments are invalid, explain why the issues still exist.`, basePro
 	return basePrompt
 }
 
-// hasUncompletedTasks checks if plan file has any uncompleted checkboxes.
+// maxReviewIterations returns the iteration cap of claude review loops and custom phases,
+// 10% of max_iterations (min 3).
+func (r *Runner) maxReviewIterations() int {
+	return max(3, r.cfg.MaxIterations/10)
+}
+
+// maxCodexIterations returns the iteration cap of the codex loop, 20% of max_iterations (min 3).
+func (r *Runner) maxCodexIterations() int {
+	return max(3, r.cfg.MaxIterations/5)
+}
+
+// maxPlanIterations returns the iteration cap of interactive plan creation, 20% of max_iterations (min 5).
+func (r *Runner) maxPlanIterations() int {
+	return max(5, r.cfg.MaxIterations/5)
+}
+
+// hasUncompletedTasks checks if plan file has any task section with uncompleted checkboxes, the same way
+// the dashboard tracks tasks. checkboxes outside of task sections, in "## Manual Steps" and in ignore blocks
+// are not counted, blocked items ("[~]", "[-]") are not uncompleted.
 // Checks both original path and completed/ subdirectory.
 func (r *Runner) hasUncompletedTasks() bool {
 	// try original path first
-	content, err := os.ReadFile(r.cfg.PlanFile)
+	doc, err := r.planGrammar().Load(r.cfg.PlanFile)
 	if err != nil {
 		// try completed/ subdirectory as fallback
 		completedPath := filepath.Join(filepath.Dir(r.cfg.PlanFile), "completed", filepath.Base(r.cfg.PlanFile))
-		content, err = os.ReadFile(completedPath) //nolint:gosec // planFile from CLI args
-		if err != nil {
+		if doc, err = r.planGrammar().Load(completedPath); err != nil {
 			return true // assume incomplete if can't read from either location
 		}
 	}
+	return doc.HasOpenItems()
+}
 
-	// look for uncompleted checkbox pattern: [ ] (not [x])
-	for line := range strings.SplitSeq(string(content), "\n") {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "- [ ]") {
-			return true
-		}
+// planGrammar returns the configured grammar of plan task headers and checkboxes.
+func (r *Runner) planGrammar() plan.Grammar {
+	if r.cfg.AppConfig == nil {
+		return plan.Grammar{}
 	}
-	return false
+	return r.cfg.AppConfig.PlanGrammar
 }
 
 // showCodexSummary displays a condensed summary of codex output before Claude evaluation.
@@ -557,8 +945,9 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 	r.log.PrintRaw("starting interactive plan creation\n")
 	r.log.Print("plan request: %s", r.cfg.PlanDescription)
 
-	// plan iterations use 20% of max_iterations (min 5)
-	maxPlanIterations := max(5, r.cfg.MaxIterations/5)
+	maxPlanIterations := r.maxPlanIterations()
+	startTime := time.Now() // plans modified after start are created by this run
+	lintReport := ""        // format errors of the created plan, sent back to claude to fix
 
 	for i := 1; i <= maxPlanIterations; i++ {
 		select {
@@ -570,7 +959,10 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 		r.log.PrintSection(NewPlanIterationSection(i))
 
 		prompt := r.buildPlanPrompt()
-		result := r.claude.Run(ctx, prompt)
+		if lintReport != "" {
+			prompt += fmt.Sprintf(planLintTemplate, r.planFormatHint(), lintReport)
+		}
+		result := r.task.Run(ctx, prompt)
 		if result.Error != nil {
 			return fmt.Errorf("claude execution: %w", result.Error)
 		}
@@ -579,8 +971,16 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 			return errors.New("plan creation failed (FAILED signal received)")
 		}
 
-		// check for PLAN_READY signal
+		// check for PLAN_READY signal, accepted once the created plan passes the plan linter
 		if IsPlanReady(result.Signal) {
+			if lintReport = r.lintCreatedPlan(startTime); lintReport != "" {
+				if i < maxPlanIterations {
+					r.log.Print("plan has format errors, asking to fix them:\n%s", lintReport)
+					time.Sleep(r.iterationDelay)
+					continue
+				}
+				r.log.Print("warning: plan still has format errors:\n%s", lintReport)
+			}
 			r.log.Print("plan creation completed")
 			return nil
 		}
@@ -611,5 +1011,5 @@ func (r *Runner) runPlanCreation(ctx context.Context) error {
 		time.Sleep(r.iterationDelay)
 	}
 
-	return fmt.Errorf("max plan iterations (%d) reached without completion", maxPlanIterations)
+	return fmt.Errorf("%w: max plan iterations (%d) without completion", ErrMaxIterations, maxPlanIterations)
 }
@@ -3,8 +3,10 @@ package processor_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/processor/mocks"
 )
@@ -166,6 +169,52 @@ func TestRunner_RunCodexOnly_Success(t *testing.T) {
 	assert.Len(t, codex.RunCalls(), 1)
 }
 
+func TestRunner_RunCodexOnly_DiffArgs(t *testing.T) {
+	claude := newMockExecutor([]executor.Result{
+		{Output: "fixed foo.go"},                                    // codex evaluation, first iteration
+		{Output: "done", Signal: processor.SignalCodexDone},         // codex evaluation, second iteration
+		{Output: "review done", Signal: processor.SignalReviewDone}, // post-codex review loop
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "found issue"}, {Output: "still an issue"}})
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex)
+	require.NoError(t, r.Run(context.Background()))
+
+	calls := codex.RunCalls()
+	require.Len(t, calls, 2)
+	args, ok := executor.DiffArgs(calls[0].Ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"master...HEAD"}, args, "first iteration reviews the branch")
+	assert.Contains(t, calls[0].Prompt, "Run: git diff master...HEAD")
+	args, ok = executor.DiffArgs(calls[1].Ctx)
+	assert.True(t, ok)
+	assert.Empty(t, args, "next iterations review uncommitted fixes")
+	assert.Contains(t, calls[1].Prompt, "Run: git diff\n")
+}
+
+func TestRunner_RunCodexOnly_BaseRef(t *testing.T) {
+	claude := newMockExecutor([]executor.Result{
+		{Output: "done", Signal: processor.SignalCodexDone},
+		{Output: "review done", Signal: processor.SignalReviewDone},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "found issue"}})
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		BaseRef: "develop", AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, codex)
+	require.NoError(t, r.Run(context.Background()))
+
+	calls := codex.RunCalls()
+	require.Len(t, calls, 1)
+	args, _ := executor.DiffArgs(calls[0].Ctx)
+	assert.Equal(t, []string{"develop...HEAD"}, args)
+	assert.Contains(t, calls[0].Prompt, "Review the code changes between develop and HEAD branch.")
+	assert.Contains(t, calls[0].Prompt, "Run: git diff develop...HEAD")
+	assert.Contains(t, claude.RunCalls()[1].Prompt, "git diff develop...HEAD", "review prompt uses the base ref")
+}
+
 func TestRunner_RunCodexOnly_NoFindings(t *testing.T) {
 	log := newMockLogger("progress.txt")
 	claude := newMockExecutor([]executor.Result{
@@ -236,6 +285,164 @@ func TestRunner_TaskPhase_MaxIterations(t *testing.T) {
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "max iterations")
+	assert.ErrorIs(t, err, processor.ErrMaxIterations)
+}
+
+func TestRunner_TaskPhase_Validation(t *testing.T) {
+	newPlan := func(t *testing.T, command string) (planFile, fixed string) {
+		t.Helper()
+		tmpDir := t.TempDir()
+		planFile = filepath.Join(tmpDir, "plan.md")
+		fixed = filepath.Join(tmpDir, "fixed.txt")
+		plan := fmt.Sprintf("# Plan\n\n## Validation Commands\n- `%s`\n\n### Task 1: Fix\n- [ ] fix it\n", command)
+		require.NoError(t, os.WriteFile(planFile, []byte(plan), 0o600))
+		return planFile, fixed
+	}
+	checkPlan := func(t *testing.T, planFile string) {
+		t.Helper()
+		data, err := os.ReadFile(planFile) //nolint:gosec // test
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(planFile, []byte(strings.ReplaceAll(string(data), "[ ]", "[x]")), 0o600))
+	}
+	pipeline, err := processor.ParsePipeline("tasks")
+	require.NoError(t, err)
+
+	t.Run("failure re-runs task with failure output", func(t *testing.T) {
+		planFile, fixed := newPlan(t, "test -f fixed.txt || (echo missing fixed.txt; exit 1)")
+		var prompts []string
+		claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, prompt string) executor.Result {
+			prompts = append(prompts, prompt)
+			checkPlan(t, planFile)
+			if len(prompts) == 2 {
+				require.NoError(t, os.WriteFile(fixed, []byte("ok"), 0o600))
+			}
+			return executor.Result{Output: "done", Signal: processor.SignalCompleted}
+		}}
+		t.Chdir(filepath.Dir(planFile))
+
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Len(t, prompts, 2, "completion with failed validation must not finish the phase")
+		assert.NotContains(t, prompts[0], "VALIDATION FAILED")
+		assert.Contains(t, prompts[1], "VALIDATION FAILED")
+		assert.Contains(t, prompts[1], "missing fixed.txt")
+
+		var aligned []string
+		for _, c := range log.PrintAlignedCalls() {
+			aligned = append(aligned, c.Text)
+		}
+		assert.Contains(t, aligned, "missing fixed.txt", "validation output goes to progress log")
+	})
+
+	t.Run("fails after validation retries exhausted", func(t *testing.T) {
+		planFile, _ := newPlan(t, "false")
+		claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			checkPlan(t, planFile)
+			return executor.Result{Output: "done", Signal: processor.SignalCompleted}
+		}}
+		appCfg := testAppConfig(t)
+		appCfg.ValidationRetries = 2
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation failed after 3 attempts")
+		assert.Len(t, claude.RunCalls(), 3)
+	})
+
+	t.Run("command timeout counts as failure", func(t *testing.T) {
+		planFile, _ := newPlan(t, "sleep 10")
+		var prompts []string
+		claude := &mocks.ExecutorMock{RunFunc: func(_ context.Context, prompt string) executor.Result {
+			prompts = append(prompts, prompt)
+			checkPlan(t, planFile)
+			return executor.Result{Output: "done", Signal: processor.SignalCompleted}
+		}}
+		appCfg := testAppConfig(t)
+		appCfg.ValidationTimeoutMs, appCfg.ValidationRetries = 100, 0
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation failed after 1 attempts")
+		assert.Len(t, prompts, 1)
+	})
+}
+
+func TestRunner_Usage(t *testing.T) {
+	pipeline, err := processor.ParsePipeline("tasks, review_first")
+	require.NoError(t, err)
+
+	newPlan := func(t *testing.T) string {
+		t.Helper()
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		plan := "# Plan\n### Task 1: A\n- [ ] a\n### Task 2: B\n- [ ] b\n"
+		require.NoError(t, os.WriteFile(planFile, []byte(plan), 0o600))
+		return planFile
+	}
+	// each claude call completes the first open item and reports usage
+	completingExecutor := func(t *testing.T, planFile string) *mocks.ExecutorMock {
+		return &mocks.ExecutorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			data, err := os.ReadFile(planFile) //nolint:gosec // test
+			require.NoError(t, err)
+			plan := strings.Replace(string(data), "[ ]", "[x]", 1)
+			require.NoError(t, os.WriteFile(planFile, []byte(plan), 0o600))
+			res := executor.Result{Output: "done", Usage: executor.Usage{InputTokens: 100, OutputTokens: 50, CostUSD: 0.5}}
+			if !strings.Contains(plan, "[ ]") {
+				res.Signal = processor.SignalCompleted
+			}
+			if strings.Contains(string(data), "[x] b") {
+				res.Signal = processor.SignalReviewDone // review call, plan already complete
+			}
+			return res
+		}}
+	}
+
+	t.Run("aggregates usage per phase and task", func(t *testing.T) {
+		planFile := newPlan(t)
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, completingExecutor(t, planFile), newMockExecutor(nil))
+		require.NoError(t, r.Run(context.Background()))
+
+		assert.Equal(t, 450, r.Usage().Tokens())
+		assert.InDelta(t, 1.5, r.Usage().CostUSD, 1e-9)
+
+		var lines []string
+		for _, c := range log.PrintCalls() {
+			lines = append(lines, fmt.Sprintf(c.Format, c.Args...))
+		}
+		assert.Contains(t, lines, "phase tasks: 300 tokens (in 200, out 100), $1.0000")
+		assert.Contains(t, lines, "phase review_first: 150 tokens (in 100, out 50), $0.5000")
+		assert.Contains(t, lines, "task 1: 150 tokens (in 100, out 50), $0.5000")
+		assert.Contains(t, lines, "task 2: 150 tokens (in 100, out 50), $0.5000")
+		assert.Contains(t, lines, "total: 450 tokens (in 300, out 150), $1.5000")
+	})
+
+	t.Run("stops when budget exceeded", func(t *testing.T) {
+		planFile := newPlan(t)
+		appCfg := testAppConfig(t)
+		appCfg.MaxCostUSD = 0.5
+		claude := completingExecutor(t, planFile)
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		require.ErrorIs(t, err, processor.ErrBudgetExceeded)
+		assert.Contains(t, err.Error(), "reached max_cost_usd $0.50")
+		assert.Len(t, claude.RunCalls(), 1, "no new calls after the limit is reached")
+	})
 }
 
 func TestRunner_TaskPhase_ContextCanceled(t *testing.T) {
@@ -387,6 +594,22 @@ func TestRunner_HasUncompletedTasks(t *testing.T) {
 			content:  "# Plan\n- [x] Task 1\n  - [ ] Subtask",
 			expected: true,
 		},
+		{
+			name:     "uncompleted outside of task sections",
+			content:  "# Plan\n### Task 1: A\n- [x] done\n\n## Success criteria\n- [ ] users are happy\n",
+			expected: false,
+		},
+		{
+			name: "uncompleted in manual steps and ignore block",
+			content: "# Plan\n### Task 1: A\n- [x] done\n<!-- ralphex:ignore -->\n- [ ] check staging\n" +
+				"<!-- /ralphex:ignore -->\n## Manual Steps\n- [ ] announce\n",
+			expected: false,
+		},
+		{
+			name:     "uncompleted task section",
+			content:  "# Plan\n### Task 1: A\n- [x] done\n### Task 2: B\n- [ ] todo\n",
+			expected: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -407,6 +630,23 @@ func TestRunner_HasUncompletedTasks(t *testing.T) {
 	}
 }
 
+func TestRunner_HasUncompletedTasks_PlanGrammar(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n## Step 1 - A\n* (x) done\n## Step 2 - B\n* ( ) todo\n"), 0o600))
+
+	appCfg := testAppConfig(t)
+	grammar, err := plan.NewGrammar(`^##\s+Step\s+(?P<num>\d+)\s+-\s+(?P<title>.*)$`, `^\s*\*\s+\((?P<state>[ x~])\)\s*(?P<text>.*)$`)
+	require.NoError(t, err)
+	appCfg.PlanGrammar = grammar
+
+	r := processor.NewWithExecutors(processor.Config{PlanFile: planFile, AppConfig: appCfg}, newMockLogger(""),
+		newMockExecutor(nil), newMockExecutor(nil))
+	assert.True(t, r.TestHasUncompletedTasks(), "open checkbox in step 2")
+
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n## Step 1 - A\n* (x) done\n## Notes\n- [ ] default syntax\n"), 0o600))
+	assert.False(t, r.TestHasUncompletedTasks(), "default syntax is not a checkbox of the grammar")
+}
+
 func TestRunner_TaskRetryCount_UsedCorrectly(t *testing.T) {
 	tmpDir := t.TempDir()
 	planFile := filepath.Join(tmpDir, "plan.md")
@@ -440,6 +680,57 @@ func TestRunner_TaskRetryCount_UsedCorrectly(t *testing.T) {
 	assert.Len(t, claude.RunCalls(), 3)
 }
 
+func TestRunner_ResumeSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+	pipeline, err := processor.ParsePipeline("tasks, review_loop")
+	require.NoError(t, err)
+
+	run := func(t *testing.T, resume bool) (*mocks.ExecutorMock, *mocks.LoggerMock) {
+		t.Helper()
+		claude := newMockExecutor([]executor.Result{
+			{Output: "error", Signal: processor.SignalFailed, SessionID: "task-1"},      // task fails
+			{Output: "done", Signal: processor.SignalCompleted, SessionID: "task-2"},    // retry
+			{Output: "fixed issues", SessionID: "review-1"},                             // review iteration 1
+			{Output: "review done", Signal: processor.SignalReviewDone, SessionID: "x"}, // review iteration 2
+		})
+		appCfg := testAppConfig(t)
+		appCfg.ResumeSession = resume
+		log := newMockLogger("progress.txt")
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 30, TaskRetryCount: 1,
+			IterationDelayMs: 1, Pipeline: pipeline, AppConfig: appCfg}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		require.NoError(t, r.Run(context.Background()))
+		require.Len(t, claude.RunCalls(), 4)
+		return claude, log
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		claude, log := run(t, true)
+		var resumed []string
+		for _, call := range claude.RunCalls() {
+			resumed = append(resumed, executor.ResumeSession(call.Ctx))
+		}
+		assert.Equal(t, []string{"", "task-1", "", "review-1"}, resumed)
+
+		var sessions []any
+		for _, call := range log.PrintCalls() {
+			if call.Format == "session: %s" {
+				sessions = append(sessions, call.Args...)
+			}
+		}
+		assert.Equal(t, []any{"task-1", "task-2", "review-1", "x"}, sessions, "session ids are logged per iteration")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		claude, _ := run(t, false)
+		for _, call := range claude.RunCalls() {
+			assert.Empty(t, executor.ResumeSession(call.Ctx))
+		}
+	})
+}
+
 // newMockInputCollector creates a mock input collector with predefined answers.
 func newMockInputCollector(answers []string) *mocks.InputCollectorMock {
 	idx := 0
@@ -589,6 +880,7 @@ func TestRunner_RunPlan_MaxIterations(t *testing.T) {
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "max plan iterations")
+	assert.ErrorIs(t, err, processor.ErrMaxIterations)
 }
 
 func TestRunner_RunPlan_ContextCanceled(t *testing.T) {
@@ -699,3 +991,242 @@ func TestRunner_New_CodexNotInstalled_AutoDisables(t *testing.T) {
 	// verify runner was created (auto-disable happens at construction time)
 	assert.NotNil(t, r, "runner should be created even when codex not found")
 }
+
+func TestRunner_New_OpenAIExternalExecutor(t *testing.T) {
+	log := newMockLogger("progress.txt")
+	appCfg := testAppConfig(t)
+	appCfg.CodexCommand = "/nonexistent/path/to/codex"
+	appCfg.ExternalReviewExecutors = []string{"local"}
+	appCfg.Executors = map[string]config.ExecutorConfig{"local": {Name: "local", Type: config.ExecutorTypeOpenAI,
+		URL: "http://localhost:11434/v1", Model: "qwen"}}
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+	r := processor.New(cfg, log)
+	require.NotNil(t, r)
+	for _, call := range log.PrintCalls() {
+		assert.NotContains(t, call.Format, "not found", "http executor has no binary to look up")
+	}
+}
+
+func TestRunner_New_ReviewPanelDropsMissingReviewer(t *testing.T) {
+	log := newMockLogger("progress.txt")
+	appCfg := testAppConfig(t)
+	appCfg.CodexCommand = "/nonexistent/path/to/codex"
+	appCfg.ExternalReviewExecutors = []string{"codex", "local"}
+	appCfg.Executors = map[string]config.ExecutorConfig{"local": {Name: "local", Type: config.ExecutorTypeOpenAI,
+		URL: "http://localhost:11434/v1", Model: "qwen"}}
+
+	cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}
+	r := processor.New(cfg, log)
+	require.NotNil(t, r)
+
+	var warnings []string
+	for _, call := range log.PrintCalls() {
+		if strings.HasPrefix(call.Format, "warning:") {
+			warnings = append(warnings, call.Format)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "codex not found")
+	assert.Contains(t, warnings[0], "removing it from the review panel")
+}
+
+func TestRunner_New_CustomExecutorRoles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("custom executor test uses sh")
+	}
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+
+	appCfg := testAppConfig(t)
+	appCfg.TaskExecutor = "tasker"
+	appCfg.ReviewExecutor = "reviewer"
+	appCfg.Executors = map[string]config.ExecutorConfig{
+		"tasker": {Name: "tasker", Command: "sh", Output: config.ExecutorOutputText, SignalCompleted: `(?m)^STATUS: DONE$`,
+			Args: fmt.Sprintf(`-c "cat > %s; echo 'STATUS: DONE'"`, filepath.Join(tmpDir, "task.txt"))},
+		"reviewer": {Name: "reviewer", Command: "sh", Output: config.ExecutorOutputText,
+			Args: fmt.Sprintf(`-c "cat > %s; echo '<<<RALPHEX:REVIEW_DONE>>>'"`, filepath.Join(tmpDir, "review.txt"))},
+	}
+	pipeline, err := processor.ParsePipeline("tasks, review_first")
+	require.NoError(t, err)
+
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+		Pipeline: pipeline, AppConfig: appCfg}
+	r := processor.New(cfg, newMockLogger("progress.txt"))
+	require.NoError(t, r.Run(context.Background()), "custom done signal completes the task phase")
+
+	taskPrompt, err := os.ReadFile(filepath.Join(tmpDir, "task.txt")) //nolint:gosec // test file in temp dir
+	require.NoError(t, err)
+	assert.Contains(t, string(taskPrompt), planFile, "task prompt is passed to task executor")
+	reviewPrompt, err := os.ReadFile(filepath.Join(tmpDir, "review.txt")) //nolint:gosec // test file in temp dir
+	require.NoError(t, err)
+	assert.NotEmpty(t, reviewPrompt, "review prompt is passed to review executor")
+}
+
+func TestRunner_Resume(t *testing.T) {
+	const fullPipeline = "tasks, review_first, review_loop, codex, review_loop"
+
+	t.Run("continues codex loop from saved iteration with saved claude response", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		statePath := filepath.Join(tmpDir, "progress.state.json")
+
+		st := processor.RunState{Mode: processor.ModeFull, Pipeline: fullPipeline, Step: 3, Iteration: 2,
+			ClaudeResponse: "previous claude answer", ReviewedCommit: "abc"}
+		require.NoError(t, st.Save(statePath))
+
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "done", Signal: processor.SignalCodexDone},         // codex evaluation
+			{Output: "review done", Signal: processor.SignalReviewDone}, // post-codex review loop
+		})
+		codex := newMockExecutor([]executor.Result{{Output: "found issue"}})
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+			IterationDelayMs: 1, StatePath: statePath, Resume: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, codex)
+		r.SetGitRepo(&mocks.GitRepoMock{HeadHashFunc: func() (string, error) { return "def", nil },
+			BaseRefFunc: func(string) string { return "master" }})
+		require.NoError(t, r.Run(context.Background()))
+
+		require.Len(t, codex.RunCalls(), 1)
+		prompt := codex.RunCalls()[0].Prompt
+		assert.Contains(t, prompt, "previous claude answer")
+		assert.Contains(t, prompt, "Run: git diff\n", "resumed iteration 2 reviews uncommitted changes")
+		assert.Len(t, claude.RunCalls(), 2, "task and pre-codex stages skipped")
+
+		sections := log.PrintSectionCalls()
+		require.NotEmpty(t, sections)
+		var codexIter []int
+		for _, s := range sections {
+			if s.Section.Type == processor.SectionCodexIteration {
+				codexIter = append(codexIter, s.Section.Iteration)
+			}
+		}
+		assert.Equal(t, []int{2}, codexIter)
+
+		var headMoved bool
+		for _, c := range log.PrintCalls() {
+			if strings.Contains(c.Format, "HEAD moved") {
+				headMoved = true
+			}
+		}
+		assert.True(t, headMoved, "should warn about HEAD change since checkpoint")
+
+		_, err := os.Stat(statePath)
+		assert.True(t, os.IsNotExist(err), "state removed after successful run")
+	})
+
+	t.Run("continues task phase from saved iteration", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		planFile := filepath.Join(tmpDir, "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+		statePath := filepath.Join(tmpDir, "progress.state.json")
+		st := processor.RunState{Mode: processor.ModeFull, Pipeline: fullPipeline, Step: 0, Iteration: 7}
+		require.NoError(t, st.Save(statePath))
+
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{
+			{Output: "task done", Signal: processor.SignalCompleted},
+			{Output: "review done", Signal: processor.SignalReviewDone},
+			{Output: "review done", Signal: processor.SignalReviewDone},
+			{Output: "review done", Signal: processor.SignalReviewDone},
+		})
+		codex := newMockExecutor(nil)
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50,
+			IterationDelayMs: 1, StatePath: statePath, Resume: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, codex)
+		require.NoError(t, r.Run(context.Background()))
+
+		sections := log.PrintSectionCalls()
+		require.NotEmpty(t, sections)
+		assert.Equal(t, processor.SectionTaskIteration, sections[0].Section.Type)
+		assert.Equal(t, 7, sections[0].Section.Iteration)
+	})
+
+	t.Run("pipeline mismatch fails", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), "progress.state.json")
+		st := processor.RunState{Mode: processor.ModeFull, Pipeline: fullPipeline, Step: 0, Iteration: 1}
+		require.NoError(t, st.Save(statePath))
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, StatePath: statePath,
+			Resume: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), newMockExecutor(nil), newMockExecutor(nil))
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "saved run state is for pipeline")
+	})
+
+	t.Run("step out of range fails", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), "progress.state.json")
+		st := processor.RunState{Mode: processor.ModeCodexOnly, Pipeline: "codex, review_loop", Step: 5, Iteration: 1}
+		require.NoError(t, st.Save(statePath))
+
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, StatePath: statePath,
+			Resume: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger(""), newMockExecutor(nil), newMockExecutor(nil))
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "out of pipeline range")
+	})
+
+	t.Run("missing state starts from beginning", func(t *testing.T) {
+		statePath := filepath.Join(t.TempDir(), "progress.state.json")
+		log := newMockLogger("progress.txt")
+		claude := newMockExecutor([]executor.Result{{Output: "review done", Signal: processor.SignalReviewDone}})
+		cfg := processor.Config{Mode: processor.ModeCodexOnly, MaxIterations: 50, IterationDelayMs: 1,
+			StatePath: statePath, Resume: true, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		require.NoError(t, r.Run(context.Background()))
+
+		var found bool
+		for _, c := range log.PrintCalls() {
+			if strings.Contains(c.Format, "no saved run state") {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+}
+
+func TestRunner_Checkpoint(t *testing.T) {
+	const fullPipeline = "tasks, review_first, review_loop, codex, review_loop"
+
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] Task 1"), 0o600))
+	statePath := filepath.Join(tmpDir, "progress.state.json")
+
+	log := newMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: processor.SignalCompleted},
+		{Output: "review done", Signal: processor.SignalReviewDone},
+		{Output: "review done", Signal: processor.SignalReviewDone},
+		{Output: "fixed, still arguing"}, // codex evaluation, iteration 1
+		{Error: errors.New("connection lost")},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "issue 1"}, {Output: "issue 2"}})
+
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 50, CodexEnabled: true,
+		IterationDelayMs: 1, StatePath: statePath, AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, log, claude, codex)
+	r.SetGitRepo(&mocks.GitRepoMock{HeadHashFunc: func() (string, error) { return "abc123", nil },
+		BaseRefFunc: func(string) string { return "master" }})
+	err := r.Run(context.Background())
+	require.Error(t, err)
+
+	st, err := processor.LoadState(statePath)
+	require.NoError(t, err)
+	require.NotNil(t, st)
+	assert.Equal(t, fullPipeline, st.Pipeline)
+	assert.Equal(t, 3, st.Step)
+	assert.Equal(t, "codex", st.Phase)
+	assert.Equal(t, 2, st.Iteration)
+	assert.Equal(t, "fixed, still arguing", st.ClaudeResponse)
+	assert.Equal(t, "abc123", st.ReviewedCommit)
+	assert.Equal(t, planFile, st.PlanFile)
+	assert.Equal(t, processor.ModeFull, st.Mode)
+}
@@ -17,11 +17,15 @@ const (
 	SignalCodexDone  = "<<<RALPHEX:CODEX_REVIEW_DONE>>>"
 	SignalQuestion   = "<<<RALPHEX:QUESTION>>>"
 	SignalPlanReady  = "<<<RALPHEX:PLAN_READY>>>"
+	SignalBlocked    = "<<<RALPHEX:TASK_BLOCKED>>>"
 )
 
 // questionSignalRe matches the QUESTION signal block with JSON payload
 var questionSignalRe = regexp.MustCompile(`<<<RALPHEX:QUESTION>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
 
+// blockedSignalRe matches the TASK_BLOCKED signal block with JSON payload
+var blockedSignalRe = regexp.MustCompile(`<<<RALPHEX:TASK_BLOCKED>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
+
 // QuestionPayload represents a question signal from Claude during plan creation
 type QuestionPayload struct {
 	Question string   `json:"question"`
@@ -87,3 +91,36 @@ func ParseQuestionPayload(output string) (*QuestionPayload, error) {
 
 	return &payload, nil
 }
+
+// BlockedPayload represents a task blocked by something claude can't resolve, e.g. a missing credential.
+type BlockedPayload struct {
+	Task   int    `json:"task,omitempty"` // task number, the current task if not set
+	Reason string `json:"reason"`
+}
+
+// ErrNoBlockedSignal indicates no task blocked signal was found in output
+var ErrNoBlockedSignal = errors.New("no task blocked signal found")
+
+// ParseBlockedPayload extracts a BlockedPayload from output containing TASK_BLOCKED signal.
+// returns ErrNoBlockedSignal if no blocked signal is found.
+// returns other error if signal is found but JSON is malformed.
+func ParseBlockedPayload(output string) (*BlockedPayload, error) {
+	if !strings.Contains(output, SignalBlocked) {
+		return nil, ErrNoBlockedSignal
+	}
+
+	matches := blockedSignalRe.FindStringSubmatch(output)
+	if len(matches) < 2 || strings.TrimSpace(matches[1]) == "" {
+		return nil, errors.New("malformed task blocked signal: missing END marker or empty payload")
+	}
+
+	var payload BlockedPayload
+	if err := json.Unmarshal([]byte(strings.TrimSpace(matches[1])), &payload); err != nil {
+		return nil, fmt.Errorf("malformed task blocked signal: invalid JSON: %w", err)
+	}
+	payload.Reason = strings.Join(strings.Fields(payload.Reason), " ")
+	if payload.Reason == "" {
+		return nil, errors.New("malformed task blocked signal: missing reason field")
+	}
+	return &payload, nil
+}
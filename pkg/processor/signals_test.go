@@ -268,3 +268,39 @@ func TestParseQuestionPayload_NoSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestParseBlockedPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected *BlockedPayload
+		err      string
+	}{
+		{name: "no signal", output: "task done", err: ErrNoBlockedSignal.Error()},
+		{
+			name:     "reason and task",
+			output:   "can't go on\n<<<RALPHEX:TASK_BLOCKED>>>\n{\"task\": 3, \"reason\": \"AWS credentials missing\"}\n<<<RALPHEX:END>>>",
+			expected: &BlockedPayload{Task: 3, Reason: "AWS credentials missing"},
+		},
+		{
+			name:     "multiline reason collapsed",
+			output:   "<<<RALPHEX:TASK_BLOCKED>>>{\"reason\": \"api v2\\nnot released\"}<<<RALPHEX:END>>>",
+			expected: &BlockedPayload{Reason: "api v2 not released"},
+		},
+		{name: "missing end", output: `<<<RALPHEX:TASK_BLOCKED>>> {"reason": "x"}`, err: "missing END marker"},
+		{name: "invalid json", output: "<<<RALPHEX:TASK_BLOCKED>>>{reason}<<<RALPHEX:END>>>", err: "invalid JSON"},
+		{name: "empty reason", output: `<<<RALPHEX:TASK_BLOCKED>>>{"task": 1}<<<RALPHEX:END>>>`, err: "missing reason"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := ParseBlockedPayload(tc.output)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, payload)
+		})
+	}
+}
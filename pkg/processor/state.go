@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RunState is a checkpoint of runner progress, persisted next to the progress log.
+// it is written at the start of every iteration and removed when the run completes,
+// so a leftover state file always points at the iteration that was interrupted.
+type RunState struct {
+	PlanFile       string    `json:"plan_file,omitempty"`
+	Mode           Mode      `json:"mode"`
+	Pipeline       string    `json:"pipeline"`                  // pipeline definition the checkpoint belongs to
+	Step           int       `json:"step"`                      // 0-based index of the interrupted pipeline step
+	Phase          string    `json:"phase"`                     // name of the interrupted step, informational
+	Iteration      int       `json:"iteration"`                 // 1-based iteration within the step
+	ClaudeResponse string    `json:"claude_response,omitempty"` // last claude response passed to buildCodexPrompt
+	ReviewedCommit string    `json:"reviewed_commit,omitempty"` // HEAD commit when the checkpoint was written
+	Skipped        []int     `json:"skipped,omitempty"`         // 0-based indexes of steps skipped at approval gates
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// StatePath returns the run state file path for the given progress file path.
+// e.g. "progress-feature.txt" -> "progress-feature.state.json".
+func StatePath(progressPath string) string {
+	if progressPath == "" {
+		return ""
+	}
+	return strings.TrimSuffix(progressPath, filepath.Ext(progressPath)) + ".state.json"
+}
+
+// LoadState reads a run state file. returns nil state (not error) if the file doesn't exist.
+func LoadState(path string) (*RunState, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path derived from progress filename
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+
+	var st RunState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse state file %s: %w", path, err)
+	}
+	if st.Step < 0 {
+		return nil, fmt.Errorf("invalid step %d in state file %s", st.Step, path)
+	}
+	if st.Iteration < 1 {
+		st.Iteration = 1
+	}
+	return &st, nil
+}
+
+// Save writes the run state to path atomically (temp file + rename).
+func (s *RunState) Save(path string) error {
+	s.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename state file: %w", err)
+	}
+	return nil
+}
+
+// RemoveState deletes the run state file. missing file is not an error.
+func RemoveState(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove state file: %w", err)
+	}
+	return nil
+}
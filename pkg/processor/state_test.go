@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress string
+		want     string
+	}{
+		{name: "plan progress file", progress: "progress-feature.txt", want: "progress-feature.state.json"},
+		{name: "nested path", progress: "/tmp/x/progress.txt", want: "/tmp/x/progress.state.json"},
+		{name: "no extension", progress: "progress", want: "progress.state.json"},
+		{name: "empty", progress: "", want: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, StatePath(tc.progress))
+		})
+	}
+}
+
+func TestRunState_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.state.json")
+
+	st := RunState{
+		PlanFile:       "docs/plans/feature.md",
+		Mode:           ModeFull,
+		Pipeline:       "tasks, codex",
+		Step:           1,
+		Phase:          "codex",
+		Iteration:      3,
+		ClaudeResponse: "fixed foo.go",
+		ReviewedCommit: "abc123",
+	}
+	require.NoError(t, st.Save(path))
+	assert.False(t, st.UpdatedAt.IsZero())
+
+	_, err := os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err), "temp file should be renamed")
+
+	loaded, err := LoadState(path)
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, st.PlanFile, loaded.PlanFile)
+	assert.Equal(t, ModeFull, loaded.Mode)
+	assert.Equal(t, "tasks, codex", loaded.Pipeline)
+	assert.Equal(t, 1, loaded.Step)
+	assert.Equal(t, "codex", loaded.Phase)
+	assert.Equal(t, 3, loaded.Iteration)
+	assert.Equal(t, "fixed foo.go", loaded.ClaudeResponse)
+	assert.Equal(t, "abc123", loaded.ReviewedCommit)
+}
+
+func TestLoadState(t *testing.T) {
+	t.Run("missing file returns nil", func(t *testing.T) {
+		st, err := LoadState(filepath.Join(t.TempDir(), "nope.json"))
+		require.NoError(t, err)
+		assert.Nil(t, st)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		require.NoError(t, os.WriteFile(path, []byte("{bad"), 0o600))
+		_, err := LoadState(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse state file")
+	})
+
+	t.Run("negative step", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"mode":"full","step":-1,"iteration":1}`), 0o600))
+		_, err := LoadState(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid step")
+	})
+
+	t.Run("zero iteration normalized", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"mode":"full","step":0}`), 0o600))
+		st, err := LoadState(path)
+		require.NoError(t, err)
+		assert.Equal(t, 1, st.Iteration)
+	})
+}
+
+func TestRemoveState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o600))
+	require.NoError(t, RemoveState(path))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// removing again is not an error
+	require.NoError(t, RemoveState(path))
+}
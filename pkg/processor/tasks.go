@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"slices"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// planTask is a task section of a plan file with its dependencies and completion state.
+// a blocked task has its open checkboxes marked "[~]" (or "[-]"), it counts as done for scheduling,
+// so execution continues with the next task, but is reported as blocked at the end of the run.
+type planTask struct {
+	Number        int
+	Title         string
+	Depends       []int  // numbers of tasks that must be completed first
+	Done          bool   // no uncompleted checkboxes in the section
+	Blocked       bool   // section has checkboxes marked as blocked
+	BlockedReason string // reason from the blocked note, if any
+}
+
+// parsePlanTasks extracts task sections of a parsed plan.
+// a task without a "(depends: ...)" annotation depends on the previous task, so plans without
+// annotations keep sequential order. "(depends: none)" declares a task without dependencies.
+func parsePlanTasks(doc *plan.Document) ([]planTask, error) {
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+
+	var tasks []planTask
+	for i, t := range doc.Tasks {
+		deps, _ := t.Dependencies() // validated above
+		if t.Depends == "" && i > 0 {
+			deps = []int{doc.Tasks[i-1].Number}
+		}
+		tasks = append(tasks, planTask{Number: t.Number, Title: t.Title, Depends: deps, Done: t.Done(),
+			Blocked: t.Blocked(), BlockedReason: t.BlockedReason()})
+	}
+	return tasks, nil
+}
+
+// readyTasks returns incomplete tasks with all dependencies completed, skipping excluded task numbers.
+func readyTasks(tasks []planTask, exclude map[int]bool) []planTask {
+	done := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		done[t.Number] = t.Done
+	}
+
+	var ready []planTask
+	for _, t := range tasks {
+		if t.Done || exclude[t.Number] {
+			continue
+		}
+		if !slices.ContainsFunc(t.Depends, func(dep int) bool { return !done[dep] }) {
+			ready = append(ready, t)
+		}
+	}
+	return ready
+}
+
+// allTasksDone returns true if every task section is completed.
+func allTasksDone(tasks []planTask) bool {
+	return !slices.ContainsFunc(tasks, func(t planTask) bool { return !t.Done })
+}
+
+// findTask returns the task with the given number.
+func findTask(tasks []planTask, number int) (planTask, bool) {
+	idx := slices.IndexFunc(tasks, func(t planTask) bool { return t.Number == number })
+	if idx < 0 {
+		return planTask{}, false
+	}
+	return tasks[idx], true
+}
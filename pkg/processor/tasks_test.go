@@ -0,0 +1,151 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+func TestParsePlanTasks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []planTask
+		wantErr string
+	}{
+		{
+			name: "implicit sequential dependencies",
+			content: `# Plan
+### Task 1: First
+- [x] done item
+### Task 2: Second
+- [ ] open item
+### Iteration 3: Third
+- [ ] open item`,
+			want: []planTask{
+				{Number: 1, Title: "First", Done: true},
+				{Number: 2, Title: "Second", Depends: []int{1}},
+				{Number: 3, Title: "Third", Depends: []int{2}},
+			},
+		},
+		{
+			name: "blocked task counts as done",
+			content: `### Task 1: Deploy
+
+> blocked: no cloud credentials
+- [x] write manifest
+- [~] apply manifest
+### Task 2: Docs
+- [-] publish docs`,
+			want: []planTask{
+				{Number: 1, Title: "Deploy", Done: true, Blocked: true, BlockedReason: "no cloud credentials"},
+				{Number: 2, Title: "Docs", Depends: []int{1}, Done: true, Blocked: true},
+			},
+		},
+		{
+			name: "explicit dependencies",
+			content: `### Task 1: Base
+- [ ] item
+### Task 2: API client (depends: 1)
+- [ ] item
+### Task 3: Docs (depends: none)
+- [ ] item
+### Task 4: Wire up (depends: 2, 3)
+- [ ] item`,
+			want: []planTask{
+				{Number: 1, Title: "Base"},
+				{Number: 2, Title: "API client", Depends: []int{1}},
+				{Number: 3, Title: "Docs"},
+				{Number: 4, Title: "Wire up", Depends: []int{2, 3}},
+			},
+		},
+		{
+			name: "checkboxes outside of task sections ignored",
+			content: `### Task 1: Only
+- [x] item
+
+## Success criteria
+- [ ] everything works`,
+			want: []planTask{{Number: 1, Title: "Only", Done: true}},
+		},
+		{
+			name:    "no tasks",
+			content: "# Plan\n- [ ] item",
+		},
+		{
+			name:    "unknown dependency",
+			content: "### Task 1: A\n### Task 2: B (depends: 5)",
+			wantErr: "task 2 depends on unknown task 5",
+		},
+		{
+			name:    "self dependency",
+			content: "### Task 1: A (depends: 1)",
+			wantErr: "task 1 depends on unknown task 1",
+		},
+		{
+			name:    "invalid dependency",
+			content: "### Task 1: A\n### Task 2: B (depends: first)",
+			wantErr: `task 2: invalid dependency "first"`,
+		},
+		{
+			name:    "duplicate task",
+			content: "### Task 1: A\n### Task 1: B",
+			wantErr: "duplicate task 1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parsePlanTasks(plan.Parse(tc.content))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReadyTasks(t *testing.T) {
+	tasks := []planTask{
+		{Number: 1, Done: true},
+		{Number: 2, Depends: []int{1}},
+		{Number: 3, Depends: []int{1}},
+		{Number: 4, Depends: []int{2, 3}},
+		{Number: 5},
+	}
+
+	numbers := func(tt []planTask) []int {
+		var res []int
+		for _, t := range tt {
+			res = append(res, t.Number)
+		}
+		return res
+	}
+
+	assert.Equal(t, []int{2, 3, 5}, numbers(readyTasks(tasks, nil)))
+	assert.Equal(t, []int{3, 5}, numbers(readyTasks(tasks, map[int]bool{2: true})))
+
+	tasks[1].Done, tasks[2].Done = true, true
+	assert.Equal(t, []int{4, 5}, numbers(readyTasks(tasks, nil)))
+	assert.False(t, allTasksDone(tasks))
+
+	tasks[3].Done, tasks[4].Done = true, true
+	assert.Empty(t, readyTasks(tasks, nil))
+	assert.True(t, allTasksDone(tasks))
+}
+
+func TestFindTask(t *testing.T) {
+	tasks := []planTask{{Number: 1, Title: "a"}, {Number: 3, Title: "c"}}
+	task, ok := findTask(tasks, 3)
+	require.True(t, ok)
+	assert.Equal(t, "c", task.Title)
+
+	_, ok = findTask(tasks, 2)
+	assert.False(t, ok)
+}
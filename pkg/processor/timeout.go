@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// ErrDeadlineReached is returned when the run is stopped by the configured deadline.
+var ErrDeadlineReached = errors.New("deadline reached")
+
+// stallRetryExecutor runs the executor again when a run was killed by iteration or idle timeout.
+// retries don't count as iterations, the number of retries is limited by task_retry_count.
+type stallRetryExecutor struct {
+	inner Executor
+	r     *Runner
+}
+
+// Run executes the wrapped executor, retrying runs killed by a timeout.
+func (e *stallRetryExecutor) Run(ctx context.Context, prompt string) executor.Result {
+	for attempt := 0; ; attempt++ {
+		result := e.inner.Run(ctx, prompt)
+		stalled := errors.Is(result.Error, executor.ErrIdleTimeout) || errors.Is(result.Error, executor.ErrIterationTimeout)
+		if !stalled || ctx.Err() != nil || attempt >= e.r.taskRetryCount {
+			return result
+		}
+		e.r.log.Print("warning: %v, retrying (%d/%d)...", result.Error, attempt+1, e.r.taskRetryCount)
+	}
+}
+
+// runPipelineUntilDeadline runs the pipeline and stops it when the deadline arrives.
+// the iteration running at that moment is killed, its checkpoint stays in the run state for --resume.
+func (r *Runner) runPipelineUntilDeadline(ctx context.Context) error {
+	if r.cfg.Deadline.IsZero() {
+		return r.runPipeline(ctx)
+	}
+
+	ctx, cancel := context.WithDeadlineCause(ctx, r.cfg.Deadline, ErrDeadlineReached)
+	defer cancel()
+
+	err := r.runPipeline(ctx)
+	if err != nil && errors.Is(context.Cause(ctx), ErrDeadlineReached) {
+		r.log.Print("deadline %s reached, stopping the run", r.cfg.Deadline.Format(time.DateTime))
+		return fmt.Errorf("%w at %s", ErrDeadlineReached, r.cfg.Deadline.Format(time.DateTime))
+	}
+	return err
+}
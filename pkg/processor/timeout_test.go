@@ -0,0 +1,82 @@
+package processor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+)
+
+func TestRunner_StallRetry(t *testing.T) {
+	pipeline, err := processor.ParsePipeline("tasks")
+	require.NoError(t, err)
+	stalled := executor.Result{Output: "partial", Error: fmt.Errorf("claude killed: %w after 30m0s", executor.ErrIdleTimeout)}
+
+	t.Run("retried without counting an iteration", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [x] done"), 0o600))
+		claude := newMockExecutor([]executor.Result{stalled, {Output: "done", Signal: processor.SignalCompleted}})
+		log := newMockLogger("progress.txt")
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 1, IterationDelayMs: 1,
+			TaskRetryCount: 1, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, log, claude, newMockExecutor(nil))
+		require.NoError(t, r.Run(context.Background()))
+		assert.Len(t, claude.RunCalls(), 2)
+		assert.Len(t, log.PrintSectionCalls(), 1, "retry stays in the same iteration")
+	})
+
+	t.Run("fails after retries exhausted", func(t *testing.T) {
+		planFile := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] todo"), 0o600))
+		claude := newMockExecutor([]executor.Result{stalled, stalled, stalled})
+
+		cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+			TaskRetryCount: 1, Pipeline: pipeline, AppConfig: testAppConfig(t)}
+		r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+		err := r.Run(context.Background())
+		require.Error(t, err)
+		require.ErrorIs(t, err, executor.ErrIdleTimeout)
+		assert.Len(t, claude.RunCalls(), 2)
+	})
+}
+
+func TestRunner_Deadline(t *testing.T) {
+	pipeline, err := processor.ParsePipeline("tasks")
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n- [ ] todo"), 0o600))
+	statePath := filepath.Join(tmpDir, "progress.state.json")
+
+	// claude hangs until the run context is canceled
+	claude := &mocks.ExecutorMock{RunFunc: func(ctx context.Context, _ string) executor.Result {
+		<-ctx.Done()
+		return executor.Result{Error: ctx.Err()}
+	}}
+
+	cfg := processor.Config{Mode: processor.ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1,
+		Pipeline: pipeline, StatePath: statePath, Deadline: time.Now().Add(100 * time.Millisecond), AppConfig: testAppConfig(t)}
+	r := processor.NewWithExecutors(cfg, newMockLogger("progress.txt"), claude, newMockExecutor(nil))
+
+	start := time.Now()
+	err = r.Run(context.Background())
+	require.Error(t, err)
+	require.ErrorIs(t, err, processor.ErrDeadlineReached)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Len(t, claude.RunCalls(), 1)
+
+	state, err := processor.LoadState(statePath)
+	require.NoError(t, err, "run state is kept for --resume")
+	assert.Equal(t, 1, state.Iteration)
+}
@@ -0,0 +1,159 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+// ErrBudgetExceeded is returned when accumulated usage reaches max_cost_usd or max_tokens.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// usageTaskKey is the context key for the plan task an executor call works on.
+type usageTaskKey struct{}
+
+// withUsageTask returns a context that attributes executor usage to the plan task.
+func withUsageTask(ctx context.Context, task int) context.Context {
+	return context.WithValue(ctx, usageTaskKey{}, task)
+}
+
+// usageTask returns the plan task set by withUsageTask, 0 if not set.
+func usageTask(ctx context.Context) int {
+	task, _ := ctx.Value(usageTaskKey{}).(int)
+	return task
+}
+
+// usageEntry is usage accumulated for a phase or a task.
+type usageEntry struct {
+	name  string
+	usage executor.Usage
+}
+
+// usageTracker accumulates executor usage in total, per phase and per task.
+// safe for concurrent use by parallel task workers.
+type usageTracker struct {
+	maxCostUSD float64 // 0 is unlimited
+	maxTokens  int     // 0 is unlimited
+
+	mu     sync.Mutex
+	total  executor.Usage
+	phases []usageEntry // in order of first use
+	tasks  []usageEntry // in order of first use
+}
+
+// add records usage for the phase and the task, task 0 means usage outside of plan tasks.
+func (t *usageTracker) add(phase string, task int, u executor.Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = t.total.Add(u)
+	t.phases = addUsageEntry(t.phases, phase, u)
+	if task > 0 {
+		t.tasks = addUsageEntry(t.tasks, fmt.Sprintf("task %d", task), u)
+	}
+}
+
+// addUsageEntry adds usage to the named entry, appending a new entry if needed.
+func addUsageEntry(entries []usageEntry, name string, u executor.Usage) []usageEntry {
+	for i := range entries {
+		if entries[i].name == name {
+			entries[i].usage = entries[i].usage.Add(u)
+			return entries
+		}
+	}
+	return append(entries, usageEntry{name: name, usage: u})
+}
+
+// exceeded returns ErrBudgetExceeded wrapped with details if a budget limit is reached.
+func (t *usageTracker) exceeded() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.maxCostUSD > 0 && t.total.CostUSD >= t.maxCostUSD {
+		return fmt.Errorf("%w: cost $%.4f reached max_cost_usd $%.2f", ErrBudgetExceeded, t.total.CostUSD, t.maxCostUSD)
+	}
+	if t.maxTokens > 0 && t.total.Tokens() >= t.maxTokens {
+		return fmt.Errorf("%w: %d tokens reached max_tokens %d", ErrBudgetExceeded, t.total.Tokens(), t.maxTokens)
+	}
+	return nil
+}
+
+// summary returns total usage and copies of per-phase and per-task entries.
+func (t *usageTracker) summary() (total executor.Usage, phases, tasks []usageEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total, append([]usageEntry(nil), t.phases...), append([]usageEntry(nil), t.tasks...)
+}
+
+// meteredExecutor records usage of every call and refuses new calls once the budget is exceeded,
+// so the run stops between iterations instead of killing work in progress.
+type meteredExecutor struct {
+	inner Executor
+	r     *Runner
+}
+
+// Run executes the wrapped executor and records its usage for the current phase and task.
+// the session id of the call is logged as well, it allows to open the session manually.
+func (e *meteredExecutor) Run(ctx context.Context, prompt string) executor.Result {
+	if err := e.r.usage.exceeded(); err != nil {
+		return executor.Result{Error: err}
+	}
+	result := e.inner.Run(ctx, prompt)
+	if !result.Usage.IsZero() {
+		e.r.usage.add(e.r.currentPhase(), usageTask(ctx), result.Usage)
+		e.r.log.Print("usage: %s", result.Usage)
+	}
+	if result.SessionID != "" {
+		e.r.log.Print("session: %s", result.SessionID)
+	}
+	return result
+}
+
+// Usage returns the total usage of all executor calls made by the runner.
+func (r *Runner) Usage() executor.Usage {
+	total, _, _ := r.usage.summary()
+	return total
+}
+
+// currentPhase returns the name of the phase being executed, usage and hooks are attributed to it.
+func (r *Runner) currentPhase() string {
+	if r.cfg.Mode == ModePlan {
+		return "plan"
+	}
+	if r.step < len(r.pipeline) {
+		return r.pipeline[r.step].String()
+	}
+	return "unknown"
+}
+
+// currentTask returns the number of the first incomplete plan task, the one a sequential
+// task iteration works on. returns 0 if the plan has no task sections.
+func (r *Runner) currentTask() int {
+	tasks, err := r.readPlanTasks(r.cfg.PlanFile)
+	if err != nil {
+		return 0
+	}
+	for _, t := range tasks {
+		if !t.Done {
+			return t.Number
+		}
+	}
+	return 0
+}
+
+// logUsageSummary writes usage per phase and per task to the progress log.
+func (r *Runner) logUsageSummary() {
+	total, phases, tasks := r.usage.summary()
+	if total.IsZero() {
+		return
+	}
+	r.log.PrintSection(NewGenericSection("usage summary"))
+	for _, p := range phases {
+		r.log.Print("phase %s: %s", p.name, p.usage)
+	}
+	for _, t := range tasks {
+		r.log.Print("%s: %s", t.name, t.usage)
+	}
+	r.log.Print("total: %s", total)
+}
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+func TestUsageTracker(t *testing.T) {
+	tr := &usageTracker{}
+	tr.add("tasks", 1, executor.Usage{InputTokens: 10, CostUSD: 0.1})
+	tr.add("tasks", 2, executor.Usage{InputTokens: 20, CostUSD: 0.2})
+	tr.add("tasks", 1, executor.Usage{OutputTokens: 5})
+	tr.add("codex", 0, executor.Usage{OtherTokens: 100})
+
+	total, phases, tasks := tr.summary()
+	assert.Equal(t, 135, total.Tokens())
+	assert.InDelta(t, 0.3, total.CostUSD, 1e-9)
+
+	require.Len(t, phases, 2)
+	assert.Equal(t, "tasks", phases[0].name)
+	assert.Equal(t, 35, phases[0].usage.Tokens())
+	assert.Equal(t, "codex", phases[1].name)
+	assert.Equal(t, 100, phases[1].usage.Tokens())
+
+	require.Len(t, tasks, 2, "usage outside of tasks is not attributed to a task")
+	assert.Equal(t, usageEntry{name: "task 1", usage: executor.Usage{InputTokens: 10, OutputTokens: 5, CostUSD: 0.1}}, tasks[0])
+	assert.Equal(t, "task 2", tasks[1].name)
+}
+
+func TestUsageTracker_Exceeded(t *testing.T) {
+	tests := []struct {
+		name    string
+		tracker *usageTracker
+		usage   executor.Usage
+		wantErr string
+	}{
+		{name: "no limits", tracker: &usageTracker{}, usage: executor.Usage{InputTokens: 1e6, CostUSD: 100}},
+		{name: "under cost limit", tracker: &usageTracker{maxCostUSD: 1}, usage: executor.Usage{CostUSD: 0.99}},
+		{name: "cost limit reached", tracker: &usageTracker{maxCostUSD: 1}, usage: executor.Usage{CostUSD: 1},
+			wantErr: "cost $1.0000 reached max_cost_usd $1.00"},
+		{name: "under token limit", tracker: &usageTracker{maxTokens: 100}, usage: executor.Usage{InputTokens: 99}},
+		{name: "token limit reached", tracker: &usageTracker{maxTokens: 100}, usage: executor.Usage{InputTokens: 50, OtherTokens: 60},
+			wantErr: "110 tokens reached max_tokens 100"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.tracker.add("tasks", 0, tc.usage)
+			err := tc.tracker.exceeded()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrBudgetExceeded)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestUsageTask(t *testing.T) {
+	assert.Equal(t, 0, usageTask(context.Background()))
+	assert.Equal(t, 3, usageTask(withUsageTask(context.Background(), 3)))
+}
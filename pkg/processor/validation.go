@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+const (
+	// DefaultValidationTimeout is the default timeout for a single validation command.
+	DefaultValidationTimeout = 10 * time.Minute
+	// DefaultValidationRetries is the default number of task re-runs to fix failed validation.
+	DefaultValidationRetries = 3
+
+	// maxValidationOutput limits failure output injected into the task prompt, the tail is kept
+	maxValidationOutput = 5000
+)
+
+// runValidation executes validation commands of the plan at planFile in dir, stopping at the first failure.
+// command output goes to the progress log. returns the failure report for the task prompt,
+// empty if all commands passed or the plan has no validation commands.
+func (r *Runner) runValidation(ctx context.Context, dir, planFile string) (string, error) {
+	if planFile == "" {
+		return "", nil
+	}
+	doc, err := r.planGrammar().Load(planFile)
+	if err != nil {
+		return "", err
+	}
+
+	for _, command := range doc.ValidationCommands() {
+		r.log.Print("validation: %s", command)
+		output, runErr := r.runValidationCommand(ctx, dir, command)
+		if output = strings.TrimRight(output, "\n"); output != "" {
+			r.log.PrintAligned(output)
+		}
+		if runErr == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("validation: %w", ctx.Err())
+		}
+		r.log.Print("validation failed: %s: %v", command, runErr)
+		return fmt.Sprintf("command: %s\nerror: %v\noutput:\n%s", command, runErr, truncateOutput(output)), nil
+	}
+	return "", nil
+}
+
+// runValidationCommand runs a single validation command with the configured timeout.
+func (r *Runner) runValidationCommand(ctx context.Context, dir, command string) (string, error) {
+	if r.validationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.validationTimeout)
+		defer cancel()
+	}
+	output, err := executor.RunShell(ctx, dir, command, nil)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return output, fmt.Errorf("timed out after %s", r.validationTimeout)
+	}
+	return output, err
+}
+
+// truncateOutput keeps the tail of long command output, where failures are usually reported.
+func truncateOutput(output string) string {
+	if len(output) <= maxValidationOutput {
+		return output
+	}
+	return "...(truncated)\n" + output[len(output)-maxValidationOutput:]
+}
@@ -0,0 +1,18 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	assert.Equal(t, "short", truncateOutput("short"))
+
+	long := strings.Repeat("a", maxValidationOutput) + "tail"
+	got := truncateOutput(long)
+	assert.True(t, strings.HasPrefix(got, "...(truncated)\n"))
+	assert.True(t, strings.HasSuffix(got, "tail"))
+	assert.Len(t, got, len("...(truncated)\n")+maxValidationOutput)
+}
@@ -138,7 +138,9 @@ type Config struct {
 	PlanDescription string // plan description for plan mode (used for filename)
 	Mode            string // execution mode: full, review, codex-only, plan
 	Branch          string // current git branch
+	Settings        string // effective run settings written to the header, empty omits them
 	NoColor         bool   // disable color output (sets color.NoColor globally)
+	Append          bool   // append to existing progress file instead of truncating it (resumed runs)
 }
 
 // NewLogger creates a logger writing to both a progress file and stdout.
@@ -149,7 +151,7 @@ func NewLogger(cfg Config, colors *Colors) (*Logger, error) {
 		color.NoColor = true
 	}
 
-	progressPath := progressFilename(cfg.PlanFile, cfg.PlanDescription, cfg.Mode)
+	progressPath := ProgressPath(cfg)
 
 	// ensure progress files are tracked by creating parent dir
 	if dir := filepath.Dir(progressPath); dir != "." {
@@ -158,7 +160,11 @@ func NewLogger(cfg Config, colors *Colors) (*Logger, error) {
 		}
 	}
 
-	f, err := os.Create(progressPath) //nolint:gosec // path derived from plan filename
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if cfg.Append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(progressPath, flags, 0o644) //nolint:gosec // path derived from plan filename
 	if err != nil {
 		return nil, fmt.Errorf("create progress file: %w", err)
 	}
@@ -179,6 +185,16 @@ func NewLogger(cfg Config, colors *Colors) (*Logger, error) {
 		colors:    colors,
 	}
 
+	// resumed run appends a marker instead of a second header, keeping the original header parseable
+	if cfg.Append {
+		if info, statErr := f.Stat(); statErr == nil && info.Size() > 0 {
+			l.writeFile("\n%s\n", strings.Repeat("-", 60))
+			l.writeFile("Resumed: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+			l.writeFile("%s\n\n", strings.Repeat("-", 60))
+			return l, nil
+		}
+	}
+
 	// write header
 	planStr := cfg.PlanFile
 	if planStr == "" {
@@ -188,6 +204,9 @@ func NewLogger(cfg Config, colors *Colors) (*Logger, error) {
 	l.writeFile("Plan: %s\n", planStr)
 	l.writeFile("Branch: %s\n", cfg.Branch)
 	l.writeFile("Mode: %s\n", cfg.Mode)
+	if cfg.Settings != "" {
+		l.writeFile("Settings: %s\n", cfg.Settings)
+	}
 	l.writeFile("Started: %s\n", time.Now().Format("2006-01-02 15:04:05"))
 	l.writeFile("%s\n\n", strings.Repeat("-", 60))
 
@@ -494,6 +513,11 @@ func (l *Logger) writeStdout(format string, args ...any) {
 	fmt.Fprintf(l.stdout, format, args...)
 }
 
+// ProgressPath returns the progress file path a logger created with cfg writes to.
+func ProgressPath(cfg Config) string {
+	return progressFilename(cfg.PlanFile, cfg.PlanDescription, cfg.Mode)
+}
+
 // getProgressFilename returns progress file path based on plan and mode.
 func progressFilename(planFile, planDescription, mode string) string {
 	// plan mode uses sanitized plan description
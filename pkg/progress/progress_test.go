@@ -45,6 +45,8 @@ func TestNewLogger(t *testing.T) {
 		{name: "full mode no plan", cfg: Config{Mode: "full", Branch: "main"}, wantPath: "progress.txt"},
 		{name: "review mode no plan", cfg: Config{Mode: "review", Branch: "main"}, wantPath: "progress-review.txt"},
 		{name: "codex-only mode no plan", cfg: Config{Mode: "codex-only", Branch: "main"}, wantPath: "progress-codex.txt"},
+		{name: "with settings", cfg: Config{PlanFile: "docs/plans/cache.md", Mode: "full", Branch: "main", Settings: "max_iterations=20"},
+			wantPath: "progress-cache.txt"},
 	}
 
 	for _, tc := range tests {
@@ -65,10 +67,57 @@ func TestNewLogger(t *testing.T) {
 			require.NoError(t, err)
 			assert.Contains(t, string(content), "# Ralphex Progress Log")
 			assert.Contains(t, string(content), "Mode: "+tc.cfg.Mode)
+			if tc.cfg.Settings != "" {
+				assert.Contains(t, string(content), "Mode: full\nSettings: max_iterations=20\nStarted: ")
+			} else {
+				assert.NotContains(t, string(content), "Settings:")
+			}
 		})
 	}
 }
 
+func TestNewLogger_Append(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	cfg := Config{PlanFile: "docs/plans/feature.md", Mode: "full", Branch: "feature", NoColor: true}
+
+	l, err := NewLogger(cfg, testColors())
+	require.NoError(t, err)
+	l.stdout = &bytes.Buffer{}
+	l.Print("first run output")
+	require.NoError(t, l.Close())
+
+	cfg.Append = true
+	l, err = NewLogger(cfg, testColors())
+	require.NoError(t, err)
+	l.stdout = &bytes.Buffer{}
+	l.Print("resumed run output")
+	require.NoError(t, l.Close())
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	s := string(content)
+	assert.Equal(t, 1, strings.Count(s, "# Ralphex Progress Log"), "header written once")
+	assert.Contains(t, s, "first run output")
+	assert.Contains(t, s, "Resumed: ")
+	assert.Contains(t, s, "resumed run output")
+	assert.Less(t, strings.Index(s, "first run output"), strings.Index(s, "resumed run output"))
+
+	t.Run("missing file gets full header", func(t *testing.T) {
+		require.NoError(t, os.Remove(l.Path()))
+		l, err := NewLogger(cfg, testColors())
+		require.NoError(t, err)
+		defer l.Close()
+		content, err := os.ReadFile(l.Path())
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "# Ralphex Progress Log")
+		assert.NotContains(t, string(content), "Resumed: ")
+	})
+}
+
 func TestLogger_Print(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -347,6 +396,7 @@ func TestGetProgressFilename(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			got := progressFilename(tc.planFile, tc.planDescription, tc.mode)
 			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.want, ProgressPath(Config{PlanFile: tc.planFile, PlanDescription: tc.planDescription, Mode: tc.mode}))
 		})
 	}
 }
@@ -0,0 +1,70 @@
+package runfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ApprovalDecision is the decision taken at a phase approval gate.
+type ApprovalDecision string
+
+// ApprovalDecision constants.
+const (
+	ApprovalApprove ApprovalDecision = "approve" // continue with the next phase
+	ApprovalSkip    ApprovalDecision = "skip"    // skip the next phase and continue with the one after it
+	ApprovalAbort   ApprovalDecision = "abort"   // stop the run, --resume continues with the next phase
+)
+
+// Approval is a pending phase approval. it is saved to the approval file while the run waits,
+// the dashboard shows it and answers by setting Decision.
+type Approval struct {
+	Phase     string           `json:"phase"`      // completed phase
+	NextPhase string           `json:"next_phase"` // phase started after approval
+	Commits   []string         `json:"commits"`    // commits made by the completed phase, newest first
+	Files     []string         `json:"files"`      // files changed by the completed phase, including uncommitted changes
+	DiffStat  string           `json:"diffstat"`
+	Requested time.Time        `json:"requested"`
+	Decision  ApprovalDecision `json:"decision,omitempty"`
+}
+
+// ApprovalPath returns the approval file path for the given progress file path.
+// e.g. "progress-feature.txt" -> "progress-feature.approval.json".
+func ApprovalPath(progressPath string) string {
+	return sidePath(progressPath, ".approval.json")
+}
+
+// ParseApprovalDecision validates a decision name.
+func ParseApprovalDecision(s string) (ApprovalDecision, error) {
+	switch d := ApprovalDecision(strings.ToLower(strings.TrimSpace(s))); d {
+	case ApprovalApprove, ApprovalSkip, ApprovalAbort:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown approval decision %q (valid: approve, skip, abort)", s)
+	}
+}
+
+// LoadApproval reads the approval file. returns nil (not error) if there is no pending approval.
+func LoadApproval(path string) (*Approval, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path derived from progress filename
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read approval: %w", err)
+	}
+
+	var a Approval
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("parse approval %s: %w", path, err)
+	}
+	return &a, nil
+}
+
+// Save writes the approval to path atomically (temp file + rename).
+func (a *Approval) Save(path string) error {
+	return saveJSON(path, "approval", a)
+}
@@ -0,0 +1,42 @@
+package runfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApprovalPath(t *testing.T) {
+	assert.Equal(t, "progress-feature.approval.json", ApprovalPath("progress-feature.txt"))
+	assert.Empty(t, ApprovalPath(""))
+}
+
+func TestParseApprovalDecision(t *testing.T) {
+	for _, s := range []string{"approve", "Skip", " abort "} {
+		_, err := ParseApprovalDecision(s)
+		require.NoError(t, err, s)
+	}
+	_, err := ParseApprovalDecision("later")
+	require.EqualError(t, err, `unknown approval decision "later" (valid: approve, skip, abort)`)
+}
+
+func TestApproval_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.approval.json")
+	a, err := LoadApproval(path)
+	require.NoError(t, err)
+	assert.Nil(t, a, "no pending approval")
+
+	pending := Approval{Phase: "tasks", NextPhase: "review_first", Files: []string{"a.go"}, DiffStat: "1 file changed"}
+	require.NoError(t, pending.Save(path))
+	a, err = LoadApproval(path)
+	require.NoError(t, err)
+	assert.Equal(t, pending.Files, a.Files)
+	assert.Equal(t, "review_first", a.NextPhase)
+
+	require.NoError(t, os.WriteFile(path, []byte("{bad"), 0o600))
+	_, err = LoadApproval(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package runfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Severity values of review findings.
+const (
+	SeverityCritical = "critical"
+	SeverityMajor    = "major"
+	SeverityMinor    = "minor"
+	SeverityInfo     = "info"
+)
+
+// Status values of review findings, what the reviewer did about the finding.
+const (
+	FindingFixed    = "fixed"
+	FindingRejected = "rejected"
+	FindingDeferred = "deferred"
+)
+
+// Finding is a single review finding.
+type Finding struct {
+	File        string `json:"file,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Severity    string `json:"severity"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+}
+
+// ReviewIteration holds findings reported by a single review iteration.
+type ReviewIteration struct {
+	Phase     string    `json:"phase"`
+	Iteration int       `json:"iteration"`
+	Time      time.Time `json:"time"`
+	Findings  []Finding `json:"findings"`
+}
+
+// ReviewReport holds findings of all review iterations of a run, persisted next to the progress log.
+type ReviewReport struct {
+	Iterations []ReviewIteration `json:"iterations"`
+}
+
+// ReportPath returns the review report file path for the given progress file path.
+// e.g. "progress-feature.txt" -> "progress-feature.review-report.json".
+func ReportPath(progressPath string) string {
+	return sidePath(progressPath, ".review-report.json")
+}
+
+// LoadReport reads a review report file. returns empty report (not error) if the file doesn't exist.
+func LoadReport(path string) (*ReviewReport, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path derived from progress filename
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &ReviewReport{}, nil
+		}
+		return nil, fmt.Errorf("read review report: %w", err)
+	}
+
+	var report ReviewReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse review report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// Save writes the review report to path atomically (temp file + rename).
+func (rr *ReviewReport) Save(path string) error {
+	return saveJSON(path, "review report", rr)
+}
@@ -0,0 +1,36 @@
+package runfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPath(t *testing.T) {
+	assert.Equal(t, "progress-feature.review-report.json", ReportPath("progress-feature.txt"))
+	assert.Equal(t, "/tmp/x/progress.review-report.json", ReportPath("/tmp/x/progress.txt"))
+	assert.Empty(t, ReportPath(""))
+}
+
+func TestReviewReport_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.review-report.json")
+
+	report, err := LoadReport(path)
+	require.NoError(t, err, "missing report is not an error")
+	assert.Empty(t, report.Iterations)
+
+	report.Iterations = append(report.Iterations, ReviewIteration{Phase: "review", Iteration: 2,
+		Findings: []Finding{{File: "a.go", Line: 1, Severity: SeverityMajor, Description: "bug", Status: FindingFixed}}})
+	require.NoError(t, report.Save(path))
+
+	loaded, err := LoadReport(path)
+	require.NoError(t, err)
+	assert.Equal(t, report.Iterations, loaded.Iterations)
+
+	require.NoError(t, os.WriteFile(path, []byte("{bad"), 0o600))
+	_, err = LoadReport(path)
+	require.Error(t, err)
+}
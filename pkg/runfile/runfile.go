@@ -0,0 +1,39 @@
+// Package runfile defines files a run keeps next to its progress log for the dashboard:
+// the review findings report and the pending phase approval. the runner writes them,
+// the dashboard reads them and answers approvals.
+package runfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sidePath returns the path of a file kept next to the progress log, with the progress file extension
+// replaced by suffix. returns empty string for an empty progress path.
+func sidePath(progressPath, suffix string) string {
+	if progressPath == "" {
+		return ""
+	}
+	return strings.TrimSuffix(progressPath, filepath.Ext(progressPath)) + suffix
+}
+
+// saveJSON writes v to path as indented JSON atomically (temp file + rename).
+func saveJSON(path, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", name, err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename %s: %w", name, err)
+	}
+	return nil
+}
@@ -1,13 +1,10 @@
 package web
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
-	"os"
-	"regexp"
-	"strconv"
-	"strings"
+
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 // TaskStatus represents the execution status of a task.
@@ -19,12 +16,15 @@ const (
 	TaskStatusActive  TaskStatus = "active"
 	TaskStatusDone    TaskStatus = "done"
 	TaskStatusFailed  TaskStatus = "failed"
+	TaskStatusBlocked TaskStatus = "blocked" // task marked blocked, neither done nor pending
 )
 
 // Checkbox represents a single checkbox item in a task.
 type Checkbox struct {
 	Text    string `json:"text"`
 	Checked bool   `json:"checked"`
+	Blocked bool   `json:"blocked,omitempty"` // marked "[~]" or "[-]"
+	Level   int    `json:"level,omitempty"`   // nesting level, 0 for top-level checkboxes
 }
 
 // Task represents a task section in a plan.
@@ -33,6 +33,8 @@ type Task struct {
 	Title      string     `json:"title"`
 	Status     TaskStatus `json:"status"`
 	Checkboxes []Checkbox `json:"checkboxes"`
+	// BlockedReason is the reason from the "> blocked: ..." note of a blocked task.
+	BlockedReason string `json:"blockedReason,omitempty"`
 }
 
 // Plan represents a parsed plan file.
@@ -41,84 +43,39 @@ type Plan struct {
 	Tasks []Task `json:"tasks"`
 }
 
-// patterns for parsing plan markdown.
-var (
-	taskHeaderPattern = regexp.MustCompile(`^###\s+(?:Task|Iteration)\s+(\d+):\s*(.*)$`)
-	checkboxPattern   = regexp.MustCompile(`^-\s+\[([ xX])\]\s*(.*)$`)
-	titlePattern      = regexp.MustCompile(`^#\s+(.*)$`)
-)
-
-// ParsePlan parses a plan markdown file into a structured Plan.
-func ParsePlan(content string) (*Plan, error) {
-	plan := &Plan{
-		Tasks: make([]Task, 0),
-	}
-
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	var currentTask *Task
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// check for plan title (first h1)
-		if plan.Title == "" {
-			if matches := titlePattern.FindStringSubmatch(line); matches != nil {
-				plan.Title = strings.TrimSpace(matches[1])
-				continue
-			}
-		}
-
-		// check for task header
-		if matches := taskHeaderPattern.FindStringSubmatch(line); matches != nil {
-			// save previous task if exists
-			if currentTask != nil {
-				currentTask.Status = determineTaskStatus(currentTask.Checkboxes)
-				plan.Tasks = append(plan.Tasks, *currentTask)
-			}
-
-			taskNum, _ := parseTaskNum(matches[1])
-
-			currentTask = &Task{
-				Number:     taskNum,
-				Title:      strings.TrimSpace(matches[2]),
-				Status:     TaskStatusPending,
-				Checkboxes: make([]Checkbox, 0),
-			}
-			continue
-		}
+// ParsePlan parses a plan markdown file into a structured Plan, task headers and checkboxes
+// are recognized by the grammar, the same way the runner recognizes them.
+func ParsePlan(content string, g plan.Grammar) *Plan {
+	return newPlan(g.Parse(content))
+}
 
-		// check for checkbox (only if inside a task)
-		if currentTask != nil {
-			if matches := checkboxPattern.FindStringSubmatch(line); matches != nil {
-				checked := matches[1] == "x" || matches[1] == "X"
-				currentTask.Checkboxes = append(currentTask.Checkboxes, Checkbox{
-					Text:    strings.TrimSpace(matches[2]),
-					Checked: checked,
-				})
-			}
+// newPlan converts a plan document to the dashboard view of the plan.
+// nested checkboxes are listed after their parent with a higher level.
+func newPlan(doc *plan.Document) *Plan {
+	p := &Plan{Title: doc.Title, Tasks: make([]Task, 0, len(doc.Tasks))}
+	for _, t := range doc.Tasks {
+		task := Task{Number: t.Number, Title: t.Title, Checkboxes: make([]Checkbox, 0), BlockedReason: t.BlockedReason()}
+		for _, item := range t.Checkboxes() {
+			task.Checkboxes = append(task.Checkboxes, Checkbox{
+				Text:    item.Text,
+				Checked: item.State == plan.StateDone,
+				Blocked: item.State == plan.StateBlocked,
+				Level:   item.Level,
+			})
 		}
+		task.Status = determineTaskStatus(task.Checkboxes)
+		p.Tasks = append(p.Tasks, task)
 	}
-
-	// save last task
-	if currentTask != nil {
-		currentTask.Status = determineTaskStatus(currentTask.Checkboxes)
-		plan.Tasks = append(plan.Tasks, *currentTask)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan plan: %w", err)
-	}
-
-	return plan, nil
+	return p
 }
 
 // ParsePlanFile reads and parses a plan file from disk.
-func ParsePlanFile(path string) (*Plan, error) {
-	content, err := os.ReadFile(path) //nolint:gosec // path comes from server config
+func ParsePlanFile(path string, g plan.Grammar) (*Plan, error) {
+	doc, err := g.Load(path)
 	if err != nil {
-		return nil, fmt.Errorf("read plan file: %w", err)
+		return nil, err
 	}
-	return ParsePlan(string(content))
+	return newPlan(doc), nil
 }
 
 // JSON returns the plan as JSON bytes.
@@ -130,16 +87,8 @@ func (p *Plan) JSON() ([]byte, error) {
 	return data, nil
 }
 
-// parseTaskNum extracts task number from string.
-func parseTaskNum(s string) (int, error) {
-	n, err := strconv.Atoi(s)
-	if err != nil {
-		return 0, fmt.Errorf("parse task number: %w", err)
-	}
-	return n, nil
-}
-
 // determineTaskStatus calculates task status based on checkbox states.
+// a task with any blocked checkbox is blocked.
 func determineTaskStatus(checkboxes []Checkbox) TaskStatus {
 	if len(checkboxes) == 0 {
 		return TaskStatusPending
@@ -147,6 +96,9 @@ func determineTaskStatus(checkboxes []Checkbox) TaskStatus {
 
 	checkedCount := 0
 	for _, cb := range checkboxes {
+		if cb.Blocked {
+			return TaskStatusBlocked
+		}
 		if cb.Checked {
 			checkedCount++
 		}
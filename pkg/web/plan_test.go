@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
 )
 
 func TestParsePlan(t *testing.T) {
@@ -27,25 +29,24 @@ Some description here.
 - [ ] Task 2 item 1
 - [ ] Task 2 item 2
 `
-		plan, err := ParsePlan(content)
-		require.NoError(t, err)
+		p := ParsePlan(content, plan.Grammar{})
 
-		assert.Equal(t, "My Test Plan", plan.Title)
-		require.Len(t, plan.Tasks, 2)
+		assert.Equal(t, "My Test Plan", p.Title)
+		require.Len(t, p.Tasks, 2)
 
 		// task 1
-		assert.Equal(t, 1, plan.Tasks[0].Number)
-		assert.Equal(t, "First Task", plan.Tasks[0].Title)
-		assert.Equal(t, TaskStatusActive, plan.Tasks[0].Status) // has mix of checked/unchecked
-		require.Len(t, plan.Tasks[0].Checkboxes, 3)
-		assert.False(t, plan.Tasks[0].Checkboxes[0].Checked)
-		assert.True(t, plan.Tasks[0].Checkboxes[1].Checked)
-		assert.False(t, plan.Tasks[0].Checkboxes[2].Checked)
+		assert.Equal(t, 1, p.Tasks[0].Number)
+		assert.Equal(t, "First Task", p.Tasks[0].Title)
+		assert.Equal(t, TaskStatusActive, p.Tasks[0].Status) // has mix of checked/unchecked
+		require.Len(t, p.Tasks[0].Checkboxes, 3)
+		assert.False(t, p.Tasks[0].Checkboxes[0].Checked)
+		assert.True(t, p.Tasks[0].Checkboxes[1].Checked)
+		assert.False(t, p.Tasks[0].Checkboxes[2].Checked)
 
 		// task 2
-		assert.Equal(t, 2, plan.Tasks[1].Number)
-		assert.Equal(t, "Second Task", plan.Tasks[1].Title)
-		assert.Equal(t, TaskStatusPending, plan.Tasks[1].Status) // all unchecked
+		assert.Equal(t, 2, p.Tasks[1].Number)
+		assert.Equal(t, "Second Task", p.Tasks[1].Title)
+		assert.Equal(t, TaskStatusPending, p.Tasks[1].Status) // all unchecked
 	})
 
 	t.Run("parses iteration headers as tasks", func(t *testing.T) {
@@ -59,17 +60,16 @@ Some description here.
 
 - [x] Item 2
 `
-		plan, err := ParsePlan(content)
-		require.NoError(t, err)
+		p := ParsePlan(content, plan.Grammar{})
 
-		require.Len(t, plan.Tasks, 2)
-		assert.Equal(t, 1, plan.Tasks[0].Number)
-		assert.Equal(t, "First Iteration", plan.Tasks[0].Title)
-		assert.Equal(t, TaskStatusPending, plan.Tasks[0].Status)
+		require.Len(t, p.Tasks, 2)
+		assert.Equal(t, 1, p.Tasks[0].Number)
+		assert.Equal(t, "First Iteration", p.Tasks[0].Title)
+		assert.Equal(t, TaskStatusPending, p.Tasks[0].Status)
 
-		assert.Equal(t, 2, plan.Tasks[1].Number)
-		assert.Equal(t, "Second Iteration", plan.Tasks[1].Title)
-		assert.Equal(t, TaskStatusDone, plan.Tasks[1].Status)
+		assert.Equal(t, 2, p.Tasks[1].Number)
+		assert.Equal(t, "Second Iteration", p.Tasks[1].Title)
+		assert.Equal(t, TaskStatusDone, p.Tasks[1].Status)
 	})
 
 	t.Run("parses completed tasks", func(t *testing.T) {
@@ -80,11 +80,10 @@ Some description here.
 - [x] Item 1
 - [x] Item 2
 `
-		plan, err := ParsePlan(content)
-		require.NoError(t, err)
+		p := ParsePlan(content, plan.Grammar{})
 
-		require.Len(t, plan.Tasks, 1)
-		assert.Equal(t, TaskStatusDone, plan.Tasks[0].Status)
+		require.Len(t, p.Tasks, 1)
+		assert.Equal(t, TaskStatusDone, p.Tasks[0].Status)
 	})
 
 	t.Run("parses task with no checkboxes", func(t *testing.T) {
@@ -98,12 +97,11 @@ Just some text, no checkboxes.
 
 - [ ] One item
 `
-		plan, err := ParsePlan(content)
-		require.NoError(t, err)
+		p := ParsePlan(content, plan.Grammar{})
 
-		require.Len(t, plan.Tasks, 2)
-		assert.Equal(t, TaskStatusPending, plan.Tasks[0].Status)
-		assert.Empty(t, plan.Tasks[0].Checkboxes)
+		require.Len(t, p.Tasks, 2)
+		assert.Equal(t, TaskStatusPending, p.Tasks[0].Status)
+		assert.Empty(t, p.Tasks[0].Checkboxes)
 	})
 
 	t.Run("handles uppercase X in checkbox", func(t *testing.T) {
@@ -114,12 +112,11 @@ Just some text, no checkboxes.
 - [X] Uppercase checked
 - [x] Lowercase checked
 `
-		plan, err := ParsePlan(content)
-		require.NoError(t, err)
+		p := ParsePlan(content, plan.Grammar{})
 
-		require.Len(t, plan.Tasks[0].Checkboxes, 2)
-		assert.True(t, plan.Tasks[0].Checkboxes[0].Checked)
-		assert.True(t, plan.Tasks[0].Checkboxes[1].Checked)
+		require.Len(t, p.Tasks[0].Checkboxes, 2)
+		assert.True(t, p.Tasks[0].Checkboxes[0].Checked)
+		assert.True(t, p.Tasks[0].Checkboxes[1].Checked)
 	})
 
 	t.Run("handles plan without title", func(t *testing.T) {
@@ -127,19 +124,17 @@ Just some text, no checkboxes.
 
 - [ ] Item
 `
-		plan, err := ParsePlan(content)
-		require.NoError(t, err)
+		p := ParsePlan(content, plan.Grammar{})
 
-		assert.Empty(t, plan.Title)
-		require.Len(t, plan.Tasks, 1)
+		assert.Empty(t, p.Title)
+		require.Len(t, p.Tasks, 1)
 	})
 
 	t.Run("handles empty content", func(t *testing.T) {
-		plan, err := ParsePlan("")
-		require.NoError(t, err)
+		p := ParsePlan("", plan.Grammar{})
 
-		assert.Empty(t, plan.Title)
-		assert.Empty(t, plan.Tasks)
+		assert.Empty(t, p.Title)
+		assert.Empty(t, p.Tasks)
 	})
 
 	t.Run("ignores checkboxes outside tasks", func(t *testing.T) {
@@ -151,12 +146,80 @@ Just some text, no checkboxes.
 
 - [ ] Inside task
 `
-		plan, err := ParsePlan(content)
-		require.NoError(t, err)
+		p := ParsePlan(content, plan.Grammar{})
+
+		require.Len(t, p.Tasks, 1)
+		require.Len(t, p.Tasks[0].Checkboxes, 1)
+		assert.Equal(t, "Inside task", p.Tasks[0].Checkboxes[0].Text)
+	})
+
+	t.Run("parses nested checkboxes and ends task at next header", func(t *testing.T) {
+		content := `# Plan
+
+### Task 1: API (depends: none)
+
+- [x] add client
+  - [ ] handle retries
+
+## Post-Completion
+
+- [ ] manual check
+`
+		p := ParsePlan(content, plan.Grammar{})
 
-		require.Len(t, plan.Tasks, 1)
-		require.Len(t, plan.Tasks[0].Checkboxes, 1)
-		assert.Equal(t, "Inside task", plan.Tasks[0].Checkboxes[0].Text)
+		require.Len(t, p.Tasks, 1)
+		assert.Equal(t, "API", p.Tasks[0].Title)
+		assert.Equal(t, TaskStatusActive, p.Tasks[0].Status)
+		assert.Equal(t, []Checkbox{{Text: "add client", Checked: true}, {Text: "handle retries", Level: 1}},
+			p.Tasks[0].Checkboxes)
+	})
+
+	t.Run("excludes manual steps and ignored checkboxes", func(t *testing.T) {
+		content := `# Plan
+
+### Task 1: Deploy
+
+- [x] apply manifest
+<!-- ralphex:ignore -->
+- [ ] check dashboards
+<!-- /ralphex:ignore -->
+
+## Manual Steps
+
+### Task 2: Announce
+
+- [ ] post release notes
+`
+		p := ParsePlan(content, plan.Grammar{})
+
+		require.Len(t, p.Tasks, 1)
+		assert.Equal(t, TaskStatusDone, p.Tasks[0].Status)
+		assert.Equal(t, []Checkbox{{Text: "apply manifest", Checked: true}}, p.Tasks[0].Checkboxes)
+	})
+
+	t.Run("parses blocked task with reason", func(t *testing.T) {
+		content := `# Plan
+
+### Task 1: Deploy
+
+> blocked: AWS credentials are not configured
+- [x] write manifest
+- [~] apply manifest
+
+### Task 2: Docs
+
+- [-] publish docs
+- [ ] update readme
+`
+		p := ParsePlan(content, plan.Grammar{})
+
+		require.Len(t, p.Tasks, 2)
+		assert.Equal(t, TaskStatusBlocked, p.Tasks[0].Status)
+		assert.Equal(t, "AWS credentials are not configured", p.Tasks[0].BlockedReason)
+		assert.Equal(t, []Checkbox{{Text: "write manifest", Checked: true}, {Text: "apply manifest", Blocked: true}},
+			p.Tasks[0].Checkboxes)
+		assert.Equal(t, TaskStatusBlocked, p.Tasks[1].Status)
+		assert.Empty(t, p.Tasks[1].BlockedReason)
 	})
 }
 
@@ -172,21 +235,21 @@ func TestParsePlanFile(t *testing.T) {
 		path := filepath.Join(tmpDir, "test-plan.md")
 		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
 
-		plan, err := ParsePlanFile(path)
+		p, err := ParsePlanFile(path, plan.Grammar{})
 		require.NoError(t, err)
 
-		assert.Equal(t, "File Plan", plan.Title)
-		require.Len(t, plan.Tasks, 1)
+		assert.Equal(t, "File Plan", p.Title)
+		require.Len(t, p.Tasks, 1)
 	})
 
 	t.Run("returns error for missing file", func(t *testing.T) {
-		_, err := ParsePlanFile("/nonexistent/file.md")
+		_, err := ParsePlanFile("/nonexistent/file.md", plan.Grammar{})
 		assert.Error(t, err)
 	})
 }
 
 func TestPlan_JSON(t *testing.T) {
-	plan := &Plan{
+	p := &Plan{
 		Title: "Test Plan",
 		Tasks: []Task{
 			{
@@ -201,7 +264,7 @@ func TestPlan_JSON(t *testing.T) {
 		},
 	}
 
-	data, err := plan.JSON()
+	data, err := p.JSON()
 	require.NoError(t, err)
 
 	var decoded map[string]any
@@ -224,6 +287,8 @@ func TestDetermineTaskStatus(t *testing.T) {
 		{"mixed", []Checkbox{{Checked: true}, {Checked: false}}, TaskStatusActive},
 		{"single checked", []Checkbox{{Checked: true}}, TaskStatusDone},
 		{"single unchecked", []Checkbox{{Checked: false}}, TaskStatusPending},
+		{"blocked", []Checkbox{{Checked: true}, {Blocked: true}}, TaskStatusBlocked},
+		{"blocked with pending", []Checkbox{{Blocked: true}, {Checked: false}}, TaskStatusBlocked},
 	}
 
 	for _, tt := range tests {
@@ -240,4 +305,5 @@ func TestTaskStatus_Constants(t *testing.T) {
 	assert.Equal(t, TaskStatusActive, TaskStatus("active"))
 	assert.Equal(t, TaskStatusDone, TaskStatus("done"))
 	assert.Equal(t, TaskStatusFailed, TaskStatus("failed"))
+	assert.Equal(t, TaskStatusBlocked, TaskStatus("blocked"))
 }
@@ -12,8 +12,12 @@ import (
 	"net/http"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/runfile"
 )
 
 //go:embed templates static
@@ -25,6 +29,9 @@ type ServerConfig struct {
 	PlanName string // plan name to display in dashboard
 	Branch   string // git branch name
 	PlanFile string // path to plan file for /api/plan endpoint
+	Settings string // effective run settings to display in dashboard, e.g. max iterations and agents
+	// PlanGrammar recognizes task headers and checkboxes of plans, the zero value is the default grammar.
+	PlanGrammar plan.Grammar
 }
 
 // Server provides HTTP server for the real-time dashboard.
@@ -80,6 +87,8 @@ func (s *Server) Start(ctx context.Context) error {
 	mux.HandleFunc("/events", s.handleEvents)
 	mux.HandleFunc("/api/plan", s.handlePlan)
 	mux.HandleFunc("/api/sessions", s.handleSessions)
+	mux.HandleFunc("/api/findings", s.handleFindings)
+	mux.HandleFunc("/api/approval", s.handleApproval)
 
 	// static files
 	staticFS, err := fs.Sub(embeddedFS, "static")
@@ -131,6 +140,7 @@ func (s *Server) Session() *Session {
 type templateData struct {
 	PlanName string
 	Branch   string
+	Settings string
 }
 
 // handleIndex serves the main dashboard page.
@@ -145,6 +155,7 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	data := templateData{
 		PlanName: s.cfg.PlanName,
 		Branch:   s.cfg.Branch,
+		Settings: s.cfg.Settings,
 	}
 
 	if err := s.tmpl.Execute(w, data); err != nil {
@@ -177,14 +188,14 @@ func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plan, err := s.loadPlan()
+	p, err := s.loadPlan()
 	if err != nil {
 		log.Printf("[WARN] failed to load plan file %s: %v", s.cfg.PlanFile, err)
 		http.Error(w, "unable to load plan", http.StatusInternalServerError)
 		return
 	}
 
-	data, err := plan.JSON()
+	data, err := p.JSON()
 	if err != nil {
 		log.Printf("[WARN] failed to encode plan: %v", err)
 		http.Error(w, "unable to encode plan", http.StatusInternalServerError)
@@ -218,14 +229,14 @@ func (s *Server) handleSessionPlan(w http.ResponseWriter, sessionID string) {
 		planPath = filepath.Join(sessionDir, meta.PlanPath)
 	}
 
-	plan, err := loadPlanWithFallback(planPath)
+	p, err := loadPlanWithFallback(planPath, s.cfg.PlanGrammar)
 	if err != nil {
 		log.Printf("[WARN] failed to load plan file %s: %v", meta.PlanPath, err)
 		http.Error(w, "unable to load plan", http.StatusInternalServerError)
 		return
 	}
 
-	data, err := plan.JSON()
+	data, err := p.JSON()
 	if err != nil {
 		log.Printf("[WARN] failed to encode plan: %v", err)
 		http.Error(w, "unable to encode plan", http.StatusInternalServerError)
@@ -236,6 +247,111 @@ func (s *Server) handleSessionPlan(w http.ResponseWriter, sessionID string) {
 	_, _ = w.Write(data)
 }
 
+// handleFindings serves the review findings report of the session as JSON.
+// the report is read from disk on each request since it grows with every review iteration.
+// in multi-session mode, accepts ?session=<id> to select the session.
+func (s *Server) handleFindings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, err := s.getSession(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	// missing report is returned as empty, the run may not have reached review phases yet
+	report, err := runfile.LoadReport(runfile.ReportPath(session.Path))
+	if err != nil {
+		log.Printf("[WARN] failed to load review report for %s: %v", session.Path, err)
+		http.Error(w, "unable to load review report", http.StatusInternalServerError)
+		return
+	}
+	if report.Iterations == nil {
+		report.Iterations = []runfile.ReviewIteration{}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Printf("[WARN] failed to encode review report: %v", err)
+		http.Error(w, "unable to encode review report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// approvalRequest is the body of a POST to /api/approval.
+type approvalRequest struct {
+	Decision string `json:"decision"`
+}
+
+// handleApproval serves the pending phase approval of the session.
+// GET returns the approval or null if the run doesn't wait for one. POST answers it with
+// {"decision": "approve|skip|abort"}, the waiting run picks the decision up from the approval file.
+// POST requires a JSON body, so cross-site form posts can't answer the gate.
+func (s *Server) handleApproval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session, err := s.getSession(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	path := runfile.ApprovalPath(session.Path)
+	approval, err := runfile.LoadApproval(path)
+	if err != nil {
+		log.Printf("[WARN] failed to load approval for %s: %v", session.Path, err)
+		http.Error(w, "unable to load approval", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			http.Error(w, "content type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		var req approvalRequest
+		if decodeErr := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); decodeErr != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		decision, parseErr := runfile.ParseApprovalDecision(req.Decision)
+		if parseErr != nil {
+			http.Error(w, parseErr.Error(), http.StatusBadRequest)
+			return
+		}
+		if approval == nil || approval.Decision != "" {
+			http.Error(w, "no pending approval", http.StatusConflict)
+			return
+		}
+		approval.Decision = decision
+		if saveErr := approval.Save(path); saveErr != nil {
+			log.Printf("[WARN] failed to save approval for %s: %v", session.Path, saveErr)
+			http.Error(w, "unable to save approval", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	data, err := json.Marshal(approval)
+	if err != nil {
+		log.Printf("[WARN] failed to encode approval: %v", err)
+		http.Error(w, "unable to encode approval", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
 // loadPlan returns a cached plan or loads it from disk (with completed/ fallback).
 func (s *Server) loadPlan() (*Plan, error) {
 	s.planMu.Lock()
@@ -245,24 +361,24 @@ func (s *Server) loadPlan() (*Plan, error) {
 		return s.planCache, nil
 	}
 
-	plan, err := loadPlanWithFallback(s.cfg.PlanFile)
+	p, err := loadPlanWithFallback(s.cfg.PlanFile, s.cfg.PlanGrammar)
 	if err != nil {
 		return nil, err
 	}
 
-	s.planCache = plan
-	return plan, nil
+	s.planCache = p
+	return p, nil
 }
 
 // loadPlanWithFallback loads a plan from disk with completed/ directory fallback.
 // does not cache - each call reads from disk.
-func loadPlanWithFallback(path string) (*Plan, error) {
-	plan, err := ParsePlanFile(path)
+func loadPlanWithFallback(path string, g plan.Grammar) (*Plan, error) {
+	p, err := ParsePlanFile(path, g)
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
 		completedPath := filepath.Join(filepath.Dir(path), "completed", filepath.Base(path))
-		plan, err = ParsePlanFile(completedPath)
+		p, err = ParsePlanFile(completedPath, g)
 	}
-	return plan, err
+	return p, err
 }
 
 // handleEvents serves the SSE stream.
@@ -323,8 +439,11 @@ type SessionInfo struct {
 	PlanPath     string    `json:"planPath,omitempty"`
 	Branch       string    `json:"branch,omitempty"`
 	Mode         string    `json:"mode,omitempty"`
+	Settings     string    `json:"settings,omitempty"`
 	StartTime    time.Time `json:"startTime"`
 	LastModified time.Time `json:"lastModified"`
+	// AwaitingApproval is set while the run waits for approval at a phase gate.
+	AwaitingApproval bool `json:"awaitingApproval,omitempty"`
 }
 
 // handleSessions returns a list of all discovered sessions.
@@ -370,8 +489,11 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 			PlanPath:     meta.PlanPath,
 			Branch:       meta.Branch,
 			Mode:         meta.Mode,
+			Settings:     meta.Settings,
 			StartTime:    meta.StartTime,
 			LastModified: session.GetLastModified(),
+
+			AwaitingApproval: awaitingApproval(session.Path),
 		})
 	}
 
@@ -398,3 +520,9 @@ func extractProjectDir(path string) string {
 	}
 	return name
 }
+
+// awaitingApproval reports whether the run of the progress file waits for a phase approval.
+func awaitingApproval(progressPath string) bool {
+	approval, err := runfile.LoadApproval(runfile.ApprovalPath(progressPath))
+	return err == nil && approval != nil && approval.Decision == ""
+}
@@ -8,13 +8,16 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/runfile"
 )
 
 func TestNewServer(t *testing.T) {
@@ -40,6 +43,7 @@ func TestServer_HandleIndex(t *testing.T) {
 		Port:     8080,
 		PlanName: "my-plan.md",
 		Branch:   "feature-branch",
+		Settings: "max_iterations=20, agents=quality",
 	}, session)
 	require.NoError(t, err)
 
@@ -62,6 +66,7 @@ func TestServer_HandleIndex(t *testing.T) {
 		assert.Contains(t, bodyStr, "Ralphex Dashboard")
 		assert.Contains(t, bodyStr, "my-plan.md")
 		assert.Contains(t, bodyStr, "feature-branch")
+		assert.Contains(t, bodyStr, "max_iterations=20, agents=quality")
 	})
 
 	t.Run("returns 404 for non-root paths", func(t *testing.T) {
@@ -347,6 +352,164 @@ func TestServer_HandlePlan(t *testing.T) {
 	})
 }
 
+func TestServer_HandleFindings(t *testing.T) {
+	get := func(t *testing.T, srv *Server, url string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, url, http.NoBody)
+		w := httptest.NewRecorder()
+		srv.handleFindings(w, req)
+		resp := w.Result()
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("returns report of single session", func(t *testing.T) {
+		progressPath := filepath.Join(t.TempDir(), "progress-test.txt")
+		report := runfile.ReviewReport{Iterations: []runfile.ReviewIteration{{Phase: "review", Iteration: 1,
+			Findings: []runfile.Finding{{File: "main.go", Line: 10, Severity: runfile.SeverityMajor,
+				Description: "missing error check", Status: runfile.FindingFixed}}}}}
+		require.NoError(t, report.Save(runfile.ReportPath(progressPath)))
+
+		session := NewSession("test", progressPath)
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{Port: 8080}, session)
+		require.NoError(t, err)
+
+		code, body := get(t, srv, "/api/findings")
+		assert.Equal(t, http.StatusOK, code)
+		var got runfile.ReviewReport
+		require.NoError(t, json.Unmarshal([]byte(body), &got))
+		require.Len(t, got.Iterations, 1)
+		assert.Equal(t, report.Iterations[0].Findings, got.Iterations[0].Findings)
+	})
+
+	t.Run("returns empty report if missing", func(t *testing.T) {
+		session := NewSession("test", filepath.Join(t.TempDir(), "progress-test.txt"))
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{Port: 8080}, session)
+		require.NoError(t, err)
+
+		code, body := get(t, srv, "/api/findings")
+		assert.Equal(t, http.StatusOK, code)
+		assert.JSONEq(t, `{"iterations":[]}`, body)
+	})
+
+	t.Run("returns report of requested session", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		progressPath := filepath.Join(tmpDir, "progress-test-plan.txt")
+		require.NoError(t, os.WriteFile(progressPath, []byte("# Ralphex Progress Log\nPlan: test.md\n"), 0o600))
+		report := runfile.ReviewReport{Iterations: []runfile.ReviewIteration{{Phase: "codex", Iteration: 2,
+			Findings: []runfile.Finding{{Severity: runfile.SeverityMinor, Description: "typo", Status: runfile.FindingRejected}}}}}
+		require.NoError(t, report.Save(runfile.ReportPath(progressPath)))
+
+		sm := NewSessionManager()
+		defer sm.Close()
+		_, err := sm.Discover(tmpDir)
+		require.NoError(t, err)
+		srv, err := NewServerWithSessions(ServerConfig{Port: 8080}, sm)
+		require.NoError(t, err)
+
+		code, body := get(t, srv, "/api/findings?session="+sessionIDFromPath(progressPath))
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, `"description":"typo"`)
+		assert.Contains(t, body, `"status":"rejected"`)
+
+		code, _ = get(t, srv, "/api/findings?session=unknown")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("rejects non-GET methods", func(t *testing.T) {
+		srv, err := NewServer(ServerConfig{Port: 8080}, nil)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/findings", http.NoBody)
+		w := httptest.NewRecorder()
+		srv.handleFindings(w, req)
+		resp := w.Result()
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
+
+func TestServer_HandleApproval(t *testing.T) {
+	do := func(t *testing.T, srv *Server, method, body, contentType string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/approval", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		srv.handleApproval(w, req)
+		resp := w.Result()
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+	newSrv := func(t *testing.T) (*Server, string) {
+		t.Helper()
+		progressPath := filepath.Join(t.TempDir(), "progress-test.txt")
+		session := NewSession("test", progressPath)
+		t.Cleanup(session.Close)
+		srv, err := NewServer(ServerConfig{Port: 8080}, session)
+		require.NoError(t, err)
+		return srv, runfile.ApprovalPath(progressPath)
+	}
+	pending := runfile.Approval{Phase: "tasks", NextPhase: "review_first", Commits: []string{"abc123 add cache"},
+		Files: []string{"cache.go"}, DiffStat: " cache.go | 10 ++++++++++\n 1 file changed"}
+
+	t.Run("returns null if nothing pending", func(t *testing.T) {
+		srv, _ := newSrv(t)
+		code, body := do(t, srv, http.MethodGet, "", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "null", body)
+	})
+
+	t.Run("returns pending approval", func(t *testing.T) {
+		srv, path := newSrv(t)
+		require.NoError(t, pending.Save(path))
+		code, body := do(t, srv, http.MethodGet, "", "")
+		assert.Equal(t, http.StatusOK, code)
+		var got runfile.Approval
+		require.NoError(t, json.Unmarshal([]byte(body), &got))
+		assert.Equal(t, "review_first", got.NextPhase)
+		assert.Equal(t, pending.Files, got.Files)
+		assert.Empty(t, got.Decision)
+	})
+
+	t.Run("post saves decision", func(t *testing.T) {
+		srv, path := newSrv(t)
+		require.NoError(t, pending.Save(path))
+		code, _ := do(t, srv, http.MethodPost, `{"decision":"skip"}`, "application/json")
+		assert.Equal(t, http.StatusOK, code)
+		got, err := runfile.LoadApproval(path)
+		require.NoError(t, err)
+		assert.Equal(t, runfile.ApprovalSkip, got.Decision)
+
+		code, _ = do(t, srv, http.MethodPost, `{"decision":"approve"}`, "application/json")
+		assert.Equal(t, http.StatusConflict, code, "already decided")
+	})
+
+	t.Run("post rejects invalid requests", func(t *testing.T) {
+		srv, path := newSrv(t)
+		code, _ := do(t, srv, http.MethodPost, `{"decision":"approve"}`, "application/json")
+		assert.Equal(t, http.StatusConflict, code, "nothing pending")
+
+		require.NoError(t, pending.Save(path))
+		code, _ = do(t, srv, http.MethodPost, "decision=approve", "application/x-www-form-urlencoded")
+		assert.Equal(t, http.StatusUnsupportedMediaType, code)
+		code, _ = do(t, srv, http.MethodPost, `{"decision":"later"}`, "application/json")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = do(t, srv, http.MethodPut, "", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+		got, err := runfile.LoadApproval(path)
+		require.NoError(t, err)
+		assert.Empty(t, got.Decision)
+	})
+}
+
 func TestNewServerWithSessions(t *testing.T) {
 	sm := NewSessionManager()
 	defer sm.Close()
@@ -394,6 +557,7 @@ func TestServer_HandleSessions(t *testing.T) {
 Plan: docs/plans/test-plan.md
 Branch: feature-branch
 Mode: full
+Settings: max_iterations=20, codex_enabled=false
 Started: 2026-01-22 10:30:00
 ------------------------------------------------------------
 [10:30:00] Starting execution
@@ -432,6 +596,7 @@ Started: 2026-01-22 10:30:00
 		assert.Equal(t, "docs/plans/test-plan.md", sessions[0].PlanPath)
 		assert.Equal(t, "feature-branch", sessions[0].Branch)
 		assert.Equal(t, "full", sessions[0].Mode)
+		assert.Equal(t, "max_iterations=20, codex_enabled=false", sessions[0].Settings)
 	})
 
 	t.Run("rejects non-GET methods", func(t *testing.T) {
@@ -694,10 +859,10 @@ func TestLoadPlanWithFallback(t *testing.T) {
 `
 		require.NoError(t, os.WriteFile(planPath, []byte(planContent), 0o600))
 
-		plan, err := loadPlanWithFallback(planPath)
+		p, err := loadPlanWithFallback(planPath, plan.Grammar{})
 		require.NoError(t, err)
-		require.NotNil(t, plan)
-		assert.Equal(t, "Test Plan", plan.Title)
+		require.NotNil(t, p)
+		assert.Equal(t, "Test Plan", p.Title)
 	})
 
 	t.Run("falls back to completed directory", func(t *testing.T) {
@@ -717,19 +882,35 @@ func TestLoadPlanWithFallback(t *testing.T) {
 
 		// request the non-existent original path
 		originalPath := filepath.Join(tmpDir, "test-plan.md")
-		plan, err := loadPlanWithFallback(originalPath)
+		p, err := loadPlanWithFallback(originalPath, plan.Grammar{})
 		require.NoError(t, err)
-		require.NotNil(t, plan)
-		assert.Equal(t, "Completed Plan", plan.Title)
+		require.NotNil(t, p)
+		assert.Equal(t, "Completed Plan", p.Title)
 	})
 
 	t.Run("returns error when not found in either location", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		nonexistentPath := filepath.Join(tmpDir, "nonexistent.md")
 
-		_, err := loadPlanWithFallback(nonexistentPath)
+		_, err := loadPlanWithFallback(nonexistentPath, plan.Grammar{})
 		require.Error(t, err)
 	})
+	t.Run("uses plan grammar", func(t *testing.T) {
+		planPath := filepath.Join(t.TempDir(), "test-plan.md")
+		require.NoError(t, os.WriteFile(planPath, []byte("# Steps\n## Step 1 - Setup\n- [x] install\n## Step 2 - Build\n- [ ] compile\n"), 0o600))
+
+		p, err := loadPlanWithFallback(planPath, plan.Grammar{})
+		require.NoError(t, err)
+		assert.Empty(t, p.Tasks)
+
+		g, err := plan.NewGrammar(`^##\s+Step\s+(?P<num>\d+)\s+-\s+(?P<title>.*)$`, "")
+		require.NoError(t, err)
+		p, err = loadPlanWithFallback(planPath, g)
+		require.NoError(t, err)
+		require.Len(t, p.Tasks, 2)
+		assert.Equal(t, "Build", p.Tasks[1].Title)
+		assert.Equal(t, TaskStatusDone, p.Tasks[0].Status)
+	})
 }
 
 func TestExtractProjectDir(t *testing.T) {
@@ -777,3 +958,16 @@ func TestExtractProjectDir(t *testing.T) {
 		})
 	}
 }
+
+func TestAwaitingApproval(t *testing.T) {
+	progressPath := filepath.Join(t.TempDir(), "progress-test.txt")
+	assert.False(t, awaitingApproval(progressPath))
+
+	approval := runfile.Approval{Phase: "tasks", NextPhase: "review_first"}
+	require.NoError(t, approval.Save(runfile.ApprovalPath(progressPath)))
+	assert.True(t, awaitingApproval(progressPath))
+
+	approval.Decision = runfile.ApprovalApprove
+	require.NoError(t, approval.Save(runfile.ApprovalPath(progressPath)))
+	assert.False(t, awaitingApproval(progressPath), "decided approval is not pending")
+}
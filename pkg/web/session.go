@@ -55,6 +55,7 @@ type SessionMetadata struct {
 	PlanPath  string    // path to plan file (from "Plan:" header line)
 	Branch    string    // git branch (from "Branch:" header line)
 	Mode      string    // execution mode: full, review, codex-only (from "Mode:" header line)
+	Settings  string    // effective run settings, e.g. max iterations (from "Settings:" header line)
 	StartTime time.Time // start time (from "Started:" header line)
 }
 
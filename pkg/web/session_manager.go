@@ -388,6 +388,7 @@ func IsActive(path string) (bool, error) {
 //	Plan: path/to/plan.md
 //	Branch: feature-branch
 //	Mode: full
+//	Settings: max_iterations=50, codex_enabled=true
 //	Started: 2026-01-22 10:30:00
 //	------------------------------------------------------------
 func ParseProgressHeader(path string) (SessionMetadata, error) {
@@ -418,6 +419,8 @@ func ParseProgressHeader(path string) (SessionMetadata, error) {
 			meta.Branch = val
 		} else if val, found := strings.CutPrefix(line, "Mode: "); found {
 			meta.Mode = val
+		} else if val, found := strings.CutPrefix(line, "Settings: "); found {
+			meta.Settings = val
 		} else if val, found := strings.CutPrefix(line, "Started: "); found {
 			t, err := time.Parse("2006-01-02 15:04:05", val)
 			if err == nil {
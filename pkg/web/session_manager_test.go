@@ -346,6 +346,7 @@ func TestParseProgressHeader(t *testing.T) {
 Plan: docs/plans/my-plan.md
 Branch: feature-branch
 Mode: full
+Settings: max_iterations=50, agents=all
 Started: 2026-01-22 10:30:00
 ------------------------------------------------------------
 
@@ -359,6 +360,7 @@ Started: 2026-01-22 10:30:00
 		assert.Equal(t, "docs/plans/my-plan.md", meta.PlanPath)
 		assert.Equal(t, "feature-branch", meta.Branch)
 		assert.Equal(t, "full", meta.Mode)
+		assert.Equal(t, "max_iterations=50, agents=all", meta.Settings)
 		assert.Equal(t, time.Date(2026, 1, 22, 10, 30, 0, 0, time.UTC), meta.StartTime)
 	})
 
@@ -39,6 +39,8 @@
     const projectCopyBtn = document.getElementById('project-copy');
     const planNameEl = document.getElementById('plan-name');
     const branchNameEl = document.getElementById('branch-name');
+    const runSettingsEl = document.getElementById('run-settings');
+    const approvalPanel = document.getElementById('approval-panel');
 
     // SSE reconnection constants
     var SSE_INITIAL_RECONNECT_MS = 1000;
@@ -617,8 +619,11 @@
         const taskEl = planContent.querySelector('.plan-task[data-task-num="' + taskNum + '"]');
         if (!taskEl) return;
 
-        taskEl.classList.remove('active');
         const statusEl = taskEl.querySelector('.plan-task-status');
+        // blocked tasks stay blocked, task boundary events don't know about blocking
+        if (statusEl.classList.contains('blocked')) return;
+
+        taskEl.classList.remove('active');
         statusEl.classList.remove('pending', 'active', 'done', 'failed');
         statusEl.classList.add(statusValue);
 
@@ -1006,6 +1011,7 @@
             .then(function(sessions) {
                 state.sessions = sessions;
                 renderSessionList(sessions);
+                fetchApproval();
                 // auto-select first session if none is currently selected
                 if (!state.currentSessionId && sessions.length > 0) {
                     selectSession(sessions[0].id);
@@ -1232,6 +1238,13 @@
             info.appendChild(metaRow);
         }
 
+        if (session.awaitingApproval) {
+            var approvalRow = document.createElement('div');
+            approvalRow.className = 'session-row session-row-approval';
+            approvalRow.textContent = 'awaiting approval';
+            info.appendChild(approvalRow);
+        }
+
         if (session.branch) {
             var branchRow = document.createElement('div');
             branchRow.className = 'session-row session-row-branch';
@@ -1305,6 +1318,10 @@
             if (branchNameEl) {
                 branchNameEl.textContent = session.branch || '';
             }
+            if (runSettingsEl) {
+                runSettingsEl.textContent = session.settings || '';
+                runSettingsEl.title = session.settings || '';
+            }
         }
 
         // reconnect SSE to new session
@@ -1312,6 +1329,7 @@
 
         // reload plan for new session
         fetchPlanForSession(sessionId);
+        fetchApproval();
     }
 
     function copyTextToClipboard(text) {
@@ -1379,6 +1397,96 @@
             });
     }
 
+    // approval gate api url for the current session
+    function approvalURL() {
+        var url = '/api/approval';
+        if (state.currentSessionId) {
+            url += '?session=' + encodeURIComponent(state.currentSessionId);
+        }
+        return url;
+    }
+
+    // fetch pending phase approval of the current session, polled together with sessions
+    function fetchApproval() {
+        if (!approvalPanel) return;
+        fetch(approvalURL())
+            .then(function(response) {
+                if (!response.ok) {
+                    throw new Error('Approval not available');
+                }
+                return response.json();
+            })
+            .then(renderApproval)
+            .catch(function(err) {
+                renderApproval(null);
+                console.log('Approval fetch:', err.message);
+            });
+    }
+
+    // send approval gate decision (approve, skip or abort)
+    function sendApproval(decision) {
+        fetch(approvalURL(), {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({decision: decision})
+        })
+            .then(function(response) {
+                if (!response.ok) {
+                    throw new Error('Approval not accepted');
+                }
+                return response.json();
+            })
+            .then(renderApproval)
+            .catch(function(err) {
+                console.log('Approval send:', err.message);
+                fetchApproval();
+            });
+    }
+
+    // render pending approval panel with phase summary and decision buttons
+    function renderApproval(approval) {
+        clearElement(approvalPanel);
+        if (!approval || approval.decision) {
+            approvalPanel.classList.add('is-hidden');
+            return;
+        }
+
+        var title = document.createElement('div');
+        title.className = 'approval-title';
+        title.textContent = 'Awaiting approval: ' + approval.phase + ' phase completed, next ' + approval.next_phase;
+        approvalPanel.appendChild(title);
+
+        var commits = approval.commits || [];
+        var files = approval.files || [];
+        var summary = document.createElement('div');
+        summary.className = 'approval-summary';
+        summary.textContent = commits.length + ' commit(s), ' + files.length + ' changed file(s)';
+        approvalPanel.appendChild(summary);
+
+        if (commits.length > 0 || approval.diffstat) {
+            var details = document.createElement('pre');
+            details.className = 'approval-details';
+            details.textContent = commits.concat(approval.diffstat ? [approval.diffstat] : []).join('\n');
+            approvalPanel.appendChild(details);
+        }
+
+        var actions = document.createElement('div');
+        actions.className = 'approval-actions';
+        [
+            {decision: 'approve', label: 'Approve, continue with ' + approval.next_phase},
+            {decision: 'skip', label: 'Skip ' + approval.next_phase},
+            {decision: 'abort', label: 'Abort run'}
+        ].forEach(function(a) {
+            var btn = document.createElement('button');
+            btn.className = 'approval-btn approval-' + a.decision;
+            btn.textContent = a.label;
+            btn.addEventListener('click', function() { sendApproval(a.decision); });
+            actions.appendChild(btn);
+        });
+        approvalPanel.appendChild(actions);
+        approvalPanel.classList.remove('is-hidden');
+    }
+
     // start polling for session updates
     function startSessionPolling() {
         if (state.sessionPollInterval) {
@@ -1485,8 +1593,12 @@
                 case 'active': statusIcon.textContent = '●'; break;
                 case 'done': statusIcon.textContent = '✓'; break;
                 case 'failed': statusIcon.textContent = '✗'; break;
+                case 'blocked': statusIcon.textContent = '⊘'; break;
                 default: statusIcon.textContent = '○';
             }
+            if (task.blockedReason) {
+                header.title = 'Blocked: ' + task.blockedReason;
+            }
 
             const title = document.createElement('span');
             title.className = 'plan-task-title';
@@ -1503,12 +1615,20 @@
                 if (checkbox.checked) {
                     cbEl.classList.add('checked');
                 }
+                if (checkbox.level) {
+                    // nested checkbox, indent under its parent
+                    cbEl.style.paddingLeft = (26 + checkbox.level * 18) + 'px';
+                }
 
                 const icon = document.createElement('span');
                 icon.className = 'plan-checkbox-icon';
                 if (checkbox.checked) {
                     icon.classList.add('checked');
                     icon.textContent = '☑';
+                } else if (checkbox.blocked) {
+                    cbEl.classList.add('blocked');
+                    icon.classList.add('blocked');
+                    icon.textContent = '⊘';
                 } else {
                     icon.textContent = '☐';
                 }